@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/knowledge"
+)
+
+func main() {
+	logLevel := flag.String("log-level", "warn", "Log level (debug, info, warn, error, fatal, panic)")
+	format := flag.String("format", "text", "Format of the report: text or junit")
+	output := flag.String("output", "", "File the report is written to. The report is written to stdout when not set")
+	overlapFails := flag.Bool("overlap-fails", false, "Treat should_match samples which match other assets as failures")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [prompt directory]\n\nThe prompt directory defaults to PROMPT_PATH.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	level, err := log.ParseLevel(*logLevel)
+	if err != nil {
+		log.Fatalf("Invalid log level: %s", *logLevel)
+	}
+	log.SetLevel(level)
+
+	dir := os.Getenv("PROMPT_PATH")
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	if len(dir) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := knowledge.Lint(dir)
+	if err != nil {
+		log.Fatalf("unable to lint %s: %v", dir, err)
+	}
+
+	var out io.Writer = os.Stdout
+	if len(*output) > 0 {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("unable to create %s: %v", *output, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	switch *format {
+	case "text":
+		err = report.WriteText(out, *overlapFails)
+	case "junit":
+		err = report.WriteJUnit(out, *overlapFails)
+	default:
+		log.Fatalf("unknown format %q. expected text or junit", *format)
+	}
+	if err != nil {
+		log.Fatalf("unable to write report: %v", err)
+	}
+
+	if failures := report.Failures(*overlapFails); failures > 0 {
+		log.Errorf("%d knowledge lint failures", failures)
+		// deferred calls do not run on exit
+		if file, ok := out.(*os.File); ok && file != os.Stdout {
+			file.Close()
+		}
+		os.Exit(1)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
@@ -16,9 +18,13 @@ import (
 	"github.com/slack-go/slack/socketmode"
 
 	"github.com/openshift-splat-team/splat-bot/pkg/commands"
-	_ "github.com/openshift-splat-team/splat-bot/pkg/controllers"
-	_ "github.com/openshift-splat-team/splat-bot/pkg/knowledge"
+	"github.com/openshift-splat-team/splat-bot/pkg/controllers"
+	"github.com/openshift-splat-team/splat-bot/pkg/dispatch"
+	"github.com/openshift-splat-team/splat-bot/pkg/knowledge"
+	"github.com/openshift-splat-team/splat-bot/pkg/server"
 	slackutil "github.com/openshift-splat-team/splat-bot/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 )
 
 type CustomFormatter struct{}
@@ -34,10 +40,19 @@ func (f *CustomFormatter) Format(entry *log.Entry) ([]byte, error) {
 }
 
 func main() {
-	ctx := context.TODO()
+	// the context is cancelled on SIGINT or SIGTERM so that the bot stops receiving events and finishes the events
+	// which are in flight
+	ctx := signals.SetupSignalHandler()
 
 	// Define a flag for log level
 	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error, fatal, panic)")
+	workers := flag.Int("workers", 8, "Number of events which are handled concurrently. Events in the same thread are always handled in order")
+	maxPending := flag.Int("max-pending-events", 1000, "Number of events which may be waiting or being handled before new events are rejected")
+	handlerTimeout := flag.Duration("handler-timeout", 5*time.Minute, "Maximum time allowed to handle an event")
+	mode := flag.String("mode", "socket", "How events are received from Slack: socket (Socket Mode) or http (Events API). http mode requires SLACK_SIGNING_SECRET")
+	listenAddress := flag.String("listen-address", ":3000", "Address the Events API endpoints are served on in http mode")
+	metricsAddress := flag.String("metrics-bind-address", ":8080", "Address Prometheus metrics are served on at /metrics. Set to 0 to disable the metrics endpoint")
+	watchKnowledge := flag.Bool("watch-knowledge", true, "Reload the knowledge assets when the files in PROMPT_PATH change")
 	flag.Parse()
 
 	// Parse and set the log level
@@ -50,20 +65,94 @@ func main() {
 	log.SetFormatter(&CustomFormatter{})
 	log.SetOutput(os.Stdout)
 
-	client, err := slackutil.GetClient()
+	err = controllers.Start(ctx, config.GetConfigOrDie(), nil, *metricsAddress)
 	if err != nil {
-		log.Debugf("unable to get slack client: %v", err)
+		log.Errorf("unable to start controllers: %v", err)
 		os.Exit(1)
 	}
 
 	err = commands.Initialize()
 	if err != nil {
-		log.Debugf("unable to get users in group")
+		log.Errorf("unable to initialize commands: %v", err)
 		os.Exit(1)
 	}
+	go knowledge.RunFeedback(ctx)
+
+	if *watchKnowledge {
+		if err := knowledge.Watch(ctx); err != nil {
+			log.Warnf("not watching knowledge assets for changes: %v", err)
+		}
+	}
+
+	dispatcher := dispatch.New(*workers, *maxPending, *handlerTimeout)
+	// the events which are in flight when the bot is asked to stop are still handled, so the workers aren't cancelled
+	// by the signal. Each event is still bound by the handler timeout.
+	dispatcher.Start(context.WithoutCancel(ctx))
+
+	switch *mode {
+	case "socket":
+		err = runSocketMode(ctx, dispatcher)
+	case "http":
+		err = runHTTP(ctx, dispatcher, *listenAddress)
+	default:
+		err = fmt.Errorf("unknown mode %s", *mode)
+	}
+	// no more events are dispatched once the client returns, so the queued events can be drained
+	dispatcher.Stop()
+	if err := knowledge.FlushFeedback(); err != nil {
+		log.Warnf("%v", err)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalf("error encountered while running client: %v", err)
+	}
+	log.Infof("stopped")
+}
+
+// runHTTP receives events from Slack over HTTP
+func runHTTP(ctx context.Context, dispatcher *dispatch.Dispatcher, listenAddress string) error {
+	webClient, err := slackutil.GetWebClient()
+	if err != nil {
+		return fmt.Errorf("unable to get slack client: %v", err)
+	}
+	client := slackutil.NewInstrumentedClient(webClient)
+
+	srv, err := server.New(os.Getenv("SLACK_SIGNING_SECRET"), client, dispatcher)
+	if err != nil {
+		return err
+	}
+	commands.StartScheduler(ctx, client)
+	return srv.ListenAndServe(ctx, listenAddress)
+}
+
+// dispatchOrDrop queues a job without blocking the Socket Mode event loop. The event has already been acknowledged, so
+// it is dropped when it can't be queued.
+func dispatchOrDrop(dispatcher *dispatch.Dispatcher, key, name string, job dispatch.Job) {
+	if err := dispatcher.Dispatch(key, name, job); err != nil {
+		log.Warnf("dropping %s: %v", name, err)
+	}
+}
 
+// runSocketMode receives events from Slack over Socket Mode
+func runSocketMode(ctx context.Context, dispatcher *dispatch.Dispatcher) error {
+	client, err := slackutil.GetClient()
+	if err != nil {
+		return fmt.Errorf("unable to get slack client: %v", err)
+	}
+	instrumentedClient := slackutil.NewInstrumentedClient(client)
+	commands.StartScheduler(ctx, instrumentedClient)
+
+	// the event loop is stopped whenever the client returns, not only when ctx is cancelled
+	loopCtx, stopLoop := context.WithCancel(ctx)
+	done := make(chan struct{})
 	go func() {
-		for evt := range client.Events {
+		defer close(done)
+		for {
+			var evt socketmode.Event
+			select {
+			case <-loopCtx.Done():
+				return
+			case evt = <-client.Events:
+			}
 			switch evt.Type {
 			case socketmode.EventTypeConnecting:
 				log.Infof("Connecting to Slack with Socket Mode...")
@@ -81,16 +170,22 @@ func main() {
 				log.Debugf("event received: %+v\n", eventsAPIEvent)
 
 				client.Ack(*evt.Request)
-				err = commands.Handler(ctx, client, eventsAPIEvent)
-				if err != nil {
-					log.Warnf("error encountered while processing event: %v", err)
-				}
+				dispatchOrDrop(dispatcher, commands.EventOrderingKey(eventsAPIEvent), "event "+eventsAPIEvent.InnerEvent.Type, func(ctx context.Context) error {
+					return commands.Handler(ctx, instrumentedClient, eventsAPIEvent)
+				})
 			case socketmode.EventTypeInteractive:
 				// NOTE: we can get one of these when user is responding to slash command dialogs well as when we have
 				// interactive responses such as the pull-request command.
 				log.Debugf("GOT INTERACTIVE EVENT: %v\n", evt)
 				client.Ack(*evt.Request)
-				processInteractiveEvent(ctx, client, evt)
+				callback, ok := evt.Data.(slack.InteractionCallback)
+				if !ok {
+					log.Warnf("ignored %+v\n", evt)
+					continue
+				}
+				dispatchOrDrop(dispatcher, commands.InteractionOrderingKey(callback), "interaction "+string(callback.Type), func(ctx context.Context) error {
+					return commands.InteractionHandler(ctx, instrumentedClient, callback)
+				})
 			case socketmode.EventTypeSlashCommand:
 				log.Debug("GOT SLASH COMMAND")
 				client.Ack(*evt.Request)
@@ -103,7 +198,9 @@ func main() {
 				}
 				log.Debugf("Slash command: %v", buffer)
 
-				err = commands.SlashHandler(ctx, client, command)
+				dispatchOrDrop(dispatcher, commands.SlashCommandOrderingKey(command), "slash command "+command.Command, func(ctx context.Context) error {
+					return commands.SlashHandler(ctx, instrumentedClient, command)
+				})
 
 			default:
 				log.Warnf("Unexpected event type received: %s\n", evt.Type)
@@ -111,56 +208,9 @@ func main() {
 		}
 	}()
 
-	err = client.Run()
-	if err != nil {
-		log.Fatalf("error encountered while running client: %v", err)
-	}
-}
-
-func processInteractiveEvent(ctx context.Context, client *socketmode.Client, evt socketmode.Event) {
-	client.Ack(*evt.Request)
-
-	data := evt.Data.(slack.InteractionCallback)
-
-	// This outputs the event data for debugging
-	buffer := bytes.NewBuffer([]byte{})
-	if err := json.NewEncoder(buffer).Encode(data); err != nil {
-		log.Warnf("Error: %v", err)
-	} else {
-		log.Debugf("EVENT DATA: %v", buffer.String())
-	}
-
-	// block_actions payloads are received when a user clicks a Block Kit interactive component.
-	//shortcut and message_actions payloads are received when global and message shortcuts are used.
-	//view_submission payloads are received when a modal is submitted.
-	//view_closed payloads are received when a modal is cancelled.
-	log.Printf("Handling event type %v", data.Type)
-	switch data.Type {
-	case slack.InteractionTypeDialogCancellation:
-	case slack.InteractionTypeDialogSubmission:
-	case slack.InteractionTypeDialogSuggestion:
-	case slack.InteractionTypeInteractionMessage:
-	case slack.InteractionTypeMessageAction:
-	case slack.InteractionTypeBlockActions:
-		// Ok, so we are going have to pass the evt to the attribute / slash command that this belongs to.
-		// Currently, we are only closing the dialog.
-		if data.ActionCallback.BlockActions[0].Text.Text == "Close" {
-			_, _, err := client.PostMessage(data.Channel.ID, slack.MsgOptionDeleteOriginal(data.ResponseURL))
-			if err != nil {
-				log.Warnf("Error occurred handling interative event: %v", err)
-			}
-		}
-	case slack.InteractionTypeBlockSuggestion:
-	case slack.InteractionTypeViewSubmission:
-		err := commands.ViewSubmissionHandler(ctx, client, data)
-		if err != nil {
-			log.Warnf("Error occurred handling interative event: %v", err)
-		}
-	case slack.InteractionTypeViewClosed:
-	case slack.InteractionTypeShortcut:
-	case slack.InteractionTypeWorkflowStepEdit:
-	default:
-		log.Warnf("Unexpected event type received: %s\n", evt.Type)
-	}
-
+	err = client.RunContext(ctx)
+	// wait for the event loop so that nothing is dispatched after the dispatcher is stopped
+	stopLoop()
+	<-done
+	return err
 }
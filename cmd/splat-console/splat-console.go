@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/commands"
+	"github.com/openshift-splat-team/splat-bot/pkg/console"
+	"github.com/openshift-splat-team/splat-bot/pkg/controllers"
+	_ "github.com/openshift-splat-team/splat-bot/pkg/knowledge"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the console until the messages are exhausted. Errors are returned rather than exiting so that the deferred
+// cleanup, such as stopping envtest, always runs.
+func run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logLevel := flag.String("log-level", "warn", "Log level (debug, info, warn, error, fatal, panic)")
+	script := flag.String("script", "", "File to read messages from, one per line. Messages are read from stdin when not set")
+	user := flag.String("user", "UCONSOLE", "Slack user ID the messages are sent by")
+	channel := flag.String("channel", "CCONSOLE", "Slack channel ID the messages are sent in")
+	im := flag.Bool("im", false, "Send the messages in a DM with the bot")
+	thread := flag.String("thread", "", "Timestamp of the thread the messages are sent in")
+	mention := flag.Bool("mention", true, "Mention the bot at the start of each message")
+	useEnvtest := flag.Bool("envtest", false, "Run the Kubernetes backed commands against a local API server. Requires KUBEBUILDER_ASSETS")
+	crdPath := flag.String("envtest-crds", console.DefaultCRDPath, "Directory containing the vSphere capacity manager CRDs installed in envtest")
+	envtestData := flag.String("envtest-data", "", "Directory containing pool-*.yaml files to create in envtest")
+	flag.Parse()
+
+	level, err := log.ParseLevel(*logLevel)
+	if err != nil {
+		return fmt.Errorf("invalid log level: %s", *logLevel)
+	}
+	log.SetLevel(level)
+
+	if _, ok := os.LookupEnv("SPLAT_BOT_USER_ID"); !ok {
+		os.Setenv("SPLAT_BOT_USER_ID", "USPLATBOT")
+	}
+
+	options := console.Options{
+		User:        *user,
+		Channel:     *channel,
+		ChannelType: slack.TYPE_CHANNEL,
+		Thread:      *thread,
+		Mention:     *mention,
+	}
+	if *im {
+		options.ChannelType = slack.TYPE_IM
+	}
+	c := console.New(os.Stdout, options)
+
+	// Kubernetes backed commands report that the vSphere capacity manager is not available unless a cluster is
+	// provided with -kubeconfig, KUBECONFIG or -envtest.
+	var cfg *rest.Config
+	if *useEnvtest {
+		var stop func() error
+		cfg, stop, err = console.StartEnvtest(ctx, *crdPath, *envtestData)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := stop(); err != nil {
+				log.Warnf("unable to stop envtest: %v", err)
+			}
+		}()
+	} else if flag.Lookup(config.KubeconfigFlagName).Value.String() != "" || os.Getenv("KUBECONFIG") != "" {
+		cfg, err = config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("unable to load kubeconfig: %v", err)
+		}
+	}
+	if cfg != nil {
+		// the console is run alongside a deployed bot so it does not serve metrics
+		if err := controllers.Start(ctx, cfg, c.Client(), "0"); err != nil {
+			return fmt.Errorf("unable to start controllers: %v", err)
+		}
+	}
+
+	if err := commands.Initialize(); err != nil {
+		return fmt.Errorf("unable to initialize commands: %v", err)
+	}
+
+	var in io.Reader = os.Stdin
+	if len(*script) > 0 {
+		file, err := os.Open(*script)
+		if err != nil {
+			return fmt.Errorf("unable to open script: %v", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	return c.Run(ctx, in)
+}
@@ -0,0 +1,30 @@
+package data
+
+import (
+	"context"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+	"github.com/slack-go/slack"
+)
+
+type ActionCallback func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error)
+
+// Action handles interactions with Block Kit components such as buttons, selects and overflow menus.
+type Action struct {
+	// ActionID the Handler is invoked when the action_id of the component starts with ActionID. Components which are
+	// repeated in a message, such as a button per pool, should append a suffix to keep their action_id unique.
+	ActionID string
+	// BlockID the Handler is invoked when the block_id of the component starts with BlockID. ActionID is preferred
+	// when both are set.
+	BlockID string
+	// Commands the command performed by the action. The user must be authorized to run the command, with the
+	// value of the component as its arguments, for the Handler to be invoked.
+	Commands []string
+	// AllowNonSplatUsers by default, only members of @splat-team can interact with the component
+	AllowNonSplatUsers bool
+	// Handler function called when the component is interacted with
+	Handler ActionCallback
+	// ReplaceOriginal when true, the response replaces the message containing the component. Otherwise, the response
+	// is posted as an ephemeral message to the user.
+	ReplaceOriginal bool
+}
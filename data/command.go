@@ -12,12 +12,29 @@ type Callback func(ctx context.Context, client util.SlackClientInterface, evt *s
 
 type SlashCallback func(ctx context.Context, client util.SlackClientInterface, cmd slack.SlashCommand, args []string) ([]slack.MsgOption, error)
 
+type ParsedCallback func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *ParsedArgs) ([]slack.MsgOption, error)
+
+type SlashParsedCallback func(ctx context.Context, client util.SlackClientInterface, cmd slack.SlashCommand, args *ParsedArgs) ([]slack.MsgOption, error)
+
 type MessageOfInterest func(args []string, attribute Attributes, channel string) bool
 
 type CanHandleViewSubmission func(callbackID string) bool
 
 type HandleViewSubmission func(ctx context.Context, client util.SlackClientInterface, data slack.InteractionCallback) ([]slack.MsgOption, error)
 
+// Category groups related commands in help
+type Category string
+
+const (
+	CategoryCI        Category = "CI"
+	CategoryJira      Category = "Jira"
+	CategoryGitHub    Category = "GitHub"
+	CategoryKnowledge Category = "Knowledge"
+	CategoryLLM       Category = "LLM"
+	// CategoryGeneral is used for commands which do not set a Category
+	CategoryGeneral Category = "General"
+)
+
 // Attributes define when and how to handle a message
 type Attributes struct {
 	// Commands when matched, the Callback is invoked.
@@ -30,12 +47,23 @@ type Attributes struct {
 	MaxArgs int
 	// Callback function called when the attributes are met
 	Callback Callback
-	// Rank: Future - in a situation where multiple regexes match, this allows a priority to be assigned.
+	// Params describes the arguments which follow Commands. When set, the arguments are validated against Params
+	// instead of RequiredArgs and MaxArgs, and a usage line is generated from them when validation fails.
+	Params []Param
+	// ParsedCallback when set, is called instead of Callback with the arguments parsed from Params.
+	ParsedCallback ParsedCallback
+	// Wizard when set, the command starts a wizard in the thread of the message instead of calling the Callback.
+	// The arguments parsed from Params are available to the wizard's Finish along with the answers.
+	Wizard *Wizard
+	// Rank in a situation where multiple attributes match, the attribute with the highest rank is invoked. When ranks
+	// are equal, the attribute with the most Commands wins.
 	Rank int64
 	// RequireMention when true, @splat-bot must be used to invoke the command.
 	RequireMention bool
 	// HelpMarkdown is markdown that is contributed with the bot shows help.
 	HelpMarkdown string
+	// Category the command is listed under in help. Defaults to CategoryGeneral.
+	Category Category
 	// RespondInDM responds in a DM to the user.
 	RespondInDM bool
 	// RequireInChannel the attribute will only be recognized in a given channel(s).
@@ -44,6 +72,8 @@ type Attributes struct {
 	MustBeInThread bool
 	// AllowNonSplatUsers by default, only members of @splat-team can interact with the bot
 	AllowNonSplatUsers bool
+	// AdminOnly when true, only the admins listed in the authorization policy may use the command.
+	AdminOnly bool
 	// This command will not be included in the help message.
 	ExcludeFromHelp bool
 	// DontGlobQuotes when true, quotes are not globbed.  This is useful for knowledge commands that need discrete tokens.
@@ -52,6 +82,8 @@ type Attributes struct {
 	RespondInChannel bool
 	// ResponseIsEphemeral specifies if the response should be ephemeral.
 	ResponseIsEphemeral bool
+	// Actions handle interactions with the Block Kit components included in the responses of the command.
+	Actions []Action
 	// ShouldMatch is a list of strings that should match
 	ShouldMatch []string `yaml:"should_match"`
 	// ShouldntMatch is a list of strings that shouldnt match
@@ -61,14 +93,25 @@ type Attributes struct {
 type SlashCommand struct {
 	// Commands when matched, the Callback is invoked.
 	Commands []string
+	// AuthorizeAs the command tokens the slash command is authorized as, such as jira create for /jira-create, so that
+	// the policy rules which cover the command sent in a message also cover the slash command. The slash command is
+	// authorized as itself when AuthorizeAs isn't set.
+	AuthorizeAs []string
 	// The number of arguments a command must have. var args are not supported.
 	RequiredArgs int
 	// MaxArgs The maximum number of allowed arguments
 	MaxArgs int
 	// Callback function called when the attributes are met
 	ProcessCommand SlashCallback
+	// Params describes the arguments of the command. When set, the arguments are validated before the command is
+	// processed.
+	Params []Param
+	// ProcessParsedCommand when set, is called instead of ProcessCommand with the arguments parsed from Params.
+	ProcessParsedCommand SlashParsedCallback
 	// HelpMarkdown is markdown that is contributed with the bot shows help.
 	HelpMarkdown string
+	// Category the command is listed under in help. Defaults to CategoryGeneral.
+	Category Category
 	// RespondInDM responds in a DM to the user.
 	RespondInDM bool
 	// RequireInChannel the attribute will only be recognized in a given channel(s).
@@ -89,4 +132,6 @@ type SlashCommand struct {
 	ViewSubmissionCheck CanHandleViewSubmission
 	// HandleViewSubmission func to call to perform actions on the ViewSubmission event
 	HandleViewSubmission HandleViewSubmission
+	// Actions handle interactions with the Block Kit components included in the responses of the command.
+	Actions []Action
 }
@@ -1,6 +1,12 @@
 package data
 
-import "github.com/expr-lang/expr/vm"
+import (
+	"regexp"
+
+	"github.com/expr-lang/expr/vm"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
 
 // Knowledge defines a peice of knowledge that the bot can respond with
 type Knowledge struct {
@@ -55,6 +61,27 @@ type KnowledgeAsset struct {
 
 	// RequireInChannel the attribute will only be recognized in a given channel(s).
 	RequireInChannel []string `yaml:"must_be_in_channels"`
+
+	// Priority is added to the score of the asset when it matches a message. When several assets match, the asset
+	// with the highest score responds. Priority may be negative to prefer more specific assets.
+	Priority int `yaml:"priority"`
+
+	// Owners the Slack user IDs of the authors of the asset. Owners are notified when the asset is muted because its
+	// answers were rated unhelpful.
+	Owners []string `yaml:"owners"`
+
+	// Cooldown limits how often the asset repeats its answer. Cooldowns which aren't set are inherited from the
+	// cooldowns of the channel, then the default cooldowns, in cooldowns.yaml.
+	Cooldown Cooldown `yaml:"cooldown"`
+}
+
+// Cooldown durations are Go durations such as 30m or 24h. An empty duration is inherited and "0" disables the
+// cooldown.
+type Cooldown struct {
+	// Channel the asset doesn't answer again in a channel until the duration has passed since it answered there
+	Channel string `yaml:"channel"`
+	// User the asset doesn't answer a user again until the duration has passed since it answered them
+	User string `yaml:"user"`
 }
 
 type ChannelContext struct {
@@ -71,5 +98,21 @@ type TokenMatch struct {
 	Terms        []TokenMatch `yaml:"terms"`
 	CompiledExpr *vm.Program
 	Expr         string `yaml:"expr"`
-	Satisfied    bool
+
+	// Match the modes tokens are compared with the tokens of a message with: stem, synonyms and fuzzy. Tokens are
+	// compared exactly by default. Terms inherit the modes of their parent unless they set their own.
+	Match []string `yaml:"match"`
+	// MaxDistance the greatest edit distance allowed by the fuzzy mode. Defaults to 1 and may be at most 2.
+	MaxDistance int `yaml:"max_distance"`
+	// Options are resolved from Match and MaxDistance when the asset is loaded
+	Options util.MatchOptions `yaml:"-"`
+
+	// Phrase the message must contain the phrase, such as "x509: certificate signed by unknown authority"
+	Phrase string `yaml:"phrase"`
+	// Sequence the message must contain each of the phrases in order, not necessarily next to each other
+	Sequence []string `yaml:"sequence"`
+	// Regex the message must match the regular expression, such as `4\.1[4-7]`
+	Regex string `yaml:"regex"`
+	// CompiledRegex is compiled from Regex when the asset is loaded
+	CompiledRegex *regexp.Regexp `yaml:"-"`
 }
@@ -0,0 +1,110 @@
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParamType is the type of value a parameter accepts
+type ParamType string
+
+const (
+	ParamString   ParamType = "string"
+	ParamInt      ParamType = "int"
+	ParamEnum     ParamType = "enum"
+	ParamDuration ParamType = "duration"
+	ParamUser     ParamType = "user"
+	ParamChannel  ParamType = "channel"
+)
+
+// Param describes an argument accepted by a command
+type Param struct {
+	// Name of the parameter. key=value parameters are matched by name.
+	Name string
+	// Type of value accepted by the parameter. Defaults to ParamString.
+	Type ParamType
+	// KeyValue when true, the parameter is provided as name=value and may appear anywhere after the command.
+	// Otherwise, the parameter is positional.
+	KeyValue bool
+	// Required when true, the command is rejected if the parameter is not provided.
+	Required bool
+	// Default is applied when the parameter is not provided.
+	Default string
+	// Min and Max bound ParamInt values. The bounds are only enforced when Max is greater than Min.
+	Min int
+	Max int
+	// Values allowed for ParamEnum parameters.
+	Values []string
+	// Remainder when true, the parameter consumes the remaining positional arguments. Only the last positional
+	// parameter may be a Remainder.
+	Remainder bool
+	// Description of the parameter shown in help.
+	Description string
+}
+
+// ParsedArgs are the arguments of a command after they have been validated against its Params.
+type ParsedArgs struct {
+	// Raw the tokens of the message, including the command.
+	Raw []string
+
+	values map[string]any
+	tokens map[string][]string
+}
+
+// NewParsedArgs returns an empty set of parsed arguments for the raw tokens of a message.
+func NewParsedArgs(raw []string) *ParsedArgs {
+	return &ParsedArgs{
+		Raw:    raw,
+		values: map[string]any{},
+		tokens: map[string][]string{},
+	}
+}
+
+// Set sets the parsed value of a parameter.
+func (p *ParsedArgs) Set(name string, value any) {
+	p.values[name] = value
+}
+
+// SetTokens sets the tokens consumed by a Remainder parameter.
+func (p *ParsedArgs) SetTokens(name string, tokens []string) {
+	p.tokens[name] = tokens
+}
+
+// Tokens returns the tokens consumed by a Remainder parameter. Unlike String, quoted arguments remain separate
+// tokens, so the tokens can be run as a command.
+func (p *ParsedArgs) Tokens(name string) []string {
+	return p.tokens[name]
+}
+
+// Has returns true if the parameter was provided or has a default.
+func (p *ParsedArgs) Has(name string) bool {
+	_, ok := p.values[name]
+	return ok
+}
+
+// String returns the value of a ParamString, ParamEnum, ParamUser or ParamChannel parameter.
+func (p *ParsedArgs) String(name string) string {
+	value, _ := p.values[name].(string)
+	return value
+}
+
+// Int returns the value of a ParamInt parameter.
+func (p *ParsedArgs) Int(name string) int {
+	value, _ := p.values[name].(int)
+	return value
+}
+
+// Duration returns the value of a ParamDuration parameter.
+func (p *ParsedArgs) Duration(name string) time.Duration {
+	value, _ := p.values[name].(time.Duration)
+	return value
+}
+
+// Values returns every parameter formatted as a string.
+func (p *ParsedArgs) Values() map[string]string {
+	values := map[string]string{}
+	for name, value := range p.values {
+		values[name] = fmt.Sprint(value)
+	}
+	return values
+}
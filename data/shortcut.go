@@ -0,0 +1,34 @@
+package data
+
+import (
+	"context"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+	"github.com/slack-go/slack"
+)
+
+type ShortcutCallback func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback) ([]slack.MsgOption, error)
+
+// Shortcut handles a message shortcut, chosen from the menu of a message, or a global shortcut, chosen from the
+// shortcuts menu. Shortcuts must also be configured in the Slack app with a matching callback ID.
+type Shortcut struct {
+	// CallbackID of the shortcut as configured in the Slack app.
+	CallbackID string
+	// Name of the shortcut as shown in Slack.
+	Name string
+	// Commands the command performed by the shortcut. The user must be authorized to run the command for the
+	// Callback to be invoked.
+	Commands []string
+	// AllowNonSplatUsers by default, only members of @splat-team can use the shortcut
+	AllowNonSplatUsers bool
+	// Callback function called when the shortcut is used. Shortcuts which open a modal should do so with the
+	// callback's TriggerID and return no response.
+	Callback ShortcutCallback
+	// RespondInThread when true, the response to a message shortcut is posted in the thread of the message.
+	// Otherwise, the response is only visible to the user.
+	RespondInThread bool
+	// HelpMarkdown is markdown that is contributed with the bot shows help.
+	HelpMarkdown string
+	// Category the shortcut is listed under in help. Defaults to CategoryGeneral.
+	Category Category
+}
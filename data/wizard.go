@@ -0,0 +1,37 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+type WizardChoices func(ctx context.Context) ([]string, error)
+
+type WizardFinish func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, answers *ParsedArgs) ([]slack.MsgOption, error)
+
+// Step is a question asked by a Wizard.
+type Step struct {
+	// Param describes the answer. The answer is validated like an argument of a command and is available to Finish
+	// under the name of the Param. Steps which are not Required may be skipped, in which case the Default is used.
+	Param Param
+	// Prompt is the question asked. Defaults to the Description of the Param.
+	Prompt string
+	// Choices when set, returns the answers which are accepted. The choices are listed in the prompt. The step is
+	// not restricted when the choices can not be retrieved.
+	Choices WizardChoices
+}
+
+// Wizard asks a series of questions in a thread before performing an action. Replies in the thread from the user
+// who started the wizard are routed to the wizard until it finishes, is cancelled or times out.
+type Wizard struct {
+	// Steps are asked in order.
+	Steps []Step
+	// Finish is called with the answers once every step has been answered.
+	Finish WizardFinish
+	// Timeout is how long the wizard waits for an answer. Defaults to 10 minutes.
+	Timeout time.Duration
+}
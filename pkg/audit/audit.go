@@ -0,0 +1,244 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Outcomes of a command invocation
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	OutcomeDenied  = "denied"
+	OutcomeInvalid = "invalid"
+)
+
+// maxLineSize bounds the size of a record which can be read back from the log
+const maxLineSize = 1 << 20
+
+// Object is an object which was changed by a command, such as a Kubernetes object or a Jira issue. The Namespace of
+// a Jira issue is its project.
+type Object struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Verb describes the change, such as create, update or delete.
+	Verb string `json:"verb"`
+}
+
+func (o Object) String() string {
+	if len(o.Namespace) > 0 {
+		return fmt.Sprintf("%s %s %s/%s", o.Verb, strings.ToLower(o.Kind), o.Namespace, o.Name)
+	}
+	return fmt.Sprintf("%s %s %s", o.Verb, strings.ToLower(o.Kind), o.Name)
+}
+
+// Record describes a single invocation of a command.
+type Record struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Channel string    `json:"channel,omitempty"`
+	// Command the command which was invoked, such as `ci pools cordon`.
+	Command string `json:"command"`
+	// Source how the command was invoked: message, slash, view, action, wizard or schedule.
+	Source string `json:"source"`
+	// Args the tokens of the command, including the command itself.
+	Args []string `json:"args,omitempty"`
+	// Params the arguments after they were validated against the command's Params.
+	Params   map[string]string `json:"params,omitempty"`
+	Outcome  string            `json:"outcome"`
+	Error    string            `json:"error,omitempty"`
+	Duration time.Duration     `json:"duration"`
+	Objects  []Object          `json:"objects,omitempty"`
+
+	mu sync.Mutex
+}
+
+// Finish sets the outcome and duration of the record. err is recorded when it is not nil.
+func (r *Record) Finish(outcome string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Outcome = outcome
+	if err != nil {
+		r.Error = err.Error()
+	}
+	r.Duration = time.Since(r.Time)
+}
+
+func (r *Record) addObject(object Object) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Objects = append(r.Objects, object)
+}
+
+// Filter selects records from the log. Zero values match every record.
+type Filter struct {
+	User string
+	// Command matches records whose command starts with Command
+	Command string
+	Since   time.Time
+	Until   time.Time
+	// Limit the number of records returned. The most recent records are returned.
+	Limit int
+}
+
+func (f Filter) matches(record *Record) bool {
+	if len(f.User) > 0 && record.User != f.User {
+		return false
+	}
+	if len(f.Command) > 0 && !strings.HasPrefix(record.Command, f.Command) {
+		return false
+	}
+	if !f.Since.IsZero() && record.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && record.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Log is an append-only log of records stored as JSON lines.
+type Log struct {
+	mu   sync.Mutex
+	path string
+}
+
+// Open returns a log which appends to the file at path. The file is created if it does not exist.
+func Open(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit log %s: %v", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("unable to open audit log %s: %v", path, err)
+	}
+	return &Log{path: path}, nil
+}
+
+// Write appends the record to the log.
+func (l *Log) Write(record *Record) error {
+	record.mu.Lock()
+	content, err := json.Marshal(record)
+	record.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("unable to marshal audit record: %v", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to open audit log %s: %v", l.path, err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(content, '\n')); err != nil {
+		return fmt.Errorf("unable to write audit record: %v", err)
+	}
+	return nil
+}
+
+// Query returns the records which match the filter, oldest first.
+func (l *Log) Query(filter Filter) ([]*Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.Open(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit log %s: %v", l.path, err)
+	}
+	defer file.Close()
+
+	var records []*Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		record := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			log.Warnf("skipping malformed audit record on line %d: %v", line, err)
+			continue
+		}
+		if filter.matches(record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read audit log %s: %v", l.path, err)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+	return records, nil
+}
+
+var (
+	defaultMu  sync.Mutex
+	defaultLog *Log
+)
+
+// SetDefault sets the log which records are written to by Write. Auditing is disabled when log is nil.
+func SetDefault(log *Log) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLog = log
+}
+
+// Default returns the log which records are written to, or nil if auditing is disabled.
+func Default() *Log {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	return defaultLog
+}
+
+// Write appends the record to the default log. Failures are logged rather than returned so that a problem with the
+// audit log does not prevent commands from being handled.
+func Write(record *Record) {
+	auditLog := Default()
+	if auditLog == nil {
+		return
+	}
+	if err := auditLog.Write(record); err != nil {
+		log.Warnf("%v", err)
+	}
+}
+
+type recordKey struct{}
+
+// NewContext returns a context which carries the record so that the objects changed while handling the
+// command can be added to it with Touched.
+func NewContext(ctx context.Context, record *Record) context.Context {
+	return context.WithValue(ctx, recordKey{}, record)
+}
+
+// FromContext returns the record carried by ctx, if any.
+func FromContext(ctx context.Context) *Record {
+	record, _ := ctx.Value(recordKey{}).(*Record)
+	return record
+}
+
+// Touched adds an object to the record carried by ctx. Nothing is recorded if ctx does not carry a record.
+func Touched(ctx context.Context, verb, kind, namespace, name string) {
+	record := FromContext(ctx)
+	if record == nil {
+		return
+	}
+	record.addObject(Object{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Verb:      verb,
+	})
+}
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteAndQuery(t *testing.T) {
+	auditLog, err := Open(filepath.Join(t.TempDir(), "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("unable to open audit log: %v", err)
+	}
+
+	now := time.Now()
+	records := []*Record{
+		{Time: now.Add(-48 * time.Hour), User: "U1", Command: "ci pools cordon", Outcome: OutcomeSuccess},
+		{Time: now.Add(-2 * time.Hour), User: "U2", Command: "ci lease release", Outcome: OutcomeSuccess},
+		{Time: now.Add(-time.Hour), User: "U1", Command: "ci pools uncordon", Outcome: OutcomeDenied},
+		{Time: now, User: "U1", Command: "ci pools cordon", Outcome: OutcomeError, Error: "no such pool"},
+	}
+	for _, record := range records {
+		if err := auditLog.Write(record); err != nil {
+			t.Fatalf("unable to write record: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{
+			name:     "everything",
+			expected: []string{"ci pools cordon", "ci lease release", "ci pools uncordon", "ci pools cordon"},
+		},
+		{
+			name:     "by user",
+			filter:   Filter{User: "U2"},
+			expected: []string{"ci lease release"},
+		},
+		{
+			name:     "by command prefix",
+			filter:   Filter{Command: "ci pools"},
+			expected: []string{"ci pools cordon", "ci pools uncordon", "ci pools cordon"},
+		},
+		{
+			name:     "by time range",
+			filter:   Filter{Since: now.Add(-3 * time.Hour), Until: now.Add(-30 * time.Minute)},
+			expected: []string{"ci lease release", "ci pools uncordon"},
+		},
+		{
+			name:     "limit returns the most recent",
+			filter:   Filter{User: "U1", Limit: 2},
+			expected: []string{"ci pools uncordon", "ci pools cordon"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			found, err := auditLog.Query(tc.filter)
+			if err != nil {
+				t.Fatalf("unable to query: %v", err)
+			}
+			if len(found) != len(tc.expected) {
+				t.Fatalf("expected %d records, got %d", len(tc.expected), len(found))
+			}
+			for idx, record := range found {
+				if record.Command != tc.expected[idx] {
+					t.Errorf("expected record %d to be %s, got %s", idx, tc.expected[idx], record.Command)
+				}
+			}
+		})
+	}
+}
+
+func TestQuerySkipsMalformedRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	content := `{"user":"U1","command":"ci lease acquire","outcome":"success"}
+not json
+{"user":"U2","command":"ci lease release","outcome":"success"}
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("unable to write audit log: %v", err)
+	}
+	auditLog, err := Open(path)
+	if err != nil {
+		t.Fatalf("unable to open audit log: %v", err)
+	}
+	found, err := auditLog.Query(Filter{})
+	if err != nil {
+		t.Fatalf("unable to query: %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(found))
+	}
+}
+
+func TestTouched(t *testing.T) {
+	// objects touched without a record are ignored
+	Touched(context.TODO(), "update", "Pool", "ns", "pool-1")
+
+	record := &Record{Time: time.Now()}
+	ctx := NewContext(context.TODO(), record)
+	Touched(ctx, "update", "Pool", "ns", "pool-1")
+	Touched(ctx, "delete", "Lease", "ns", "user-lease-abc")
+	record.Finish(OutcomeError, errors.New("failed"))
+
+	if len(record.Objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(record.Objects))
+	}
+	if record.Objects[1].String() != "delete lease ns/user-lease-abc" {
+		t.Errorf("unexpected object: %s", record.Objects[1])
+	}
+	if record.Outcome != OutcomeError || record.Error != "failed" {
+		t.Errorf("unexpected outcome: %s %s", record.Outcome, record.Error)
+	}
+}
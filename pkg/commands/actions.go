@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+var (
+	actionMu sync.Mutex
+	actions  = []data.Action{}
+)
+
+// CloseAction deletes the message containing the component. Use util.NewCloseButton to add a close button to a
+// response.
+var CloseAction = data.Action{
+	ActionID:           util.CloseActionID,
+	AllowNonSplatUsers: true,
+	Handler: func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+		_, _, err := client.PostMessage(callback.Channel.ID, slack.MsgOptionDeleteOriginal(callback.ResponseURL))
+		if err != nil {
+			return nil, fmt.Errorf("unable to close message: %v", err)
+		}
+		return nil, nil
+	},
+}
+
+// AddAction adds a handler for interactions with Block Kit components. Actions declared by commands are added when
+// the command is added.
+func AddAction(actionsToAdd ...data.Action) {
+	actionMu.Lock()
+	defer actionMu.Unlock()
+	for _, action := range actionsToAdd {
+		log.Printf("adding action: %s%s", action.ActionID, action.BlockID)
+		actions = append(actions, action)
+	}
+}
+
+func getActions() []data.Action {
+	actionMu.Lock()
+	defer actionMu.Unlock()
+
+	newActions := make([]data.Action, len(actions))
+
+	copy(newActions, actions)
+	return newActions
+}
+
+// findAction returns the action which handles the component. Actions matched by action_id are preferred over those
+// matched by block_id, and longer prefixes are preferred over shorter ones.
+func findAction(blockAction *slack.BlockAction) (data.Action, bool) {
+	var found data.Action
+	foundByActionID := false
+	best := 0
+	for _, action := range getActions() {
+		byActionID := false
+		prefix := 0
+		if len(action.ActionID) > 0 && strings.HasPrefix(blockAction.ActionID, action.ActionID) {
+			byActionID = true
+			prefix = len(action.ActionID)
+		} else if len(action.BlockID) > 0 && strings.HasPrefix(blockAction.BlockID, action.BlockID) {
+			prefix = len(action.BlockID)
+		}
+		if prefix == 0 || (foundByActionID && !byActionID) {
+			continue
+		}
+		if byActionID == foundByActionID && prefix <= best {
+			continue
+		}
+		found = action
+		foundByActionID = byActionID
+		best = prefix
+	}
+	return found, best > 0
+}
+
+// actionValue returns the value selected or submitted by the component
+func actionValue(blockAction *slack.BlockAction) string {
+	switch {
+	case len(blockAction.Value) > 0:
+		return blockAction.Value
+	case len(blockAction.SelectedOption.Value) > 0:
+		return blockAction.SelectedOption.Value
+	case len(blockAction.SelectedUser) > 0:
+		return blockAction.SelectedUser
+	case len(blockAction.SelectedChannel) > 0:
+		return blockAction.SelectedChannel
+	}
+	return ""
+}
+
+// isHomeTabAction checks if the interaction is with a component on the App Home tab. The interaction has no channel
+// so responses are sent to the user in a DM.
+func isHomeTabAction(callback slack.InteractionCallback) bool {
+	return len(callback.Channel.ID) == 0 && callback.View.Type == slack.VTHomeTab
+}
+
+// ActionHandler dispatches block_actions interactions to the registered actions.
+func ActionHandler(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback) error {
+	responseChannel := callback.Channel.ID
+	if isHomeTabAction(callback) {
+		var err error
+		responseChannel, err = getDMChannelIDByUser(client, callback.User.ID)
+		if err != nil {
+			return fmt.Errorf("unable to respond to action from the home tab: %v", err)
+		}
+	}
+
+	for _, blockAction := range callback.ActionCallback.BlockActions {
+		action, found := findAction(blockAction)
+		if !found {
+			log.Debugf("no action registered for action_id %s, block_id %s", blockAction.ActionID, blockAction.BlockID)
+			continue
+		}
+		log.Debugf("found action: %s%s", action.ActionID, action.BlockID)
+
+		var command []string
+		var record *audit.Record
+		actionCtx := ctx
+		if len(action.Commands) > 0 {
+			command = append(append(command, action.Commands...), strings.Fields(actionValue(blockAction))...)
+			record = newAuditRecord(auditSourceAction, callback.User.ID, callback.Channel.ID, action.Commands, command)
+			actionCtx = audit.NewContext(ctx, record)
+		}
+		err := authorizeUser(client, callback.User.ID, callback.Channel.ID, command, action.AllowNonSplatUsers)
+		if err != nil {
+			finishAuditRecord(record, audit.OutcomeDenied, err)
+			return denyUser(client, callback.User.ID, responseChannel, err)
+		}
+
+		response, err := action.Handler(actionCtx, client, callback, blockAction)
+		if err != nil {
+			log.Warnf("failed processing action: %v", err)
+			finishAuditRecord(record, audit.OutcomeError, err)
+		} else {
+			finishAuditRecord(record, audit.OutcomeSuccess, nil)
+		}
+		if len(response) == 0 {
+			continue
+		}
+
+		switch {
+		case isHomeTabAction(callback):
+			_, _, err = client.PostMessage(responseChannel, response...)
+		case action.ReplaceOriginal:
+			response = append(response, slack.MsgOptionReplaceOriginal(callback.ResponseURL))
+			_, _, err = client.PostMessage(callback.Channel.ID, response...)
+		default:
+			_, err = client.PostEphemeral(callback.Channel.ID, callback.User.ID, response...)
+		}
+		if err != nil {
+			return fmt.Errorf("failed responding to action: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// withActions replaces the registered actions for the duration of a test
+func withActions(t *testing.T, testActions ...data.Action) {
+	actionMu.Lock()
+	saved := actions
+	actions = []data.Action{}
+	actionMu.Unlock()
+
+	AddAction(testActions...)
+	t.Cleanup(func() {
+		actionMu.Lock()
+		actions = saved
+		actionMu.Unlock()
+	})
+}
+
+func TestFindAction(t *testing.T) {
+	var invoked []string
+	handler := func(name string) data.ActionCallback {
+		return func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+			invoked = append(invoked, name+":"+action.Value)
+			return nil, nil
+		}
+	}
+	withActions(t,
+		data.Action{ActionID: "pool", Handler: handler("pool")},
+		data.Action{ActionID: "pool-cordon", Handler: handler("pool-cordon")},
+		data.Action{BlockID: "pool-actions", Handler: handler("pool-actions")},
+	)
+
+	cases := map[string]*slack.BlockAction{
+		"pool-cordon":  {ActionID: "pool-cordon-pool-1", BlockID: "pool-actions-pool-1"},
+		"pool":         {ActionID: "pool-uncordon-pool-1", BlockID: "pool-actions-pool-1"},
+		"pool-actions": {ActionID: "other", BlockID: "pool-actions-pool-1"},
+	}
+	for expected, blockAction := range cases {
+		action, found := findAction(blockAction)
+		if !found {
+			t.Fatalf("expected an action to be found for %s", blockAction.ActionID)
+		}
+		if action.ActionID+action.BlockID != expected {
+			t.Errorf("expected %s to handle %s, got %s%s", expected, blockAction.ActionID, action.ActionID, action.BlockID)
+		}
+	}
+
+	if _, found := findAction(&slack.BlockAction{ActionID: "unknown", BlockID: "unknown"}); found {
+		t.Errorf("expected no action to be found")
+	}
+
+	callback := slack.InteractionCallback{
+		Type: slack.InteractionTypeBlockActions,
+		ActionCallback: slack.ActionCallbacks{
+			BlockActions: []*slack.BlockAction{{ActionID: "pool-cordon-pool-1", Value: "pool-1"}},
+		},
+	}
+	if err := ActionHandler(context.TODO(), &util.StubInterface{}, callback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(invoked) != 1 || invoked[0] != "pool-cordon:pool-1" {
+		t.Errorf("expected the pool-cordon action to be invoked with pool-1, got %v", invoked)
+	}
+}
@@ -20,14 +20,21 @@ var AskDocsAttributes = data.Attributes{
 	RequireMention:      true,
 	ResponseIsEphemeral: false,
 	AllowNonSplatUsers:  true,
-	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+	Params: []data.Param{
+		{
+			Name:      "question",
+			Required:  true,
+			Remainder: true,
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
 		url := os.Getenv("DOC_QUERY_URL")
 		if url == "" {
 			url = "http://localhost:8000/"
 		}
 
-		log.Debugf("question: %v\n", args)
-		question := args[1]
+		question := args.String("question")
+		log.Debugf("question: %v\n", question)
 
 		response := "sorry! I was unable to find an answer."
 
@@ -49,7 +56,7 @@ var AskDocsAttributes = data.Attributes{
 		}
 		return util.StringToBlock(response, false), nil
 	},
-	RequiredArgs: 2,
+	Category:     data.CategoryLLM,
 	HelpMarkdown: "ask docs a question: `ask-docs ask a question`",
 	ShouldMatch: []string{
 		"ask-docs how do I rotate credentials for vSphere?",
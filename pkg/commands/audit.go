@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// sources of audit records
+const (
+	auditSourceMessage  = "message"
+	auditSourceSlash    = "slash"
+	auditSourceView     = "view"
+	auditSourceAction   = "action"
+	auditSourceWizard   = "wizard"
+	auditSourceSchedule = "schedule"
+)
+
+func init() {
+	AddCommand(AuditAttributes)
+}
+
+// newAuditRecord starts a record of a command invocation. The record is finished with finishAuditRecord.
+func newAuditRecord(source, user, channel string, command, args []string) *audit.Record {
+	return &audit.Record{
+		Time:    time.Now(),
+		User:    user,
+		Channel: channel,
+		Command: strings.Join(command, " "),
+		Source:  source,
+		Args:    args,
+	}
+}
+
+// finishAuditRecord sets the outcome of the record, writes it to the audit log and records the invocation in the
+// command metrics. record may be nil for invocations which are not audited.
+func finishAuditRecord(record *audit.Record, outcome string, err error) {
+	if record == nil {
+		return
+	}
+	record.Finish(outcome, err)
+	audit.Write(record)
+	metrics.ObserveCommand(record.Command, record.Source, record.Outcome, record.Duration)
+}
+
+// viewValues returns the values submitted in a view keyed by the action_id of the input
+func viewValues(view slack.View) map[string]string {
+	values := map[string]string{}
+	if view.State == nil {
+		return values
+	}
+	for _, block := range view.State.Values {
+		for actionID, action := range block {
+			switch {
+			case len(action.Value) > 0:
+				values[actionID] = action.Value
+			case len(action.SelectedOption.Value) > 0:
+				values[actionID] = action.SelectedOption.Value
+			}
+		}
+	}
+	return values
+}
+
+func formatAuditRecord(record *audit.Record) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "`%s` <@%s>", record.Time.Local().Format("2006-01-02 15:04:05"), record.User)
+	if len(record.Channel) > 0 {
+		fmt.Fprintf(&builder, " in <#%s>", record.Channel)
+	}
+	invocation := record.Command
+	if len(record.Args) > 0 {
+		invocation = strings.Join(record.Args, " ")
+	}
+	fmt.Fprintf(&builder, " ran `%s` via %s: *%s* (%s)", invocation, record.Source, record.Outcome, record.Duration.Round(time.Millisecond))
+	if len(record.Error) > 0 {
+		fmt.Fprintf(&builder, " %s", record.Error)
+	}
+	for _, object := range record.Objects {
+		fmt.Fprintf(&builder, "\n    %s", object)
+	}
+	return builder.String()
+}
+
+var AuditAttributes = data.Attributes{
+	Commands:       []string{"audit"},
+	RequireMention: true,
+	AdminOnly:      true,
+	RespondInDM:    true,
+	Params: []data.Param{
+		{
+			Name:        "user",
+			Type:        data.ParamUser,
+			KeyValue:    true,
+			Description: "only show commands run by the user",
+		},
+		{
+			Name:        "command",
+			KeyValue:    true,
+			Description: "only show commands which start with the command",
+		},
+		{
+			Name:        "since",
+			Type:        data.ParamDuration,
+			KeyValue:    true,
+			Default:     "24h",
+			Description: "only show commands run within the duration",
+		},
+		{
+			Name:        "until",
+			Type:        data.ParamDuration,
+			KeyValue:    true,
+			Description: "only show commands run before the duration ago",
+		},
+		{
+			Name:        "limit",
+			Type:        data.ParamInt,
+			KeyValue:    true,
+			Default:     "20",
+			Min:         1,
+			Max:         100,
+			Description: "maximum number of commands to show",
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		auditLog := audit.Default()
+		if auditLog == nil {
+			return util.StringToBlock("the audit log is not enabled. set AUDIT_LOG_PATH to enable it.", false), nil
+		}
+
+		now := time.Now()
+		filter := audit.Filter{
+			User:    args.String("user"),
+			Command: args.String("command"),
+			Since:   now.Add(-args.Duration("since")),
+			Limit:   args.Int("limit"),
+		}
+		if args.Has("until") {
+			filter.Until = now.Add(-args.Duration("until"))
+		}
+
+		records, err := auditLog.Query(filter)
+		if err != nil {
+			return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to query audit log: %v", err)
+		}
+		if len(records) == 0 {
+			return util.StringToBlock("no commands matched", false), nil
+		}
+
+		lines := make([]string, 0, len(records))
+		for _, record := range records {
+			lines = append(lines, formatAuditRecord(record))
+		}
+		return util.StringToBlock(strings.Join(lines, "\n"), false), nil
+	},
+	HelpMarkdown: "show who ran which commands. admins only: `audit user=<@user> command=\"ci pools cordon\" since=7d`",
+	ShouldMatch: []string{
+		"audit",
+		"audit user=<@U1234> since=7d",
+	},
+	ShouldntMatch: []string{
+		"ci lease list",
+	},
+}
@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
+	"github.com/openshift-splat-team/splat-bot/pkg/policy"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// withAuditLog writes audit records to a temporary log for the duration of a test
+func withAuditLog(t *testing.T) *audit.Log {
+	auditLog, err := audit.Open(filepath.Join(t.TempDir(), "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("unable to open audit log: %v", err)
+	}
+	saved := audit.Default()
+	audit.SetDefault(auditLog)
+	t.Cleanup(func() {
+		audit.SetDefault(saved)
+	})
+	return auditLog
+}
+
+func TestInvokeAttributeIsAudited(t *testing.T) {
+	auditLog := withAuditLog(t)
+
+	attribute := data.Attributes{
+		Commands:           []string{"ci", "pools", "cordon"},
+		AllowNonSplatUsers: true,
+		Params:             poolNameParams,
+		ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+			audit.Touched(ctx, "update", "Pool", "vsphere-infra-helpers", args.String("pool"))
+			return nil, errors.New("pool is busy")
+		},
+	}
+	msg := &slackevents.MessageEvent{User: "U1", Channel: "C1"}
+	for _, args := range [][]string{{"ci", "pools", "cordon", "pool-1"}, {"ci", "pools", "cordon"}} {
+		if _, err := invokeAttribute(context.TODO(), &util.StubInterface{}, msg, attribute, args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	records, err := auditLog.Query(audit.Filter{})
+	if err != nil {
+		t.Fatalf("unable to query audit log: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	record := records[0]
+	if record.User != "U1" || record.Channel != "C1" || record.Command != "ci pools cordon" || record.Source != auditSourceMessage {
+		t.Errorf("unexpected record: %+v", record)
+	}
+	if record.Outcome != audit.OutcomeError || record.Error != "pool is busy" {
+		t.Errorf("expected the error to be recorded, got %s %q", record.Outcome, record.Error)
+	}
+	if record.Params["pool"] != "pool-1" {
+		t.Errorf("expected the parsed pool to be recorded, got %v", record.Params)
+	}
+	if len(record.Objects) != 1 || record.Objects[0].Name != "pool-1" {
+		t.Errorf("expected the pool to be recorded, got %v", record.Objects)
+	}
+
+	if records[1].Outcome != audit.OutcomeInvalid {
+		t.Errorf("expected a missing argument to be recorded as invalid, got %s", records[1].Outcome)
+	}
+}
+
+func TestAdminOnly(t *testing.T) {
+	auditLog := withAuditLog(t)
+
+	saved := authPolicy
+	t.Cleanup(func() {
+		authPolicy = saved
+	})
+	var err error
+	authPolicy, err = policy.Parse([]byte("admins:\n  users: [U1]\n"))
+	if err != nil {
+		t.Fatalf("unable to parse policy: %v", err)
+	}
+
+	invoked := 0
+	attribute := data.Attributes{
+		Commands:           []string{"audit"},
+		AllowNonSplatUsers: true,
+		AdminOnly:          true,
+		Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+			invoked++
+			return nil, nil
+		},
+	}
+
+	_, err = invokeAttribute(context.TODO(), &util.StubInterface{}, &slackevents.MessageEvent{User: "U2"}, attribute, []string{"audit"})
+	if err == nil {
+		t.Fatalf("expected a user who is not an admin to be denied")
+	}
+	_, err = invokeAttribute(context.TODO(), &util.StubInterface{}, &slackevents.MessageEvent{User: "U1"}, attribute, []string{"audit"})
+	if err != nil {
+		t.Fatalf("expected an admin to be allowed: %v", err)
+	}
+	if invoked != 1 {
+		t.Fatalf("expected the command to be invoked once, got %d", invoked)
+	}
+
+	records, err := auditLog.Query(audit.Filter{})
+	if err != nil {
+		t.Fatalf("unable to query audit log: %v", err)
+	}
+	if len(records) != 2 || records[0].Outcome != audit.OutcomeDenied || records[1].Outcome != audit.OutcomeSuccess {
+		t.Fatalf("expected a denied and a successful record, got %+v", records)
+	}
+}
+
+func TestSlashCommandPolicy(t *testing.T) {
+	withAuditLog(t)
+
+	savedPolicy := authPolicy
+	slashMu.Lock()
+	savedCommands := slashCommands
+	slashMu.Unlock()
+	t.Cleanup(func() {
+		authPolicy = savedPolicy
+		slashMu.Lock()
+		slashCommands = savedCommands
+		slashMu.Unlock()
+	})
+	var err error
+	authPolicy, err = policy.Parse([]byte("rules:\n  - name: jira\n    commands: [jira create]\n    users: [U1]\n"))
+	if err != nil {
+		t.Fatalf("unable to parse policy: %v", err)
+	}
+
+	invoked := 0
+	slashMu.Lock()
+	slashCommands = nil
+	slashMu.Unlock()
+	AddSlashCommand(data.SlashCommand{
+		Commands:    []string{"/jira-create"},
+		AuthorizeAs: []string{"jira", "create"},
+		ProcessCommand: func(ctx context.Context, client util.SlackClientInterface, command slack.SlashCommand, args []string) ([]slack.MsgOption, error) {
+			invoked++
+			return nil, nil
+		},
+	})
+
+	// the slash command is covered by the rule for the command sent in a message
+	client := util.NewFakeClient()
+	if err := SlashHandler(context.TODO(), client, slack.SlashCommand{Command: "/jira-create", UserID: "U2", ChannelID: "C1"}); err == nil {
+		t.Fatalf("expected a user who isn't granted jira create to be denied")
+	}
+	if err := SlashHandler(context.TODO(), client, slack.SlashCommand{Command: "/jira-create", UserID: "U1", ChannelID: "C1"}); err != nil {
+		t.Fatalf("expected a user who is granted jira create to be allowed: %v", err)
+	}
+	if invoked != 1 {
+		t.Fatalf("expected the command to be invoked once, got %d", invoked)
+	}
+}
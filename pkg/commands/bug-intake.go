@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"text/template"
+
+	"github.com/openshift-splat-team/jira-bot/cmd/issue"
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const bugTemplateSource = `
+*Description of problem:*
+{{.Summary}}
+
+*Steps to Reproduce:*
+{{.Steps}}
+
+*Expected results:*
+{{.Expected}}
+
+*Actual results:*
+{{.Actual}}
+
+*Severity:*
+{{.Severity}}
+{{if .ThreadURL}}
+reported in thread: {{.ThreadURL}}
+{{end}}
+issue created by splat-bot
+`
+
+var bugTemplate = template.Must(template.New("bug").Parse(bugTemplateSource))
+
+type bugDescriptionInput struct {
+	Summary   string
+	Steps     string
+	Expected  string
+	Actual    string
+	Severity  string
+	ThreadURL string
+}
+
+var bugSummaryParam = data.Param{
+	Name:        "summary",
+	Remainder:   true,
+	Required:    true,
+	Description: "summary of the bug",
+}
+
+var BugIntakeAttributes = data.Attributes{
+	Commands:       []string{"jira", "bug"},
+	RequireMention: true,
+	Params: []data.Param{
+		{
+			Name:        bugSummaryParam.Name,
+			Remainder:   true,
+			Description: bugSummaryParam.Description,
+		},
+	},
+	Wizard: &data.Wizard{
+		Steps: []data.Step{
+			{
+				Param:  bugSummaryParam,
+				Prompt: "what is the problem, in one line?",
+			},
+			{
+				Param: data.Param{
+					Name:     "steps",
+					Required: true,
+				},
+				Prompt: "what are the steps to reproduce it?",
+			},
+			{
+				Param: data.Param{
+					Name:     "expected",
+					Required: true,
+				},
+				Prompt: "what did you expect to happen?",
+			},
+			{
+				Param: data.Param{
+					Name:     "actual",
+					Required: true,
+				},
+				Prompt: "what happened instead?",
+			},
+			{
+				Param: data.Param{
+					Name:    "severity",
+					Type:    data.ParamEnum,
+					Values:  []string{"Low", "Normal", "Important", "Critical"},
+					Default: "Normal",
+				},
+				Prompt: "how severe is it?",
+			},
+		},
+		Finish: createBug,
+	},
+	Category:     data.CategoryJira,
+	HelpMarkdown: "report a bug by answering a few questions: `jira bug [summary]`",
+	ShouldMatch: []string{
+		"jira bug",
+		"jira bug the installer fails on vSphere 8",
+	},
+	ShouldntMatch: []string{
+		"jira create description",
+		"jira create-with-thread PROJECT bug",
+	},
+}
+
+func init() {
+	AddCommand(BugIntakeAttributes)
+}
+
+func createBug(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, answers *data.ParsedArgs) ([]slack.MsgOption, error) {
+	var issueKey, issueURL string
+
+	summary := answers.String("summary")
+	description, err := invokeTemplate(bugTemplate, bugDescriptionInput{
+		Summary:   summary,
+		Steps:     answers.String("steps"),
+		Expected:  answers.String("expected"),
+		Actual:    answers.String("actual"),
+		Severity:  answers.String("severity"),
+		ThreadURL: util.GetThreadUrl(evt),
+	})
+	if err != nil {
+		return util.StringToBlock(fmt.Sprintf("unable to to process template. error: %v", err), false), nil
+	}
+
+	if JIRA_TEST_MODE_ENABLED {
+		issueKey = "Key"
+		issueURL = "www.example.com"
+	} else {
+		jiraIssue, err := issue.CreateIssue("SPLAT", summary, description, "Bug")
+		if err != nil {
+			return util.WrapErrorToBlock(err, "error creating issue"), nil
+		}
+		issueKey = jiraIssue.Key
+		issueURL = fmt.Sprintf("%s/browse/%s", JIRA_BASE_URL, issueKey)
+		audit.Touched(ctx, "create", "Issue", "SPLAT", issueKey)
+	}
+	return util.StringToBlock(fmt.Sprintf("bug <%s|%s> created", issueURL, issueKey), false), nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
@@ -13,7 +14,9 @@ import (
 	"github.com/slack-go/slack/slackevents"
 
 	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
 	"github.com/openshift-splat-team/splat-bot/pkg/chat"
+	"github.com/openshift-splat-team/splat-bot/pkg/policy"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 )
 
@@ -21,6 +24,7 @@ var (
 	attributeMu        sync.Mutex
 	attributes         = []data.Attributes{}
 	allowedUsers       = map[string]bool{}
+	authPolicy         *policy.Policy
 	enableChatResponse = false
 	slashMu            sync.Mutex
 	slashCommands      = []data.SlashCommand{}
@@ -34,10 +38,11 @@ func AddCommand(attribute data.Attributes, handler ...data.MessageOfInterest) {
 	log.Printf("adding command: %v", attribute.Commands)
 	if len(handler) > 0 {
 		attribute.MessageOfInterest = handler[0]
-	} else {
+	} else if attribute.MessageOfInterest == nil {
 		attribute.MessageOfInterest = checkForCommand
 	}
 	attributes = append(attributes, attribute)
+	AddAction(attribute.Actions...)
 }
 
 // AddSlashCommand adds a handler to the list of handlers.
@@ -46,6 +51,7 @@ func AddSlashCommand(slashCmd data.SlashCommand) {
 	defer slashMu.Unlock()
 	log.Printf("adding slash command: %v", slashCmd.Commands)
 	slashCommands = append(slashCommands, slashCmd)
+	AddAction(slashCmd.Actions...)
 }
 
 func getAttributes() []data.Attributes {
@@ -80,12 +86,11 @@ func init() {
 	AddCommand(CreateJiraWithThreadAttributes)
 
 	AddSlashCommand(JiraCreateSlashCommand)
+
+	AddAction(CloseAction)
 }
 
 func Initialize() error {
-	// TODO:  Global allowed users means we cannot make some actions available to some users while others not.  This could
-	//        be beefed up in the future to be allowed users per command from config provided by a yaml file or something of
-	//        that nature.
 	allowed := os.Getenv("SLACK_ALLOWED_USERS")
 	if len(allowed) == 0 {
 		log.Warnf("Disabling user enforcement.  Please configure SLACK_ALLOWED_USERS if you wish to enforce allowed users on certain commands.")
@@ -96,29 +101,118 @@ func Initialize() error {
 			log.Infof("user id %s is allowed", user)
 		}
 	}
+
+	// The policy grants individual commands to users, user groups and channels. Commands which are not covered by
+	// the policy fall back to SLACK_ALLOWED_USERS.
+	policyPath := os.Getenv("SLACK_POLICY_PATH")
+	if len(policyPath) > 0 {
+		loaded, err := policy.Load(policyPath)
+		if err != nil {
+			return fmt.Errorf("unable to load authorization policy: %v", err)
+		}
+		authPolicy = loaded
+		log.Infof("loaded %d authorization rules from %s", len(authPolicy.Rules), policyPath)
+	}
+
+	githubUsersPath := os.Getenv("SLACK_GITHUB_USERS_PATH")
+	if len(githubUsersPath) > 0 {
+		if err := loadGithubLogins(githubUsersPath); err != nil {
+			return err
+		}
+		log.Infof("loaded GitHub logins from %s", githubUsersPath)
+	}
+
+	if err := initializeSchedules(); err != nil {
+		return err
+	}
+	if err := initializeReminders(); err != nil {
+		return err
+	}
+
+	auditPath := os.Getenv("AUDIT_LOG_PATH")
+	if len(auditPath) > 0 {
+		auditLog, err := audit.Open(auditPath)
+		if err != nil {
+			return err
+		}
+		audit.SetDefault(auditLog)
+		log.Infof("writing audit records to %s", auditPath)
+	} else {
+		log.Warnf("Disabling the audit log.  Please configure AUDIT_LOG_PATH if you wish to record who ran which commands.")
+	}
 	return nil
 }
 
-func isAllowedUser(evt *slackevents.MessageEvent) error {
+func isAllowedUser(user string) error {
 	log.Debugf("User size: %d\n", len(allowedUsers))
-	if _, found := allowedUsers[evt.User]; !found && len(allowedUsers) > 0 {
-		return fmt.Errorf("user %s with id %s is not allowed", evt.Username, evt.User)
+	if _, found := allowedUsers[user]; !found && len(allowedUsers) > 0 {
+		return fmt.Errorf("user <@%s> is not in SLACK_ALLOWED_USERS", user)
 	}
 	return nil
 }
+
+// authorizeUser checks if the user may run the command. command contains the tokens of the command, including its
+// arguments, so that policy rules can distinguish sub-commands such as `ci pools cordon` and `ci pools list`.
+func authorizeUser(client util.SlackClientInterface, user, channel string, command []string, allowNonSplatUsers bool) error {
+	if len(command) > 0 {
+		matched, err := authPolicy.Authorize(client, policy.Request{
+			User:    user,
+			Channel: channel,
+			Command: command,
+		})
+		if matched {
+			return err
+		}
+	}
+	if allowNonSplatUsers {
+		return nil
+	}
+	return isAllowedUser(user)
+}
+
+// authorizeAdmin checks if the user is one of the admins listed in the authorization policy.
+func authorizeAdmin(client util.SlackClientInterface, user string) error {
+	if !authPolicy.IsAdmin(client, user) {
+		return fmt.Errorf("user <@%s> is not an admin", user)
+	}
+	return nil
+}
+
+// authorizeAttribute checks if the user may run the command described by attribute, including whether they are an
+// admin when the command is AdminOnly.
+func authorizeAttribute(client util.SlackClientInterface, user, channel string, command []string, attribute data.Attributes) error {
+	err := authorizeUser(client, user, channel, command, attribute.AllowNonSplatUsers)
+	if err == nil && attribute.AdminOnly {
+		err = authorizeAdmin(client, user)
+	}
+	return err
+}
+
+// denyUser lets the user know why they may not run a command.
+func denyUser(client util.SlackClientInterface, user, channel string, reason error) error {
+	_, err := client.PostEphemeral(channel, user, util.StringToBlock(fmt.Sprintf("sorry, you are not allowed to do that. %v", reason), false)...)
+	if err != nil {
+		log.Warnf("failed to notify user of denial: %v", err)
+	}
+	return fmt.Errorf("user not allowed: %v", reason)
+}
+
 func tokenize(msgText string, glob bool) []string {
 	msgText = strings.ReplaceAll(msgText, "\n", " ")
 	var tokens []string
 	if glob {
-		re := regexp.MustCompile(`"([^"]*?)"|(\S+)`)
+		// key="a b" is one token so that options may have values with spaces
+		re := regexp.MustCompile(`(\S+?=)"([^"]*)"|"([^"]*?)"|(\S+)`)
 		matches := re.FindAllStringSubmatch(msgText, -1)
 
 		for _, match := range matches {
 			if match[1] != "" {
+				tokens = append(tokens, match[1]+match[2])
+			} else if match[3] != "" {
 				// Remove leading and trailing quotation marks
-				tokens = append(tokens, strings.Trim(match[1], "\""))
+				tokens = append(tokens, strings.Trim(match[3], "\""))
 			} else {
-				tokens = append(tokens, match[2])
+				tokens = append(tokens, match[4])
 			}
 		}
 		return tokens
@@ -148,10 +242,43 @@ func SlashHandler(ctx context.Context, client util.SlackClientInterface, cmd sla
 		args := tokenize(cmd.Text, !command.DontGlobQuotes)
 		if checkForSlashCommand(cmd.Command, command) {
 			log.Debugf("Found command: %v", command.Commands)
+			record := newAuditRecord(auditSourceSlash, cmd.UserID, cmd.ChannelID, []string{cmd.Command}, append([]string{cmd.Command}, args...))
+			ctx := audit.NewContext(ctx, record)
+			err := authorizeUser(client, cmd.UserID, cmd.ChannelID, append(slashCommandTokens(command), args...), command.AllowNonSplatUsers)
+			if err != nil {
+				finishAuditRecord(record, audit.OutcomeDenied, err)
+				return denyUser(client, cmd.UserID, cmd.ChannelID, err)
+			}
 
-			_, err := command.ProcessCommand(ctx, client, cmd, args)
+			if len(command.Params) > 0 {
+				parsed, err := parseParams(command.Params, args, args)
+				if err != nil {
+					finishAuditRecord(record, audit.OutcomeInvalid, err)
+					_, err = client.PostEphemeral(cmd.ChannelID, cmd.UserID, util.StringToBlock(usageError(err, []string{cmd.Command}, command.Params, command.HelpMarkdown), false)...)
+					if err != nil {
+						log.Warnf("failed to post usage: %v", err)
+					}
+					continue
+				}
+				record.Params = parsed.Values()
+				if command.ProcessParsedCommand != nil {
+					_, err = command.ProcessParsedCommand(ctx, client, cmd, parsed)
+					if err != nil {
+						log.Warnf("failed processing message: %v", err)
+						finishAuditRecord(record, audit.OutcomeError, err)
+					} else {
+						finishAuditRecord(record, audit.OutcomeSuccess, nil)
+					}
+					continue
+				}
+			}
+
+			_, err = command.ProcessCommand(ctx, client, cmd, args)
 			if err != nil {
 				log.Warnf("failed processing message: %v", err)
+				finishAuditRecord(record, audit.OutcomeError, err)
+			} else {
+				finishAuditRecord(record, audit.OutcomeSuccess, nil)
 			}
 		}
 	}
@@ -165,9 +292,14 @@ func ViewSubmissionHandler(ctx context.Context, client util.SlackClientInterface
 		check := command.ViewSubmissionCheck
 		if check != nil && check(evt.View.CallbackID) {
 			log.Debugf("Found command: %v", command.Commands)
-			response, err := command.HandleViewSubmission(ctx, client, evt)
+			record := newAuditRecord(auditSourceView, evt.User.ID, evt.View.PrivateMetadata, command.Commands, []string{evt.View.CallbackID})
+			record.Params = viewValues(evt.View)
+			response, err := command.HandleViewSubmission(audit.NewContext(ctx, record), client, evt)
 			if err != nil {
 				log.Warnf("failed processing message: %v", err)
+				finishAuditRecord(record, audit.OutcomeError, err)
+			} else {
+				finishAuditRecord(record, audit.OutcomeSuccess, nil)
 			}
 
 			// This block should be moved into a function and shared w/ other workflows
@@ -178,7 +310,8 @@ func ViewSubmissionHandler(ctx context.Context, client util.SlackClientInterface
 				channelID := evt.View.PrivateMetadata
 				userID := evt.User.ID
 
-				if command.RespondInDM {
+				// views opened from global shortcuts are not associated with a channel
+				if command.RespondInDM || len(channelID) == 0 {
 					channelID, err = getDMChannelIDByUser(client, evt.User.ID)
 					if err != nil {
 						log.Warnf("failed getting channel ID: %v", err)
@@ -214,6 +347,10 @@ func Handler(ctx context.Context, client util.SlackClientInterface, evt slackeve
 		return nil
 	}
 
+	if homeEvent, ok := evt.InnerEvent.Data.(*slackevents.AppHomeOpenedEvent); ok {
+		return HomeHandler(ctx, client, homeEvent)
+	}
+
 	msg := &slackevents.MessageEvent{}
 	switch ev := evt.InnerEvent.Data.(type) {
 	case *slackevents.AppMentionEvent:
@@ -240,8 +377,77 @@ func Handler(ctx context.Context, client util.SlackClientInterface, evt slackeve
 		return nil
 	}
 
-	var args []string
-	var response []slack.MsgOption
+	if deduplicator.isDuplicate(eventKeys(evt, msg)...) {
+		return nil
+	}
+
+	// replies in a thread with a wizard in progress answer the wizard rather than being matched to a command
+	if handled, err := wizards.handle(ctx, client, msg); handled {
+		return err
+	}
+
+	candidates := matchAttributes(msg, isAppMentionEvent)
+	responded := false
+	if len(candidates) > 0 {
+		best := candidates[0]
+		if len(candidates) > 1 {
+			log.Debugf("%d commands matched, dispatching to %v with rank %d", len(candidates), best.attribute.Commands, best.attribute.Rank)
+		}
+		var err error
+		responded, err = runAttribute(ctx, client, msg, best.attribute, best.args)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the bot was asked to do something it doesn't recognize. try to point the user towards what they meant.
+	if !responded && util.ContainsBotMention(msg.Text) && !hasCommandMatch(candidates) {
+		args := tokenize(msg.Text, true)[1:]
+		if suggestions := suggestCommands(args); len(suggestions) > 0 {
+			options := util.StringToBlock(fmt.Sprintf("I don't know how to do that. Did you mean %s? Ask me for `help` to see everything I can do.", strings.Join(suggestions, ", ")), false)
+			if len(msg.ThreadTimeStamp) > 0 {
+				options = append(options, slack.MsgOptionTS(msg.ThreadTimeStamp))
+			}
+			_, err := client.PostEphemeral(msg.Channel, msg.User, options...)
+			if err != nil {
+				return fmt.Errorf("failed responding to message: %v", err)
+			}
+			return nil
+		}
+	}
+
+	// if the message isn't handled, check to see if this is an IM message
+	// and the user is allowed.
+	if !responded && enableChatResponse {
+		ieData := msg
+		channelType := ieData.ChannelType
+		if channelType == slack.TYPE_IM && !util.ContainsBotMention(msg.Text) && (len(msg.BotID) == 0 || util.IsSPLATBotID(msg.BotID)) {
+			response, err := chat.HandleChatInteraction(ctx, client, msg)
+			if err != nil {
+				log.Warnf("failed processing message: %v", err)
+			}
+			if len(response) > 0 {
+				_, _, err = client.PostMessage(msg.Channel, response...)
+				if err != nil {
+					log.Warnf("failed posting message: %v", err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// candidate is an attribute which matched a message along with the arguments it matched with
+type candidate struct {
+	attribute data.Attributes
+	args      []string
+}
+
+// matchAttributes returns every attribute which is interested in the message, ordered from the best to the worst
+// match. Attributes with a higher Rank are preferred. When ranks are equal, attributes with longer Commands are
+// more specific and are preferred. Otherwise, the order in which the attributes were added is retained.
+func matchAttributes(msg *slackevents.MessageEvent, isAppMentionEvent bool) []candidate {
+	var candidates []candidate
 	for _, attribute := range getAttributes() {
 		log.Debugf("checking command: %v", attribute.Commands)
 
@@ -254,8 +460,7 @@ func Handler(ctx context.Context, client util.SlackClientInterface, evt slackeve
 				log.Warnf("command requires a mention: %s", msg.Text)
 				continue
 			} else if !isAppMentionEvent {
-				ieData := evt.InnerEvent.Data.(*slackevents.MessageEvent)
-				channelType := ieData.ChannelType
+				channelType := msg.ChannelType
 
 				if !util.ContainsBotMention(msg.Text) && channelType == slack.TYPE_CHANNEL {
 					log.Warnf("message is targeting a %s and doesnt contain a bot mention: %s", channelType, msg.Text)
@@ -281,99 +486,228 @@ func Handler(ctx context.Context, client util.SlackClientInterface, evt slackeve
 			}
 		}
 
-		args = tokenize(msg.Text, !attribute.DontGlobQuotes)
+		args := tokenize(msg.Text, !attribute.DontGlobQuotes)
 		if util.ContainsBotMention(msg.Text) {
 			args = args[1:]
 		}
-		log.Warnf("msg: %v\n", msg)
-		if len(msg.Type) > 0 && checkForCommand(args, attribute, msg.Channel) {
-			log.Debugf("found command: %v", attribute.Commands)
-			// Now that we found command, make sure it can be used by current user.
-			if !attribute.AllowNonSplatUsers {
-				err := isAllowedUser(msg)
-				if err != nil {
-					return fmt.Errorf("user not allowed: %v", err)
-				}
-			}
+		messageOfInterest := attribute.MessageOfInterest
+		if messageOfInterest == nil {
+			messageOfInterest = checkForCommand
+		}
+		if len(msg.Type) == 0 || !messageOfInterest(args, attribute, msg.Channel) {
+			continue
+		}
 
-			var err error
-			inThread := len(util.GetThreadUrl(msg)) > 0
-			if attribute.MustBeInThread && !inThread {
-				log.Warnf("message must be in a thread, but isnt: %s", msg.Text)
-				continue
-			}
+		inThread := len(util.GetThreadUrl(msg)) > 0
+		if attribute.MustBeInThread && !inThread {
+			log.Warnf("message must be in a thread, but isnt: %s", msg.Text)
+			continue
+		}
 
-			maxExceeded := false
-			if attribute.MaxArgs > 0 && len(args) > attribute.MaxArgs {
-				maxExceeded = true
-			}
-			minRequired := attribute.RequiredArgs > 0 && len(args) < attribute.RequiredArgs
+		log.Debugf("found command: %v", attribute.Commands)
+		candidates = append(candidates, candidate{attribute: attribute, args: args})
+	}
 
-			if len(args) < attribute.RequiredArgs {
-				response = []slack.MsgOption{
-					slack.MsgOptionText(fmt.Sprintf("command requires %d arguments.\n%s\n", attribute.RequiredArgs, attribute.HelpMarkdown), true),
-				}
-			} else if minRequired || maxExceeded {
-				response = []slack.MsgOption{
-					slack.MsgOptionText(fmt.Sprintf("command requires %d arguments. if an argument is greater than one word, be sure to wrap that argument in quotes.\n%s\n", attribute.RequiredArgs, attribute.HelpMarkdown), true),
-				}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		left, right := candidates[i].attribute, candidates[j].attribute
+		if left.Rank != right.Rank {
+			return left.Rank > right.Rank
+		}
+		return len(left.Commands) > len(right.Commands)
+	})
+	return candidates
+}
+
+// hasCommandMatch returns true if any of the candidates matched on Commands rather than being a catch-all
+func hasCommandMatch(candidates []candidate) bool {
+	for _, candidate := range candidates {
+		if len(candidate.attribute.Commands) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// runAttribute invokes the attribute's callback and posts the response. true is returned if a response was posted.
+func runAttribute(ctx context.Context, client util.SlackClientInterface, msg *slackevents.MessageEvent, attribute data.Attributes, args []string) (bool, error) {
+	response, err := invokeAttribute(ctx, client, msg, attribute, args)
+	if err != nil {
+		return false, err
+	}
+	if len(response) == 0 {
+		log.Debugf("finished processing command")
+		return false, nil
+	}
+
+	log.Debugf("responding to message: %v", response)
+	channel := msg.Channel
+	if attribute.RespondInDM {
+		channel, err = getDMChannelID(client, msg)
+		if err != nil {
+			log.Warnf("failed getting channel ID: %v", err)
+		}
+	} else if attribute.Wizard != nil && len(msg.ThreadTimeStamp) > 0 {
+		// the wizard is tied to the thread the command was sent in
+		response = append(response, slack.MsgOptionTS(msg.ThreadTimeStamp))
+	} else if !attribute.RespondInChannel {
+		response = append(response, slack.MsgOptionTS(msg.TimeStamp))
+	} else if len(util.GetThreadUrl(msg)) > 0 {
+		response = append(response, slack.MsgOptionTS(msg.ThreadTimeStamp))
+	}
+
+	log.Debugf("responding to message in channel: %s", channel)
+	if attribute.ResponseIsEphemeral {
+		_, err = client.PostEphemeral(channel, msg.User, response...)
+	} else {
+		_, _, err = client.PostMessage(channel, response...)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed responding to message: %v", err)
+	}
+	return true, nil
+}
+
+// invokeAttribute authorizes the user, validates the arguments and invokes the attribute's callback. The response
+// is returned rather than posted. An error is only returned if the user was not allowed to run the command.
+func invokeAttribute(ctx context.Context, client util.SlackClientInterface, msg *slackevents.MessageEvent, attribute data.Attributes, args []string) ([]slack.MsgOption, error) {
+	return invokeAttributeFrom(ctx, auditSourceMessage, client, msg, attribute, args)
+}
+
+// invokeAttributeFrom invokes the attribute on behalf of msg.User. source describes how the command was invoked and is
+// recorded in the audit log.
+func invokeAttributeFrom(ctx context.Context, source string, client util.SlackClientInterface, msg *slackevents.MessageEvent, attribute data.Attributes, args []string) ([]slack.MsgOption, error) {
+	// Now that we found command, make sure it can be used by current user.
+	var command []string
+	var record *audit.Record
+	if len(attribute.Commands) > 0 {
+		command = args
+		// catch-all attributes, such as knowledge, respond to ordinary conversation and are not audited
+		record = newAuditRecord(source, msg.User, msg.Channel, attribute.Commands, args)
+		ctx = audit.NewContext(ctx, record)
+	}
+	err := authorizeAttribute(client, msg.User, msg.Channel, command, attribute)
+	if err != nil {
+		finishAuditRecord(record, audit.OutcomeDenied, err)
+		return nil, denyUser(client, msg.User, msg.Channel, err)
+	}
+
+	outcome := audit.OutcomeSuccess
+	var response []slack.MsgOption
+	maxExceeded := false
+	if attribute.MaxArgs > 0 && len(args) > attribute.MaxArgs {
+		maxExceeded = true
+	}
+	minRequired := attribute.RequiredArgs > 0 && len(args) < attribute.RequiredArgs
+
+	if len(attribute.Params) > 0 {
+		// the schema supersedes RequiredArgs and MaxArgs
+		params := attribute.Params
+		if attribute.Wizard != nil {
+			params = wizardParams(params)
+		}
+		var parsed *data.ParsedArgs
+		parsed, err = parseParams(params, args[min(len(attribute.Commands), len(args)):], args)
+		if err != nil {
+			outcome = audit.OutcomeInvalid
+			response = util.StringToBlock(usageError(err, attribute.Commands, attribute.Params, attribute.HelpMarkdown), false)
+		} else {
+			if record != nil {
+				record.Params = parsed.Values()
+			}
+			if attribute.Wizard != nil {
+				response = wizards.start(ctx, client, msg, attribute, parsed)
+			} else if attribute.ParsedCallback != nil {
+				response, err = attribute.ParsedCallback(ctx, client, msg, parsed)
 			} else {
 				response, err = attribute.Callback(ctx, client, msg, args)
-				if err != nil {
-					log.Warnf("failed processing message: %v, %v", err, response)
-				}
 			}
-			if len(response) > 0 {
-				log.Debugf("responding to message: %v", response)
-				if attribute.RespondInDM {
-					channelID, err := getDMChannelID(client, msg)
-					if err != nil {
-						log.Warnf("failed getting channel ID: %v", err)
-					}
-					msg.Channel = channelID
-				} else if !attribute.RespondInChannel {
-					response = append(response, slack.MsgOptionTS(msg.TimeStamp))
-				} else if len(util.GetThreadUrl(msg)) > 0 {
-					response = append(response, slack.MsgOptionTS(msg.ThreadTimeStamp))
-				}
-
-				log.Debugf("responding to message in channel: %s", msg.Channel)
-				if attribute.ResponseIsEphemeral {
-					_, err = client.PostEphemeral(msg.Channel, msg.User, response...)
-				} else {
-					_, _, err = client.PostMessage(msg.Channel, response...)
-				}
-				if err != nil {
-					return fmt.Errorf("failed responding to message: %v", err)
-				}
-				return nil
+			if err != nil {
+				outcome = audit.OutcomeError
 			}
-			log.Debugf("finished processing command")
+		}
+		if err != nil {
+			log.Warnf("failed processing message: %v, %v", err, response)
+		}
+	} else if len(args) < attribute.RequiredArgs {
+		outcome = audit.OutcomeInvalid
+		response = []slack.MsgOption{
+			slack.MsgOptionText(fmt.Sprintf("command requires %d arguments.\n%s\n", attribute.RequiredArgs, attribute.HelpMarkdown), true),
+		}
+	} else if minRequired || maxExceeded {
+		outcome = audit.OutcomeInvalid
+		response = []slack.MsgOption{
+			slack.MsgOptionText(fmt.Sprintf("command requires %d arguments. if an argument is greater than one word, be sure to wrap that argument in quotes.\n%s\n", attribute.RequiredArgs, attribute.HelpMarkdown), true),
+		}
+	} else if attribute.Wizard != nil {
+		response = wizards.start(ctx, client, msg, attribute, data.NewParsedArgs(args))
+	} else {
+		response, err = attribute.Callback(ctx, client, msg, args)
+		if err != nil {
+			outcome = audit.OutcomeError
+			log.Warnf("failed processing message: %v, %v", err, response)
 		}
 	}
+	finishAuditRecord(record, outcome, err)
+	return response, nil
+}
 
-	// if the message isn't handled, check to see if this is an IM message
-	// and the user is allowed.
-	if len(response) == 0 && enableChatResponse {
-		ieData := msg
-		channelType := ieData.ChannelType
-		if channelType == slack.TYPE_IM && !util.ContainsBotMention(msg.Text) && (len(msg.BotID) == 0 || util.IsSPLATBotID(msg.BotID)) {
-			response, err := chat.HandleChatInteraction(ctx, client, msg)
-			if err != nil {
-				log.Warnf("failed processing message: %v", err)
-			}
-			if len(response) > 0 {
-				_, _, err = client.PostMessage(msg.Channel, response...)
-				if err != nil {
-					log.Warnf("failed posting message: %v", err)
-				}
-			}
+// suggestCommands returns the registered commands which are closest to args
+func suggestCommands(args []string) []string {
+	const (
+		maxSuggestions      = 3
+		minSuggestionLength = 4
+	)
+
+	type suggestion struct {
+		command  string
+		distance int
+	}
+	var suggestions []suggestion
+	seen := map[string]bool{}
+	for _, attribute := range getAttributes() {
+		if len(attribute.Commands) == 0 {
+			continue
+		}
+		command := strings.Join(attribute.Commands, " ")
+		if seen[command] {
+			continue
+		}
+		seen[command] = true
+
+		length := len(attribute.Commands)
+		if length > len(args) {
+			length = len(args)
+		}
+		attempted := strings.Join(args[:length], " ")
+		// short commands such as ci are too close to ordinary words like hi to suggest them
+		if len(command) < minSuggestionLength {
+			continue
 		}
+		distance := util.EditDistance(strings.ToLower(attempted), command)
+		// allow roughly one mistake for every three characters
+		if distance > len(command)/3 {
+			continue
+		}
+		suggestions = append(suggestions, suggestion{command: command, distance: distance})
 	}
-	return nil
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+	var commands []string
+	for idx, suggestion := range suggestions {
+		if idx == maxSuggestions {
+			break
+		}
+		commands = append(commands, fmt.Sprintf("`%s`", suggestion.command))
+	}
+	return commands
 }
 
 func checkForCommand(args []string, attribute data.Attributes, channel string) bool {
+	if len(args) < len(attribute.Commands) {
+		return false
+	}
 	match := true
 	for index, command := range attribute.Commands {
 		if command != args[index] {
@@ -384,6 +718,15 @@ func checkForCommand(args []string, attribute data.Attributes, channel string) b
 	return match
 }
 
+// slashCommandTokens returns the command tokens a slash command is authorized as. These are its AuthorizeAs tokens,
+// or the slash command itself when AuthorizeAs isn't set.
+func slashCommandTokens(command data.SlashCommand) []string {
+	if len(command.AuthorizeAs) > 0 {
+		return command.AuthorizeAs
+	}
+	return command.Commands
+}
+
 func checkForSlashCommand(cmdVal string, command data.SlashCommand) bool {
 	match := true
 	for _, curCommand := range command.Commands {
@@ -64,94 +64,174 @@ func buildEvent(text, user, channel string, inThread bool) slackevents.EventsAPI
 const (
 	SPLAT_BOT_USER_ID   = "testbot"
 	SLACK_ALLOWED_USERS = "alloweduser1"
-	POST_MESSAGE        = "failed responding to message: PostMessage"
-	POST_EPHEMERAL      = "failed responding to message: PostEphemeral"
 )
 
-func checkResponse(attribute data.Attributes, err error) error {
-	if attribute.ResponseIsEphemeral {
-		if err.Error() != POST_EPHEMERAL {
-			return fmt.Errorf("expected ephemeral response when mentioning bot")
+// checkResponse checks that the command posted a response. Commands which are admin only must have denied
+// the test user.
+func checkResponse(attribute data.Attributes, client *util.FakeClient, err error) error {
+	message, posted := client.LastMessage()
+	if attribute.AdminOnly {
+		// the test user is not an admin
+		if err == nil || !strings.HasPrefix(err.Error(), "user not allowed") {
+			return fmt.Errorf("expected non-admins to be denied: %v", err)
 		}
-	} else if err.Error() != POST_MESSAGE {
-		return fmt.Errorf("expected response when mentioning bot")
+		if !posted || !message.Ephemeral || !strings.Contains(message.Text, "not allowed") {
+			return fmt.Errorf("expected the user to be told they were denied, got %+v", message)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("expected no error when mentioning bot: %v", err)
+	}
+	if !posted {
+		return fmt.Errorf("expected a response to %v", attribute.Commands)
+	}
+	if message.Ephemeral != attribute.ResponseIsEphemeral {
+		return fmt.Errorf("expected ephemeral to be %t when mentioning bot", attribute.ResponseIsEphemeral)
+	}
+	if attribute.RespondInDM && !strings.HasPrefix(message.Channel, "D") {
+		return fmt.Errorf("expected a response in a DM, got channel %s", message.Channel)
+	}
+	if len(strings.TrimSpace(message.Render())) == 0 {
+		return fmt.Errorf("expected the response to have content")
 	}
 	return nil
 }
 
-func checkRequireChannel(tokens []string, client util.SlackClientInterface, attribute data.Attributes) error {
+func checkRequireChannel(tokens []string, client *util.FakeClient, attribute data.Attributes) error {
 	ctx := context.TODO()
 	msg := strings.Join(tokens, " ")
 	for _, channel := range attribute.RequireInChannel {
-		if err := Handler(ctx, client, buildEvent(msg, "test", channel, false)); err != nil {
-			err = checkResponse(attribute, err)
-			if err != nil {
-				return err
-			}
+		client.Reset()
+		err := Handler(ctx, client, buildEvent(msg, "test", channel, false))
+		if err := checkResponse(attribute, client, err); err != nil {
+			return err
+		}
 
+		client.Reset()
+		if err := Handler(ctx, client, buildEvent(msg, "test", "testchannel", true)); err != nil {
+			return err
 		}
-		if err := Handler(ctx, client, buildEvent(msg, "test", "testchannel", true)); err == nil {
-			err = checkResponse(attribute, err)
-			if err != nil {
-				return err
-			}
+		if len(client.Messages()) > 0 {
+			return fmt.Errorf("expected no response outside of %s", channel)
 		}
 	}
 	return nil
 
 }
 
-func checkRequireMention(tokens []string, client util.SlackClientInterface, attribute data.Attributes) error {
+func checkRequireMention(tokens []string, client *util.FakeClient, attribute data.Attributes) error {
 	ctx := context.TODO()
 	msg := strings.Join(tokens, " ")
 	if !attribute.RequireMention {
 		return nil
 	}
-	if err := Handler(ctx, client, buildAppMentionEvent(msg, "test", "testchannel", false)); err != nil {
+	client.Reset()
+	if err := Handler(ctx, client, buildAppMentionEvent(msg, "test", "testchannel", false)); err != nil || len(client.Messages()) > 0 {
 		return fmt.Errorf("expected no response when not mentioning bot: %v", err)
 	}
 	msg = fmt.Sprintf("<@%s> %s", SPLAT_BOT_USER_ID, msg)
-	if err := Handler(ctx, client, buildAppMentionEvent(msg, "test", "testchannel", false)); err != nil {
-		err = checkResponse(attribute, err)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	client.Reset()
+	err := Handler(ctx, client, buildAppMentionEvent(msg, "test", "testchannel", false))
+	return checkResponse(attribute, client, err)
 }
 
-func checkNotRequireMention(tokens []string, client util.SlackClientInterface, attribute data.Attributes) error {
+func checkNotRequireMention(tokens []string, client *util.FakeClient, attribute data.Attributes) error {
 	ctx := context.TODO()
 	msg := strings.Join(tokens, " ")
 	if attribute.RequireMention {
 		return nil
 	}
-	if err := Handler(ctx, client, buildEvent(msg, "test", "testchannel", false)); err != nil {
-		err = checkResponse(attribute, err)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	client.Reset()
+	err := Handler(ctx, client, buildEvent(msg, "test", "testchannel", false))
+	return checkResponse(attribute, client, err)
 }
 
 func TestHandler(t *testing.T) {
-	mockClient := &util.StubInterface{}
+	client := util.NewFakeClient()
 	os.Setenv("SPLAT_BOT_USER_ID", SPLAT_BOT_USER_ID)
 	os.Setenv("SLACK_ALLOWED_USERS", SLACK_ALLOWED_USERS)
 	for _, attribute := range attributes {
-		if err := checkRequireMention(attribute.Commands, mockClient, attribute); err != nil {
+		if err := checkRequireMention(attribute.Commands, client, attribute); err != nil {
 			t.Errorf("test failed for %v: %v", attribute.Commands, err)
 		}
-		if err := checkNotRequireMention(attribute.Commands, mockClient, attribute); err != nil {
+		if err := checkNotRequireMention(attribute.Commands, client, attribute); err != nil {
 			t.Errorf("test failed for %v: %v", attribute.Commands, err)
 		}
-		if err := checkRequireChannel(attribute.Commands, mockClient, attribute); err != nil {
+		if err := checkRequireChannel(attribute.Commands, client, attribute); err != nil {
 			t.Errorf("test failed for %v: %v", attribute.Commands, err)
 		}
 	}
 }
 
+func TestHandlerResponses(t *testing.T) {
+	os.Setenv("SPLAT_BOT_USER_ID", SPLAT_BOT_USER_ID)
+
+	cases := []struct {
+		name      string
+		message   string
+		ephemeral bool
+		contains  []string
+	}{
+		{
+			name:      "help lists commands",
+			message:   "help",
+			ephemeral: true,
+			contains:  []string{"*CI*", "ci pools cordon", "*Jira*", "jira create", "/jira-create", "_(mention the bot, SPLAT members only)_"},
+		},
+		{
+			name:      "help shows the details of a command",
+			message:   "help ci lease acquire",
+			ephemeral: true,
+			contains:  []string{"*ci lease acquire* (CI)", "usage: `ci lease acquire [cpus=<1-128>]", "`cpus=` vCPUs to lease. 1-128. default 24", "`@splat-bot ci lease acquire`", "you may run this command"},
+		},
+		{
+			name:      "help suggests commands",
+			message:   "help ci leese",
+			ephemeral: true,
+			contains:  []string{"I don't know the command `ci leese`. Did you mean `ci lease`?"},
+		},
+		{
+			name:     "missing argument shows usage",
+			message:  "ci pools cordon",
+			contains: []string{"missing required argument pool", "usage: `ci pools cordon <pool>`"},
+		},
+		{
+			name:     "invalid argument shows usage",
+			message:  "ci lease acquire cpus=1000",
+			contains: []string{"cpus must be between 1 and 128, got 1000"},
+		},
+		{
+			name:      "misspelled command suggests the closest",
+			message:   "ci leas list",
+			ephemeral: true,
+			contains:  []string{"Did you mean `ci lease`"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := util.NewFakeClient()
+			msg := fmt.Sprintf("<@%s> %s", SPLAT_BOT_USER_ID, tc.message)
+			if err := Handler(context.TODO(), client, buildAppMentionEvent(msg, "test", "testchannel", false)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			message, posted := client.LastMessage()
+			if !posted {
+				t.Fatalf("expected a response")
+			}
+			if message.Ephemeral != tc.ephemeral {
+				t.Errorf("expected ephemeral to be %t", tc.ephemeral)
+			}
+			rendered := message.Render()
+			for _, expected := range tc.contains {
+				if !strings.Contains(rendered, expected) {
+					t.Errorf("expected the response to contain %q, got:\n%s", expected, rendered)
+				}
+			}
+		})
+	}
+}
+
 func TestCommands(t *testing.T) {
 	attributes := getAttributes()
 
@@ -187,3 +267,81 @@ func TestCommands(t *testing.T) {
 		}
 	})
 }
+
+// withAttributes replaces the registered attributes for the duration of a test
+func withAttributes(t *testing.T, testAttributes ...data.Attributes) {
+	attributeMu.Lock()
+	saved := attributes
+	attributes = []data.Attributes{}
+	attributeMu.Unlock()
+
+	for _, attribute := range testAttributes {
+		AddCommand(attribute)
+	}
+	t.Cleanup(func() {
+		attributeMu.Lock()
+		attributes = saved
+		attributeMu.Unlock()
+	})
+}
+
+func TestMatchAttributesRanking(t *testing.T) {
+	os.Setenv("SPLAT_BOT_USER_ID", SPLAT_BOT_USER_ID)
+	withAttributes(t,
+		data.Attributes{
+			HelpMarkdown:      "catch-all",
+			MessageOfInterest: func(args []string, attribute data.Attributes, channel string) bool { return true },
+		},
+		data.Attributes{Commands: []string{"ci"}, HelpMarkdown: "ci"},
+		data.Attributes{Commands: []string{"ci", "lease"}, HelpMarkdown: "ci lease", RequireMention: true},
+		data.Attributes{Commands: []string{"ci", "pools"}, HelpMarkdown: "ci pools"},
+	)
+
+	msg := &slackevents.MessageEvent{
+		Type:    "message",
+		Text:    fmt.Sprintf("<@%s> ci lease list", SPLAT_BOT_USER_ID),
+		Channel: "testchannel",
+	}
+	var order []string
+	for _, candidate := range matchAttributes(msg, true) {
+		order = append(order, candidate.attribute.HelpMarkdown)
+	}
+	expected := []string{"ci lease", "ci", "catch-all"}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected candidates %v, got %v", expected, order)
+	}
+
+	withAttributes(t,
+		data.Attributes{Commands: []string{"ci", "lease"}, HelpMarkdown: "ci lease"},
+		data.Attributes{Commands: []string{"ci"}, HelpMarkdown: "ranked", Rank: 1},
+	)
+	candidates := matchAttributes(msg, true)
+	if len(candidates) != 2 || candidates[0].attribute.HelpMarkdown != "ranked" {
+		t.Fatalf("expected the attribute with the highest rank to be preferred")
+	}
+}
+
+func TestSuggestCommands(t *testing.T) {
+	cases := []struct {
+		message  string
+		expected string
+	}{
+		{message: "ci leas list", expected: "`ci lease`"},
+		{message: "jira craete something", expected: "`jira create`"},
+		{message: "prow grph vsphere", expected: "`prow graph`"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.message, func(t *testing.T) {
+			suggestions := suggestCommands(strings.Split(tc.message, " "))
+			if len(suggestions) == 0 || suggestions[0] != tc.expected {
+				t.Fatalf("expected %s to be suggested first, got %v", tc.expected, suggestions)
+			}
+		})
+	}
+
+	for _, message := range []string{"thanks so much", "hi", "hi there"} {
+		if suggestions := suggestCommands(strings.Split(message, " ")); len(suggestions) > 0 {
+			t.Fatalf("expected no suggestions for %s, got %v", message, suggestions)
+		}
+	}
+}
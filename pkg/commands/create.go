@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"text/template"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/openshift-splat-team/jira-bot/cmd/issue"
 	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
@@ -50,6 +52,11 @@ issue created by splat-bot
 `
 
 	CreateJiraDialogCommand = "Jira_Create_Dialog_Input"
+
+	// CreateJiraFromMessageCallbackID is the callback ID of the message shortcut which creates an issue from a message
+	CreateJiraFromMessageCallbackID = "create_jira_from_message"
+	// CreateJiraCallbackID is the callback ID of the global shortcut which creates an issue
+	CreateJiraCallbackID = "create_jira"
 )
 
 // This variable is used to prevent sending JIRA requests when developing
@@ -101,13 +108,21 @@ func invokeTemplate(t *template.Template, data any) (string, error) {
 var CreateAttributes = data.Attributes{
 	Commands:       []string{"jira", "create"},
 	RequireMention: true,
-	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
-		// If only two args are passed in, we'll generate a dialog to collect the data we need, else we will use the
-		// provided info to generate the Jira card.
+	Params: []data.Param{
+		{
+			Name:        "summary",
+			Required:    true,
+			Description: "summary of the issue. wrap the summary in quotes if it is more than one word.",
+		},
+		{
+			Name:        "outcome",
+			Description: "the desired outcome of the issue",
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
 		return createJira(ctx, evt, args)
 	},
-	RequiredArgs: 2,
-	MaxArgs:      4,
+	Category:     data.CategoryJira,
 	HelpMarkdown: "create a Jira issue: `jira create \"[description]\"`",
 	ShouldMatch: []string{
 		"jira create description",
@@ -119,20 +134,50 @@ var CreateAttributes = data.Attributes{
 	},
 }
 
+var JiraCreateFromMessageShortcut = data.Shortcut{
+	CallbackID: CreateJiraFromMessageCallbackID,
+	Name:       "Create Jira from message",
+	Commands:   []string{"jira", "create"},
+	Callback: func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback) ([]slack.MsgOption, error) {
+		values := jiraDialogValues{}
+		if len(callback.Message.Text) > 0 {
+			title, _, _ := strings.Cut(callback.Message.Text, "\n")
+			values.Title = title
+			values.OtherInfo = fmt.Sprintf("created from message: %s\n\n%s", messagePermalink(client, callback), callback.Message.Text)
+		}
+		return openJiraDialog(ctx, client, callback.TriggerID, callback.Channel.ID, values)
+	},
+	Category:     data.CategoryJira,
+	HelpMarkdown: "create a Jira issue prefilled from a message: choose *Create Jira from message* from the message's menu",
+}
+
+var JiraCreateShortcut = data.Shortcut{
+	CallbackID: CreateJiraCallbackID,
+	Name:       "Create Jira",
+	Commands:   []string{"jira", "create"},
+	Callback: func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback) ([]slack.MsgOption, error) {
+		return openJiraDialog(ctx, client, callback.TriggerID, callback.Channel.ID, jiraDialogValues{})
+	},
+	Category:     data.CategoryJira,
+	HelpMarkdown: "create a Jira issue from anywhere: choose *Create Jira* from the shortcuts menu",
+}
+
 var JiraCreateSlashCommand = data.SlashCommand{
-	Commands: []string{"/jira-create"},
+	Commands:    []string{"/jira-create"},
+	AuthorizeAs: []string{"jira", "create"},
 	ProcessCommand: func(ctx context.Context, client util.SlackClientInterface, command slack.SlashCommand, args []string) ([]slack.MsgOption, error) {
 		return createJiraDialog(ctx, client, command)
 
 	},
 	RequiredArgs:         2,
 	MaxArgs:              4,
-	HelpMarkdown:         "create a Jira issue: `jira create \"[description]\"`",
+	Category:             data.CategoryJira,
+	HelpMarkdown:         "create a Jira issue in a dialog: `/jira-create`",
 	ViewSubmissionCheck:  CanHandleViewSubmission,
 	HandleViewSubmission: HandleViewSubmission,
 }
 
-func createJira(ctx context.Context, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+func createJira(ctx context.Context, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
 	var description, issueKey, issueURL string
 	var err error
 
@@ -145,12 +190,12 @@ func createJira(ctx context.Context, evt *slackevents.MessageEvent, args []strin
 	}
 
 	url := util.GetThreadUrl(evt)
-	log.Debugf("%v", args)
-	summary := args[2]
+	log.Debugf("%v", args.Raw)
+	summary := args.String("summary")
 
-	if len(args) >= 4 {
-		assistantCtx.Goal = args[2]
-		assistantCtx.Outcome = args[3]
+	if args.Has("outcome") {
+		assistantCtx.Goal = summary
+		assistantCtx.Outcome = args.String("outcome")
 	}
 
 	// Execute the template and write the result into the buffer
@@ -173,7 +218,7 @@ func createJira(ctx context.Context, evt *slackevents.MessageEvent, args []strin
 		}
 		issueKey = issue.Key
 		issueURL = fmt.Sprintf("%s/browse/%s", JIRA_BASE_URL, issueKey)
-
+		audit.Touched(ctx, "create", "Issue", "SPLAT", issueKey)
 	}
 	return util.StringToBlock(fmt.Sprintf("issue <%s|%s> created", issueURL, issueKey), false), nil
 }
@@ -195,6 +240,7 @@ func generateJira(ctx context.Context, data slack.InteractionCallback, summary,
 		}
 		issueKey = jiraIssue.Key
 		issueURL = fmt.Sprintf("%s/browse/%s", JIRA_BASE_URL, issueKey)
+		audit.Touched(ctx, "create", "Issue", "SPLAT", issueKey)
 
 		// TODO - for now, stubbing in AI usage.  Ideally, I think I'd have a button in the dialog that can be used to trigger this
 		//        separately so that the fields are populated.  Each field has a limit of 3000 characters currently.
@@ -208,13 +254,24 @@ func generateJira(ctx context.Context, data slack.InteractionCallback, summary,
 
 func createJiraDialog(ctx context.Context, client util.SlackClientInterface, command slack.SlashCommand) ([]slack.MsgOption, error) {
 	log.Printf("Processing slash command: %v", command.Command)
+	return openJiraDialog(ctx, client, command.TriggerID, command.ChannelID, jiraDialogValues{})
+}
+
+// jiraDialogValues are used to prefill the Jira create dialog
+type jiraDialogValues struct {
+	Title     string
+	OtherInfo string
+}
+
+// openJiraDialog opens the Jira create dialog. Once submitted, the issue is reported in channelID or, when
+// channelID is empty, in a DM with the user.
+func openJiraDialog(ctx context.Context, client util.SlackClientInterface, triggerId, channelID string, values jiraDialogValues) ([]slack.MsgOption, error) {
 	var messageBlocks []slack.Block
 
 	view := slack.ModalViewRequest{}
 	//response := util.StringToBlock(fmt.Sprintf("Manta's brain is hard at work generating the dialog"), false)
 
 	log.Printf("Attempting to creating dialog for jira create.")
-	triggerId := command.TriggerID
 
 	view.Type = slack.VTModal
 	view.CallbackID = CreateJiraDialogCommand
@@ -225,26 +282,26 @@ func createJiraDialog(ctx context.Context, client util.SlackClientInterface, com
 	view.Close = slack.NewTextBlockObject("plain_text", "Close", false, false)
 
 	// Generate Header (title)
-	messageBlocks = append(messageBlocks, createTextInputField("title", "Title", "", "", 0, false, false))
+	messageBlocks = append(messageBlocks, createTextInputField("title", "Title", "", "", util.Truncate(values.Title, 80), 0, false, false))
 
 	// Generate User Story
-	messageBlocks = append(messageBlocks, createTextInputField("userStory", "User Story", "As a ____ I want to ____ so that ____", "", 3000, true, false))
+	messageBlocks = append(messageBlocks, createTextInputField("userStory", "User Story", "As a ____ I want to ____ so that ____", "", "", 3000, true, false))
 
 	// Generate Description
-	messageBlocks = append(messageBlocks, createTextInputField("description", "Description", "A single sentence describing what this story needs to accomplish", "", 3000, true, true))
+	messageBlocks = append(messageBlocks, createTextInputField("description", "Description", "A single sentence describing what this story needs to accomplish", "", "", 3000, true, true))
 
 	// Generate Acceptance Criteria
-	messageBlocks = append(messageBlocks, createTextInputField("acceptanceCriteria", "AcceptanceCriteria", "Describe in detail what all needs to be done including acceptance criteria and definition of done, done, done.", "", 3000, true, true))
+	messageBlocks = append(messageBlocks, createTextInputField("acceptanceCriteria", "AcceptanceCriteria", "Describe in detail what all needs to be done including acceptance criteria and definition of done, done, done.", "", "", 3000, true, true))
 
 	// Generate Other Information
-	messageBlocks = append(messageBlocks, createTextInputField("otherInfo", "Other Information", "Record anything else that may be helpful to someone else picking up the card.", "", 3000, true, true))
+	messageBlocks = append(messageBlocks, createTextInputField("otherInfo", "Other Information", "Record anything else that may be helpful to someone else picking up the card.", "", util.Truncate(values.OtherInfo, 3000), 3000, true, true))
 
 	view.Blocks = slack.Blocks{
 		BlockSet: messageBlocks,
 	}
 
 	// We set channel id of where request came from to log the new jira that was created.
-	view.PrivateMetadata = command.ChannelID
+	view.PrivateMetadata = channelID
 
 	// Create request and set the view
 	log.Println("Attempting to open dialog")
@@ -259,7 +316,7 @@ func createJiraDialog(ctx context.Context, client util.SlackClientInterface, com
 	}
 }
 
-func createTextInputField(fieldId, fieldLabel, fieldPlaceholderText, fieldHintText string, maxLength int, multiline, isOptional bool) slack.Block {
+func createTextInputField(fieldId, fieldLabel, fieldPlaceholderText, fieldHintText, initialValue string, maxLength int, multiline, isOptional bool) slack.Block {
 	var channelNameText, channelNameHint, channelPlaceholder *slack.TextBlockObject
 
 	channelNameText = slack.NewTextBlockObject(slack.PlainTextType, fmt.Sprintf("%v:", fieldLabel), false, false)
@@ -280,6 +337,7 @@ func createTextInputField(fieldId, fieldLabel, fieldPlaceholderText, fieldHintTe
 	}
 
 	channelNameElement.Multiline = multiline
+	channelNameElement.InitialValue = initialValue
 	channelNameBlock := slack.NewInputBlock(fieldId, channelNameText, channelNameHint, channelNameElement)
 	channelNameBlock.Optional = isOptional
 
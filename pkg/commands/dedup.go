@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack/slackevents"
+	"k8s.io/apimachinery/pkg/util/cache"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
+)
+
+const (
+	// dedupTTL is how long an event is remembered. Slack gives up redelivering an event after about an hour.
+	dedupTTL = time.Hour
+	// dedupMaxEntries bounds the number of events which are remembered
+	dedupMaxEntries = 4096
+)
+
+var deduplicator = newEventDeduplicator(dedupMaxEntries, dedupTTL, nil)
+
+// eventDeduplicator drops events which have already been handled. Slack redelivers events which are not handled
+// in time and a message which mentions the bot is delivered as both a message event and an app_mention event.
+type eventDeduplicator struct {
+	mu      sync.Mutex
+	seen    *cache.LRUExpireCache
+	ttl     time.Duration
+	dropped atomic.Uint64
+}
+
+func newEventDeduplicator(maxEntries int, ttl time.Duration, clock cache.Clock) *eventDeduplicator {
+	seen := cache.NewLRUExpireCache(maxEntries)
+	if clock != nil {
+		seen = cache.NewLRUExpireCacheWithClock(maxEntries, clock)
+	}
+	return &eventDeduplicator{
+		seen: seen,
+		ttl:  ttl,
+	}
+}
+
+// eventKeys returns the keys which identify an event. A redelivered event has the same event ID. A message and the
+// app_mention event for the same message have different event IDs but share the channel and timestamp of the
+// message, so whichever of the pair arrives first is handled and the other is dropped.
+func eventKeys(evt slackevents.EventsAPIEvent, msg *slackevents.MessageEvent) []string {
+	var keys []string
+	if callback, ok := evt.Data.(*slackevents.EventsAPICallbackEvent); ok && len(callback.EventID) > 0 {
+		keys = append(keys, fmt.Sprintf("event/%s", callback.EventID))
+	}
+	if len(msg.Channel) > 0 && len(msg.TimeStamp) > 0 {
+		keys = append(keys, fmt.Sprintf("message/%s/%s", msg.Channel, msg.TimeStamp))
+	}
+	return keys
+}
+
+// isDuplicate records the keys of an event and returns true if any of them have already been seen.
+func (d *eventDeduplicator) isDuplicate(keys ...string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, key := range keys {
+		if _, found := d.seen.Get(key); found {
+			dropped := d.dropped.Add(1)
+			metrics.DuplicateEventsDropped.Inc()
+			log.Infof("dropping duplicate event %s. %d duplicate events dropped", key, dropped)
+			return true
+		}
+	}
+	for _, key := range keys {
+		d.seen.Add(key, struct{}{}, d.ttl)
+	}
+	return false
+}
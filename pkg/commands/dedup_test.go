@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func TestEventDeduplicator(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	dedup := newEventDeduplicator(10, time.Minute, clock)
+
+	message := &slackevents.MessageEvent{Channel: "C1", TimeStamp: "1.1"}
+	messageEvent := slackevents.EventsAPIEvent{Data: &slackevents.EventsAPICallbackEvent{EventID: "Ev1"}}
+	mentionEvent := slackevents.EventsAPIEvent{Data: &slackevents.EventsAPICallbackEvent{EventID: "Ev2"}}
+
+	if dedup.isDuplicate(eventKeys(messageEvent, message)...) {
+		t.Fatalf("expected the first delivery to be handled")
+	}
+	if !dedup.isDuplicate(eventKeys(messageEvent, message)...) {
+		t.Fatalf("expected a redelivery to be dropped")
+	}
+	if !dedup.isDuplicate(eventKeys(mentionEvent, message)...) {
+		t.Fatalf("expected the app_mention for the same message to be dropped")
+	}
+	if dedup.isDuplicate(eventKeys(slackevents.EventsAPIEvent{}, &slackevents.MessageEvent{Channel: "C1", TimeStamp: "1.2"})...) {
+		t.Fatalf("expected a different message to be handled")
+	}
+	if dropped := dedup.dropped.Load(); dropped != 2 {
+		t.Fatalf("expected 2 duplicates to be counted, got %d", dropped)
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	if dedup.isDuplicate(eventKeys(messageEvent, message)...) {
+		t.Fatalf("expected the event to be forgotten once it expires")
+	}
+}
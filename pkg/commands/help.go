@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/openshift-splat-team/splat-bot/data"
@@ -10,33 +11,267 @@ import (
 	"github.com/slack-go/slack/slackevents"
 )
 
-func compileHelp() slack.MsgOption {
-	helpText := strings.Builder{}
+// helpCategories is the order in which categories are shown in help
+var helpCategories = []data.Category{
+	data.CategoryCI,
+	data.CategoryJira,
+	data.CategoryGitHub,
+	data.CategoryKnowledge,
+	data.CategoryLLM,
+	data.CategoryGeneral,
+}
+
+func categoryOf(category data.Category) data.Category {
+	if len(category) == 0 {
+		return data.CategoryGeneral
+	}
+	return category
+}
+
+// attributeConstraints describes when the command is recognized and how it responds
+func attributeConstraints(attribute data.Attributes) []string {
+	var constraints []string
+	if attribute.RequireMention {
+		constraints = append(constraints, "mention the bot")
+	}
+	if attribute.MustBeInThread {
+		constraints = append(constraints, "only in threads")
+	}
+	if len(attribute.RequireInChannel) > 0 {
+		var channels []string
+		for _, channel := range attribute.RequireInChannel {
+			channels = append(channels, fmt.Sprintf("<#%s>", channel))
+		}
+		constraints = append(constraints, fmt.Sprintf("only in %s", strings.Join(channels, ", ")))
+	}
+	switch {
+	case attribute.AdminOnly:
+		constraints = append(constraints, "admins only")
+	case !attribute.AllowNonSplatUsers:
+		constraints = append(constraints, "SPLAT members only")
+	}
+	return constraints
+}
+
+// attributeReplies describes where the command responds
+func attributeReplies(attribute data.Attributes) string {
+	switch {
+	case attribute.Wizard != nil:
+		return "asks questions in a thread"
+	case attribute.RespondInDM:
+		return "replies in a DM"
+	case attribute.ResponseIsEphemeral:
+		return "replies only to you"
+	case attribute.RespondInChannel:
+		return "replies in the channel"
+	}
+	return "replies in a thread"
+}
+
+func slashConstraints(command data.SlashCommand) []string {
+	var constraints []string
+	if !command.AllowNonSplatUsers {
+		constraints = append(constraints, "SPLAT members only")
+	}
+	return constraints
+}
+
+func helpLine(markdown string, constraints []string) string {
+	if len(constraints) == 0 {
+		return fmt.Sprintf("- %s\n", markdown)
+	}
+	return fmt.Sprintf("- %s _(%s)_\n", markdown, strings.Join(constraints, ", "))
+}
+
+// compileHelp lists the commands, slash commands and shortcuts grouped by category
+func compileHelp() []slack.MsgOption {
+	sections := map[data.Category]*strings.Builder{}
+	section := func(category data.Category) *strings.Builder {
+		category = categoryOf(category)
+		if _, ok := sections[category]; !ok {
+			sections[category] = &strings.Builder{}
+		}
+		return sections[category]
+	}
+
 	for _, attribute := range getAttributes() {
-		if attribute.ExcludeFromHelp {
+		if attribute.ExcludeFromHelp || len(attribute.HelpMarkdown) == 0 {
+			continue
+		}
+		section(attribute.Category).WriteString(helpLine(attribute.HelpMarkdown, attributeConstraints(attribute)))
+	}
+	for _, command := range getSlashCommands() {
+		if command.ExcludeFromHelp || len(command.HelpMarkdown) == 0 {
+			continue
+		}
+		section(command.Category).WriteString(helpLine(command.HelpMarkdown, slashConstraints(command)))
+	}
+	for _, shortcut := range getShortcuts() {
+		if len(shortcut.HelpMarkdown) == 0 {
 			continue
 		}
-		helpText.WriteString("- ")
-		helpText.WriteString(attribute.HelpMarkdown)
-		helpText.WriteString("\n")
+		section(shortcut.Category).WriteString(helpLine(shortcut.HelpMarkdown, nil))
 	}
 
-	return util.StringsToBlockUnfurl([]string{helpText.String()}, false, false)[0]
+	var messages []string
+	for _, category := range helpCategories {
+		builder, ok := sections[category]
+		if !ok {
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("*%s*\n%s", category, builder.String()))
+	}
+	messages = append(messages, "ask for `help <command>` to see the details of a command, such as `help ci lease acquire`")
+	return util.StringsToBlockUnfurl(messages, false, false)
+}
+
+// findAttribute returns the attribute whose Commands are the longest prefix of args. Attributes which are excluded
+// from help are only found when includeHidden is set.
+func findAttribute(args []string, includeHidden bool) (data.Attributes, bool) {
+	var found data.Attributes
+	for _, attribute := range getAttributes() {
+		if len(attribute.Commands) == 0 || (attribute.ExcludeFromHelp && !includeHidden) || len(attribute.Commands) <= len(found.Commands) {
+			continue
+		}
+		if checkForCommand(args, attribute, "") {
+			found = attribute
+		}
+	}
+	return found, len(found.Commands) > 0
+}
+
+func findSlashCommand(name string) (data.SlashCommand, bool) {
+	name = "/" + strings.TrimPrefix(name, "/")
+	for _, command := range getSlashCommands() {
+		if !command.ExcludeFromHelp && checkForSlashCommand(name, command) {
+			return command, true
+		}
+	}
+	return data.SlashCommand{}, false
+}
+
+// attributeHelp is the detailed help of a command. user and channel are used to report whether the user may run
+// the command.
+func attributeHelp(client util.SlackClientInterface, user, channel string, attribute data.Attributes) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "*%s* (%s)\n%s\n", strings.Join(attribute.Commands, " "), categoryOf(attribute.Category), attribute.HelpMarkdown)
+	if len(attribute.Params) > 0 {
+		fmt.Fprintf(&builder, "%s\n", usage(attribute.Commands, attribute.Params))
+		builder.WriteString("\n*arguments*\n")
+		for _, param := range attribute.Params {
+			builder.WriteString(paramHelp(param))
+		}
+	}
+
+	if len(attribute.ShouldMatch) > 0 {
+		builder.WriteString("\n*examples*\n")
+		for _, example := range attribute.ShouldMatch {
+			if attribute.RequireMention {
+				example = fmt.Sprintf("@splat-bot %s", example)
+			}
+			fmt.Fprintf(&builder, "- `%s`\n", example)
+		}
+	}
+
+	builder.WriteString("\n*usage notes*\n")
+	for _, constraint := range append(attributeConstraints(attribute), attributeReplies(attribute)) {
+		fmt.Fprintf(&builder, "- %s\n", constraint)
+	}
+
+	builder.WriteString(permissionHelp(authorizeAttribute(client, user, channel, attribute.Commands, attribute)))
+	return builder.String()
+}
+
+func slashCommandHelp(client util.SlackClientInterface, user, channel string, command data.SlashCommand) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "*%s* (%s)\n%s\n", strings.Join(command.Commands, " "), categoryOf(command.Category), command.HelpMarkdown)
+	if len(command.Params) > 0 {
+		fmt.Fprintf(&builder, "%s\n", usage(command.Commands, command.Params))
+		builder.WriteString("\n*arguments*\n")
+		for _, param := range command.Params {
+			builder.WriteString(paramHelp(param))
+		}
+	}
+	if constraints := slashConstraints(command); len(constraints) > 0 {
+		builder.WriteString("\n*usage notes*\n")
+		for _, constraint := range constraints {
+			fmt.Fprintf(&builder, "- %s\n", constraint)
+		}
+	}
+	builder.WriteString(permissionHelp(authorizeUser(client, user, channel, slashCommandTokens(command), command.AllowNonSplatUsers)))
+	return builder.String()
+}
+
+func paramHelp(param data.Param) string {
+	var details []string
+	if len(param.Description) > 0 {
+		details = append(details, param.Description)
+	}
+	if placeholder := paramPlaceholder(param); placeholder != param.Name {
+		details = append(details, placeholder)
+	}
+	if param.Required {
+		details = append(details, "required")
+	} else if len(param.Default) > 0 {
+		details = append(details, fmt.Sprintf("default %s", param.Default))
+	}
+	name := param.Name
+	if param.KeyValue {
+		name += "="
+	}
+	if len(details) == 0 {
+		return fmt.Sprintf("- `%s`\n", name)
+	}
+	return fmt.Sprintf("- `%s` %s\n", name, strings.Join(details, ". "))
+}
+
+func permissionHelp(err error) string {
+	if err != nil {
+		return fmt.Sprintf("\n:no_entry: you may not run this command. %v\n", err)
+	}
+	return "\n:white_check_mark: you may run this command\n"
+}
+
+// commandHelp returns the detailed help of the command or slash command named by args
+func commandHelp(client util.SlackClientInterface, user, channel string, args []string) string {
+	if len(args) == 1 {
+		if command, ok := findSlashCommand(args[0]); ok {
+			return slashCommandHelp(client, user, channel, command)
+		}
+	}
+	if attribute, ok := findAttribute(args, false); ok {
+		return attributeHelp(client, user, channel, attribute)
+	}
+
+	response := fmt.Sprintf("I don't know the command `%s`.", strings.Join(args, " "))
+	if suggestions := suggestCommands(args); len(suggestions) > 0 {
+		response = fmt.Sprintf("%s Did you mean %s?", response, strings.Join(suggestions, ", "))
+	}
+	return fmt.Sprintf("%s Ask me for `help` to see everything I can do.", response)
 }
 
 var HelpAttributes = data.Attributes{
 	Commands:        []string{"help"},
 	RequireMention:  true,
 	ExcludeFromHelp: true,
-	Callback: func(ctx context.Context, client util.SlackClientInterface, eventsAPIEvent *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
-		return []slack.MsgOption{
-			compileHelp(),
-		}, nil
+	Params: []data.Param{
+		{
+			Name:        "command",
+			Remainder:   true,
+			Description: "command to show the details of",
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		if !args.Has("command") {
+			return compileHelp(), nil
+		}
+		return util.StringToBlock(commandHelp(client, evt.User, evt.Channel, strings.Fields(args.String("command"))), false), nil
 	},
 	ResponseIsEphemeral: true,
 	RespondInChannel:    true,
 	ShouldMatch: []string{
 		"help",
+		"help ci lease acquire",
 	},
 	ShouldntMatch: []string{
 		"jira create-with-summary PROJECT bug",
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/policy"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+func TestCompileHelp(t *testing.T) {
+	withAttributes(t,
+		data.Attributes{Commands: []string{"status"}, HelpMarkdown: "show the status"},
+		data.Attributes{Commands: []string{"ci", "thing"}, Category: data.CategoryCI, HelpMarkdown: "do a CI thing", RequireMention: true, MustBeInThread: true},
+		data.Attributes{Commands: []string{"hidden"}, HelpMarkdown: "hidden", ExcludeFromHelp: true},
+	)
+
+	help := util.RenderMsgOptions(compileHelp()...)
+	ci := strings.Index(help, "*CI*\n- do a CI thing _(mention the bot, only in threads, SPLAT members only)_")
+	general := strings.Index(help, "*General*\n- show the status _(SPLAT members only)_")
+	if ci < 0 || general < 0 {
+		t.Fatalf("expected the commands to be grouped by category, got:\n%s", help)
+	}
+	if general < ci {
+		t.Fatalf("expected CI to be listed before General, got:\n%s", help)
+	}
+	if strings.Contains(help, "hidden") {
+		t.Fatalf("expected excluded commands to be left out, got:\n%s", help)
+	}
+}
+
+func TestCommandHelp(t *testing.T) {
+	savedPolicy := authPolicy
+	authPolicy = &policy.Policy{Admins: policy.Admins{Users: []string{"admin"}}}
+	t.Cleanup(func() {
+		authPolicy = savedPolicy
+	})
+
+	withAttributes(t,
+		data.Attributes{Commands: []string{"ci", "lease"}, HelpMarkdown: "leases"},
+		data.Attributes{
+			Commands:     []string{"ci", "lease", "purge"},
+			HelpMarkdown: "purge leases",
+			AdminOnly:    true,
+			RespondInDM:  true,
+			Params:       []data.Param{{Name: "pool", Required: true, Description: "pool to purge"}},
+			ShouldMatch:  []string{"ci lease purge pool-1"},
+		},
+	)
+
+	client := util.NewFakeClient()
+	help := commandHelp(client, "user", "C1", []string{"ci", "lease", "purge", "pool-1"})
+	for _, expected := range []string{
+		"*ci lease purge* (General)",
+		"usage: `ci lease purge <pool>`",
+		"- `pool` pool to purge. required",
+		"- `ci lease purge pool-1`",
+		"- admins only\n- replies in a DM",
+		"you may not run this command. user <@user> is not an admin",
+	} {
+		if !strings.Contains(help, expected) {
+			t.Fatalf("expected the help to contain %q, got:\n%s", expected, help)
+		}
+	}
+
+	if help := commandHelp(client, "admin", "C1", []string{"ci", "lease", "purge"}); !strings.Contains(help, "you may run this command") {
+		t.Fatalf("expected admins to be allowed, got:\n%s", help)
+	}
+	if help := commandHelp(client, "user", "C1", []string{"ci", "lease"}); !strings.HasPrefix(help, "*ci lease* (General)") {
+		t.Fatalf("expected the shorter command to be found, got:\n%s", help)
+	}
+}
@@ -0,0 +1,285 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"sigs.k8s.io/prow/pkg/prstatus"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
+	"github.com/openshift-splat-team/splat-bot/pkg/controllers"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// homeMaxPullRequests bounds the pull requests listed in each section of the home tab. A view may contain at
+	// most 100 blocks.
+	homeMaxPullRequests = 10
+	// homeMaxIssues bounds the recently created Jira issues listed on the home tab
+	homeMaxIssues = 5
+	// homeRefreshDelay is how long a lease change waits before the home tab is published again, so that the changes
+	// of a reconcile, or of several leases, are published once
+	homeRefreshDelay = 5 * time.Second
+	// homePublishTimeout bounds publishing a home tab which is refreshed because a lease changed
+	homePublishTimeout = 30 * time.Second
+	// homeUserTTL is how long a home tab is refreshed after the user last opened it
+	homeUserTTL = 24 * time.Hour
+	// homeMaxUsers bounds the number of users whose home tab is refreshed
+	homeMaxUsers = 1000
+)
+
+// homeState is the home tab of a user who opened it
+type homeState struct {
+	// pullRequests the pull requests found when the user opened the home tab. They are reused when the home tab is
+	// refreshed because a lease changed, so that lease changes don't search GitHub.
+	pullRequests []slack.Block
+	// refreshing whether a refresh is scheduled
+	refreshing bool
+}
+
+var (
+	homeMu sync.Mutex
+	// homeUsers the state of the home tab of the users who have opened it recently. Their home tab is published again
+	// when their leases change.
+	homeUsers = cache.NewLRUExpireCache(homeMaxUsers)
+	// homeAfter runs a refresh of the home tab after a delay. tests replace it to refresh immediately.
+	homeAfter = func(delay time.Duration, refresh func()) {
+		time.AfterFunc(delay, refresh)
+	}
+
+	// sources of the home tab. tests replace these so that they don't need a cluster or GitHub.
+	homeLeases             = controllers.GetUserLeases
+	homePoolCapacity       = controllers.GetPoolCapacity
+	homeSearchPullRequests = searchPullRequests
+)
+
+func init() {
+	controllers.OnLeaseChange(refreshHome)
+}
+
+// refreshHome schedules the home tab of the user to be published again if they have opened it. The lease changes
+// which arrive before the refresh runs are published together. It returns immediately so that it doesn't slow down
+// the lease reconciler.
+func refreshHome(ctx context.Context, client util.SlackClientInterface, user string) {
+	homeMu.Lock()
+	defer homeMu.Unlock()
+	value, opened := homeUsers.Get(user)
+	if !opened {
+		return
+	}
+	state := value.(*homeState)
+	if state.refreshing {
+		return
+	}
+	state.refreshing = true
+	homeAfter(homeRefreshDelay, func() {
+		homeMu.Lock()
+		state.refreshing = false
+		pullRequests := state.pullRequests
+		homeMu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), homePublishTimeout)
+		defer cancel()
+		if err := publishHome(ctx, client, user, pullRequests); err != nil {
+			log.Warnf("%v", err)
+		}
+	})
+}
+
+// HomeHandler publishes the home tab of the user who opened it.
+func HomeHandler(ctx context.Context, client util.SlackClientInterface, evt *slackevents.AppHomeOpenedEvent) error {
+	if evt.Tab != "home" {
+		return nil
+	}
+	pullRequests := homePullRequestBlocks(ctx, evt.User)
+	homeMu.Lock()
+	homeUsers.Add(evt.User, &homeState{pullRequests: pullRequests}, homeUserTTL)
+	homeMu.Unlock()
+	return publishHome(ctx, client, evt.User, pullRequests)
+}
+
+func publishHome(ctx context.Context, client util.SlackClientInterface, user string, pullRequests []slack.Block) error {
+	_, err := client.PublishViewContext(ctx, user, homeView(user, pullRequests), "")
+	if err != nil {
+		return fmt.Errorf("unable to publish the home tab of %s: %v", user, err)
+	}
+	return nil
+}
+
+// homeView is the dashboard of the user: their leases, the capacity of the CI pools, their pull requests and the
+// Jira issues they recently created. pullRequests are the blocks of homePullRequestBlocks.
+func homeView(user string, pullRequests []slack.Block) slack.HomeTabViewRequest {
+	var blocks []slack.Block
+	blocks = append(blocks, homeLeaseBlocks(user)...)
+	blocks = append(blocks, slack.NewDividerBlock())
+	blocks = append(blocks, homePoolBlocks()...)
+	blocks = append(blocks, slack.NewDividerBlock())
+	blocks = append(blocks, pullRequests...)
+	blocks = append(blocks, slack.NewDividerBlock())
+	blocks = append(blocks, homeIssueBlocks(user)...)
+	blocks = append(blocks, slack.NewContextBlock("",
+		slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("updated %s", slackDate(time.Now())), false, false)))
+
+	return slack.HomeTabViewRequest{
+		Type:   slack.VTHomeTab,
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}
+}
+
+func homeSection(text string) *slack.SectionBlock {
+	return slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)
+}
+
+func homeHeader(text string) *slack.HeaderBlock {
+	return slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, text, false, false))
+}
+
+// slackDate formats t so that Slack shows it in the timezone of the reader
+func slackDate(t time.Time) string {
+	return fmt.Sprintf("<!date^%d^{date_short_pretty} {time}|%s>", t.Unix(), t.UTC().Format("2006-01-02 15:04 MST"))
+}
+
+func homeLeaseBlocks(user string) []slack.Block {
+	blocks := []slack.Block{homeHeader("Your CI leases")}
+	leases := homeLeases(user)
+	if len(leases) == 0 {
+		return append(blocks, homeSection("you have no leases. acquire one with `@splat-bot ci lease acquire` or `@splat-bot ci lease wizard`"))
+	}
+
+	var builder strings.Builder
+	for _, lease := range leases {
+		details := []string{fmt.Sprintf("`%s`", lease.Name)}
+		if lease.NetworkOnly {
+			details = append(details, "network only")
+		} else {
+			details = append(details, fmt.Sprintf("%d vCPUs, %dGB memory", lease.VCpus, lease.Memory))
+		}
+		if len(lease.Pool) > 0 {
+			details = append(details, fmt.Sprintf("pool %s", lease.Pool))
+		}
+		if len(lease.Phase) > 0 {
+			details = append(details, string(lease.Phase))
+		}
+		if !lease.Expires.IsZero() {
+			details = append(details, fmt.Sprintf("expires %s", slackDate(lease.Expires)))
+		}
+		fmt.Fprintf(&builder, "- %s\n", strings.Join(details, " · "))
+	}
+	return append(blocks, homeSection(builder.String()), controllers.LeaseActionsBlock("home-lease-actions"))
+}
+
+func homePoolBlocks() []slack.Block {
+	blocks := []slack.Block{homeHeader("CI pool capacity")}
+	pools := homePoolCapacity()
+	if len(pools) == 0 {
+		return append(blocks, homeSection("no pools are known to the bot"))
+	}
+
+	var builder strings.Builder
+	for _, pool := range pools {
+		fmt.Fprintf(&builder, "- *%s* %d/%d vCPUs, %d/%dGB memory available", pool.Name, pool.VCpusAvailable, pool.VCpus, pool.MemoryAvailable, pool.Memory)
+		if pool.NoSchedule {
+			builder.WriteString(" _(cordoned)_")
+		}
+		builder.WriteString("\n")
+	}
+	return append(blocks, homeSection(builder.String()))
+}
+
+func homePullRequestBlocks(ctx context.Context, user string) []slack.Block {
+	blocks := []slack.Block{homeHeader("Your pull requests")}
+	if !githubEnabled {
+		return append(blocks, homeSection("GitHub is not configured for the bot"))
+	}
+	login, ok := getGithubLogin(user)
+	if !ok {
+		return append(blocks, homeSection("your GitHub login is not known to the bot. ask an admin to add it to the GitHub logins file"))
+	}
+
+	sections := []struct {
+		title string
+		query string
+	}{
+		{"*opened by you*", fmt.Sprintf("is:pr state:open author:%s", login)},
+		{"*awaiting your review*", fmt.Sprintf("is:pr state:open review-requested:%s", login)},
+	}
+	for _, section := range sections {
+		prs, err := homeSearchPullRequests(ctx, section.query)
+		if err != nil {
+			log.Warnf("unable to search pull requests for the home tab of %s: %v", user, err)
+			blocks = append(blocks, homeSection(fmt.Sprintf("%s\nunable to search GitHub", section.title)))
+			continue
+		}
+		blocks = append(blocks, homeSection(fmt.Sprintf("%s\n%s", section.title, pullRequestList(prs))))
+	}
+	return blocks
+}
+
+func pullRequestList(prs []prstatus.PullRequest) string {
+	if len(prs) == 0 {
+		return "none"
+	}
+	var builder strings.Builder
+	for index, pr := range prs {
+		if index == homeMaxPullRequests {
+			fmt.Fprintf(&builder, "and %d more\n", len(prs)-homeMaxPullRequests)
+			break
+		}
+		fmt.Fprintf(&builder, "- <%s|%s/%s#%d> %s\n", generatePrURL(pr), pr.Repository.Owner.Login, pr.Repository.Name, pr.Number, pr.Title)
+	}
+	return builder.String()
+}
+
+// createdIssue is a Jira issue which was created through the bot
+type createdIssue struct {
+	Key  string
+	Time time.Time
+}
+
+// recentIssues returns the Jira issues most recently created by the user, newest first. Issues are found in the audit
+// log so only issues created through the bot are returned.
+func recentIssues(auditLog *audit.Log, user string, limit int) ([]createdIssue, error) {
+	records, err := auditLog.Query(audit.Filter{User: user})
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []createdIssue
+	for index := len(records) - 1; index >= 0 && len(issues) < limit; index-- {
+		for _, object := range records[index].Objects {
+			if object.Kind == "Issue" && object.Verb == "create" && len(issues) < limit {
+				issues = append(issues, createdIssue{Key: object.Name, Time: records[index].Time})
+			}
+		}
+	}
+	return issues, nil
+}
+
+func homeIssueBlocks(user string) []slack.Block {
+	blocks := []slack.Block{homeHeader("Your recent Jira issues")}
+	auditLog := audit.Default()
+	if auditLog == nil {
+		return append(blocks, homeSection("issues are found in the audit log, which is not configured for the bot"))
+	}
+	issues, err := recentIssues(auditLog, user, homeMaxIssues)
+	if err != nil {
+		log.Warnf("unable to find the issues created by %s: %v", user, err)
+		return append(blocks, homeSection("unable to read the audit log"))
+	}
+	if len(issues) == 0 {
+		return append(blocks, homeSection("you have not created any issues through the bot. try `@splat-bot jira bug`"))
+	}
+
+	var builder strings.Builder
+	for _, issue := range issues {
+		fmt.Fprintf(&builder, "- <%s/browse/%s|%s> created %s\n", JIRA_BASE_URL, issue.Key, issue.Key, slackDate(issue.Time))
+	}
+	return append(blocks, homeSection(builder.String()))
+}
@@ -0,0 +1,267 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"sigs.k8s.io/prow/pkg/prstatus"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
+	"github.com/openshift-splat-team/splat-bot/pkg/controllers"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// homeSearches counts the GitHub searches made by the home tab
+var homeSearches atomic.Int32
+
+// withHomeSources replaces the sources of the home tab for the duration of a test. Pull requests are keyed by the
+// GitHub search query which returns them.
+func withHomeSources(t *testing.T, leases []controllers.LeaseSummary, pools []controllers.PoolCapacity, prs map[string][]prstatus.PullRequest) {
+	savedLeases, savedPools, savedSearch := homeLeases, homePoolCapacity, homeSearchPullRequests
+	savedEnabled := githubEnabled
+	homeMu.Lock()
+	savedUsers, savedAfter := homeUsers, homeAfter
+	homeUsers = cache.NewLRUExpireCache(homeMaxUsers)
+	homeMu.Unlock()
+
+	homeLeases = func(user string) []controllers.LeaseSummary {
+		return leases
+	}
+	homePoolCapacity = func() []controllers.PoolCapacity {
+		return pools
+	}
+	homeSearchPullRequests = func(ctx context.Context, query string) ([]prstatus.PullRequest, error) {
+		homeSearches.Add(1)
+		found, ok := prs[query]
+		if !ok {
+			return nil, errors.New("unexpected query")
+		}
+		return found, nil
+	}
+	githubEnabled = true
+	githubLoginsMu.Lock()
+	savedLogins := githubLogins
+	githubLogins = map[string]string{"U1": "octocat"}
+	githubLoginsMu.Unlock()
+
+	t.Cleanup(func() {
+		homeLeases, homePoolCapacity, homeSearchPullRequests = savedLeases, savedPools, savedSearch
+		githubEnabled = savedEnabled
+		githubLoginsMu.Lock()
+		githubLogins = savedLogins
+		githubLoginsMu.Unlock()
+		homeMu.Lock()
+		homeUsers, homeAfter = savedUsers, savedAfter
+		homeMu.Unlock()
+	})
+}
+
+func pullRequest(owner, repo string, number int, title string) prstatus.PullRequest {
+	pr := prstatus.PullRequest{
+		Number: githubql.Int(number),
+		Title:  githubql.String(title),
+	}
+	pr.Repository.Owner.Login = githubql.String(owner)
+	pr.Repository.Name = githubql.String(repo)
+	return pr
+}
+
+func homeOpenedEvent(user string) slackevents.EventsAPIEvent {
+	return slackevents.EventsAPIEvent{
+		Type: slackevents.CallbackEvent,
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: string(slackevents.AppHomeOpened),
+			Data: &slackevents.AppHomeOpenedEvent{
+				Type: string(slackevents.AppHomeOpened),
+				User: user,
+				Tab:  "home",
+			},
+		},
+	}
+}
+
+func TestHomeHandler(t *testing.T) {
+	auditLog := withAuditLog(t)
+	record := newAuditRecord(auditSourceMessage, "U1", "C1", []string{"jira", "bug"}, []string{"jira", "bug"})
+	audit.Touched(audit.NewContext(context.TODO(), record), "create", "Issue", "SPLAT", "SPLAT-1234")
+	record.Finish(audit.OutcomeSuccess, nil)
+	if err := auditLog.Write(record); err != nil {
+		t.Fatalf("unable to write audit record: %v", err)
+	}
+
+	withHomeSources(t,
+		[]controllers.LeaseSummary{
+			{Name: "user-lease-abc", VCpus: 24, Memory: 96, Pool: "pool-1", Phase: "Fulfilled", Expires: time.Now().Add(time.Hour)},
+			{Name: "user-lease-def", Pool: "pool-1", NetworkOnly: true},
+		},
+		[]controllers.PoolCapacity{
+			{Name: "pool-1", VCpus: 100, VCpusAvailable: 76, Memory: 400, MemoryAvailable: 304},
+			{Name: "pool-2", VCpus: 50, Memory: 200, NoSchedule: true},
+		},
+		map[string][]prstatus.PullRequest{
+			"is:pr state:open author:octocat":           {pullRequest("openshift", "installer", 1, "fix vSphere zones")},
+			"is:pr state:open review-requested:octocat": nil,
+		},
+	)
+
+	client := util.NewFakeClient()
+	if err := Handler(context.TODO(), client, homeOpenedEvent("U1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	views := client.HomeViews()
+	if len(views) != 1 {
+		t.Fatalf("expected the home tab to be published once, got %d", len(views))
+	}
+	if views[0].UserID != "U1" || views[0].View.Type != slack.VTHomeTab {
+		t.Errorf("expected the home tab of U1 to be published, got %s %s", views[0].View.Type, views[0].UserID)
+	}
+
+	rendered := util.RenderBlocks(views[0].View.Blocks.BlockSet)
+	for _, expected := range []string{
+		"`user-lease-abc` · 24 vCPUs, 96GB memory · pool pool-1 · Fulfilled · expires <!date^",
+		"`user-lease-def` · network only · pool pool-1\n",
+		"[Renew] [Release]",
+		"*pool-1* 76/100 vCPUs, 304/400GB memory available\n",
+		"*pool-2* 0/50 vCPUs, 0/200GB memory available _(cordoned)_",
+		"<https://github.com/openshift/installer/pull/1|openshift/installer#1> fix vSphere zones",
+		"*awaiting your review*\nnone",
+		"<https://issues.redhat.com/browse/SPLAT-1234|SPLAT-1234> created",
+	} {
+		if !strings.Contains(rendered, expected) {
+			t.Errorf("expected the home tab to contain %q, got:\n%s", expected, rendered)
+		}
+	}
+
+	// the home tab of users who have not opened it is not published when their leases change
+	var scheduled []func()
+	homeAfter = func(delay time.Duration, refresh func()) {
+		scheduled = append(scheduled, refresh)
+	}
+	client.Reset()
+	refreshHome(context.TODO(), client, "U2")
+	if len(scheduled) != 0 {
+		t.Errorf("expected the home tab of U2 to not be refreshed")
+	}
+
+	// the lease changes which arrive before the refresh runs are published once, without searching GitHub again
+	searches := homeSearches.Load()
+	refreshHome(context.TODO(), client, "U1")
+	refreshHome(context.TODO(), client, "U1")
+	if len(scheduled) != 1 {
+		t.Fatalf("expected one refresh of the home tab of U1 to be scheduled, got %d", len(scheduled))
+	}
+	scheduled[0]()
+	views = client.HomeViews()
+	if len(views) != 1 {
+		t.Fatalf("expected the home tab of U1 to be published again, got %d views", len(views))
+	}
+	if homeSearches.Load() != searches {
+		t.Errorf("expected a lease change to not search GitHub")
+	}
+	if rendered := util.RenderBlocks(views[0].View.Blocks.BlockSet); !strings.Contains(rendered, "fix vSphere zones") {
+		t.Errorf("expected the refreshed home tab to keep the pull requests, got:\n%s", rendered)
+	}
+	refreshHome(context.TODO(), client, "U1")
+	if len(scheduled) != 2 {
+		t.Errorf("expected another refresh to be scheduled once the previous one ran")
+	}
+}
+
+func TestHomeWithoutSources(t *testing.T) {
+	withHomeSources(t, nil, nil, nil)
+	githubEnabled = false
+	saved := audit.Default()
+	audit.SetDefault(nil)
+	t.Cleanup(func() {
+		audit.SetDefault(saved)
+	})
+
+	rendered := util.RenderBlocks(homeView("U1", homePullRequestBlocks(context.TODO(), "U1")).Blocks.BlockSet)
+	for _, expected := range []string{
+		"you have no leases",
+		"no pools are known to the bot",
+		"GitHub is not configured for the bot",
+		"the audit log, which is not configured",
+	} {
+		if !strings.Contains(rendered, expected) {
+			t.Errorf("expected the home tab to contain %q, got:\n%s", expected, rendered)
+		}
+	}
+	if strings.Contains(rendered, "[Renew]") {
+		t.Errorf("expected no lease buttons without leases, got:\n%s", rendered)
+	}
+
+	githubEnabled = true
+	rendered = util.RenderBlocks(homeView("U2", homePullRequestBlocks(context.TODO(), "U2")).Blocks.BlockSet)
+	if !strings.Contains(rendered, "your GitHub login is not known to the bot") {
+		t.Errorf("expected the home tab of a user without a GitHub login to say so, got:\n%s", rendered)
+	}
+}
+
+func TestHomeTabActionRespondsInDM(t *testing.T) {
+	withActions(t, data.Action{
+		ActionID:           controllers.LeaseRenewActionID,
+		AllowNonSplatUsers: true,
+		ReplaceOriginal:    true,
+		Handler: func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+			return util.StringToBlock("lease renewed", false), nil
+		},
+	})
+
+	callback := slack.InteractionCallback{
+		Type: slack.InteractionTypeBlockActions,
+		User: slack.User{ID: "U1"},
+		View: slack.View{Type: slack.VTHomeTab},
+		ActionCallback: slack.ActionCallbacks{
+			BlockActions: []*slack.BlockAction{{ActionID: controllers.LeaseRenewActionID}},
+		},
+	}
+	client := util.NewFakeClient()
+	if err := ActionHandler(context.TODO(), client, callback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	message, ok := client.LastMessage()
+	if !ok {
+		t.Fatalf("expected a response to be posted")
+	}
+	if message.Channel != "DU1" || message.Ephemeral || len(message.ResponseURL) > 0 {
+		t.Errorf("expected the response to be posted in a DM with U1, got %+v", message)
+	}
+	if !strings.Contains(message.Render(), "lease renewed") {
+		t.Errorf("expected the response of the action, got %q", message.Render())
+	}
+}
+
+func TestRecentIssues(t *testing.T) {
+	auditLog := withAuditLog(t)
+	for index, key := range []string{"SPLAT-1", "SPLAT-2", "SPLAT-3"} {
+		record := newAuditRecord(auditSourceMessage, "U1", "C1", []string{"jira", "create"}, nil)
+		record.Time = time.Date(2024, 1, index+1, 0, 0, 0, 0, time.UTC)
+		audit.Touched(audit.NewContext(context.TODO(), record), "create", "Issue", "SPLAT", key)
+		if err := auditLog.Write(record); err != nil {
+			t.Fatalf("unable to write audit record: %v", err)
+		}
+	}
+	other := newAuditRecord(auditSourceMessage, "U2", "C1", []string{"jira", "create"}, nil)
+	audit.Touched(audit.NewContext(context.TODO(), other), "create", "Issue", "SPLAT", "SPLAT-4")
+	if err := auditLog.Write(other); err != nil {
+		t.Fatalf("unable to write audit record: %v", err)
+	}
+
+	issues, err := recentIssues(auditLog, "U1", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 2 || issues[0].Key != "SPLAT-3" || issues[1].Key != "SPLAT-2" {
+		t.Errorf("expected the 2 newest issues of U1, got %+v", issues)
+	}
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// InteractionHandler routes interactions, such as button clicks, shortcuts and modal submissions, to the handler for
+// their type.
+func InteractionHandler(ctx context.Context, client util.SlackClientInterface, data slack.InteractionCallback) error {
+	// This outputs the event data for debugging
+	buffer := bytes.NewBuffer([]byte{})
+	if err := json.NewEncoder(buffer).Encode(data); err != nil {
+		log.Warnf("Error: %v", err)
+	} else {
+		log.Debugf("EVENT DATA: %v", buffer.String())
+	}
+
+	// block_actions payloads are received when a user clicks a Block Kit interactive component.
+	//shortcut and message_actions payloads are received when global and message shortcuts are used.
+	//view_submission payloads are received when a modal is submitted.
+	//view_closed payloads are received when a modal is cancelled.
+	log.Printf("Handling event type %v", data.Type)
+	switch data.Type {
+	case slack.InteractionTypeDialogCancellation:
+	case slack.InteractionTypeDialogSubmission:
+	case slack.InteractionTypeDialogSuggestion:
+	case slack.InteractionTypeInteractionMessage:
+	case slack.InteractionTypeMessageAction, slack.InteractionTypeShortcut:
+		return ShortcutHandler(ctx, client, data)
+	case slack.InteractionTypeBlockActions:
+		return ActionHandler(ctx, client, data)
+	case slack.InteractionTypeBlockSuggestion:
+	case slack.InteractionTypeViewSubmission:
+		return ViewSubmissionHandler(ctx, client, data)
+	case slack.InteractionTypeViewClosed:
+	case slack.InteractionTypeWorkflowStepEdit:
+	default:
+		log.Warnf("Unexpected event type received: %s\n", data.Type)
+	}
+	return nil
+}
@@ -4,10 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
-	"strings"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 
@@ -18,6 +15,8 @@ import (
 
 func init() {
 	AddCommand(LeasesAttributes)
+	AddCommand(LeaseAcquireAttributes)
+	AddCommand(LeaseWizardAttributes)
 }
 
 type leaseOptions struct {
@@ -27,37 +26,47 @@ type leaseOptions struct {
 	pool     string
 }
 
-func getLeaseOptions(args []string) leaseOptions {
-	cpus := 24
-	memory := 96
-	networks := 1
-	pools := ""
-	log.Printf("lease args: %v", args)
-	if len(args) >= 4 {
-		log.Printf("applying options to lease")
-		for _, arg := range args[3:] {
-			parts := strings.Split(arg, "=")
-			if len(parts) != 2 {
-				continue
-			}
-			switch parts[0] {
-			case "cpus":
-				cpus, _ = strconv.Atoi(parts[1])
-			case "memory":
-				memory, _ = strconv.Atoi(parts[1])
-			case "networks":
-				networks, _ = strconv.Atoi(parts[1])
-			case "pools":
-				// Need to remove the double quotes if added for multiple pools
-				pools = strings.Replace(parts[1], "\"", "", -1)
-			}
-		}
-	}
+var leaseAcquireParams = []data.Param{
+	{
+		Name:        "cpus",
+		Type:        data.ParamInt,
+		KeyValue:    true,
+		Default:     "24",
+		Min:         1,
+		Max:         128,
+		Description: "vCPUs to lease",
+	},
+	{
+		Name:        "memory",
+		Type:        data.ParamInt,
+		KeyValue:    true,
+		Default:     "96",
+		Min:         1,
+		Max:         512,
+		Description: "memory to lease in GB",
+	},
+	{
+		Name:        "networks",
+		Type:        data.ParamInt,
+		KeyValue:    true,
+		Default:     "1",
+		Min:         1,
+		Max:         4,
+		Description: "number of networks to lease",
+	},
+	{
+		Name:        "pools",
+		KeyValue:    true,
+		Description: "pool to lease from",
+	},
+}
+
+func getLeaseOptions(args *data.ParsedArgs) leaseOptions {
 	return leaseOptions{
-		cpus:     cpus,
-		memory:   memory,
-		networks: networks,
-		pool:     pools,
+		cpus:     args.Int("cpus"),
+		memory:   args.Int("memory"),
+		networks: args.Int("networks"),
+		pool:     args.String("pools"),
 	}
 }
 
@@ -84,56 +93,81 @@ func validateLeaseOptions(ctx context.Context, options leaseOptions) error {
 	return nil
 }
 
+func acquireLease(ctx context.Context, user string, options leaseOptions) ([]slack.MsgOption, error) {
+	if err := validateLeaseOptions(ctx, options); err != nil {
+		return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to acquire lease: %w", err)
+	}
+
+	_, err := controllers.AcquireLease(ctx, user, options.cpus, options.memory, options.pool, options.networks)
+	if err != nil {
+		return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to acquire lease: %w", err)
+	}
+	return util.StringToBlock("Lease(s) have been created. Once fulfilled by the vSphere capacity manager you will receive a direct message "+
+		"with further details. This could take a few minutes.", false), nil
+}
+
+func renewLease(ctx context.Context, user string) ([]slack.MsgOption, error) {
+	expires, err := controllers.RenewLease(ctx, user)
+	if err != nil {
+		return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to renew lease: %w", err)
+	}
+	return util.StringToBlock(fmt.Sprintf("Your lease has been renewed. It expires at %s", expires), false), nil
+}
+
+func releaseLease(ctx context.Context, user string) ([]slack.MsgOption, error) {
+	err := controllers.RemoveLease(ctx, user)
+	if err != nil {
+		return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to release lease: %w", err)
+	}
+	return util.StringToBlock("Your lease(s) and associated resources are being deleted. You will receive a notification when this is complete.", false), nil
+}
+
 var LeasesAttributes = data.Attributes{
 	Commands:       []string{"ci", "lease"},
 	RequireMention: true,
-	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
-		result := ""
-		var err error
-		if len(args) > 2 {
-			switch args[2] {
-			case "acquire":
-				options := getLeaseOptions(args)
-
-				if err = validateLeaseOptions(ctx, options); err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to acquire lease: %w", err)
-				}
-
-				_, err := controllers.AcquireLease(ctx, evt.User, options.cpus, options.memory, options.pool, options.networks)
-				if err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to acquire lease: %w", err)
-				}
-				result = "Lease(s) have been created. Once fulfilled by the vSphere capacity manager you will receive a direct message " +
-					"with further details. This could take a few minutes."
-			case "renew":
-				expires, err := controllers.RenewLease(ctx, evt.User)
-				if err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to renew lease: %w", err)
-				}
-				result = fmt.Sprintf("Your lease has been renewed. It expires at %s", expires)
-			case "release":
-				err = controllers.RemoveLease(ctx, evt.User)
-				if err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to set pool unschedulable: %w", err)
-				}
-				result = "Your lease(s) and associated resources are being deleted. You will receive a notification when this is complete."
-			case "list":
-				fallthrough
-			default:
-				result, err = controllers.GetLeaseStatus(evt.User)
-				if err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to fetch pool status: %w", err)
-				}
-			}
+	Params: []data.Param{
+		{
+			Name:    "action",
+			Type:    data.ParamEnum,
+			Values:  []string{"list", "renew", "release"},
+			Default: "list",
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		switch args.String("action") {
+		case "renew":
+			return renewLease(ctx, evt.User)
+		case "release":
+			return releaseLease(ctx, evt.User)
 		}
 
+		result, err := controllers.GetLeaseStatus(evt.User)
+		if err != nil {
+			return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to fetch lease status: %w", err)
+		}
 		return util.StringToBlock(result, false), nil
 	},
-	RequiredArgs: 0,
-	HelpMarkdown: "interact with your vSphere CI leases: `ci lease list|acquire|release`",
+	Actions: []data.Action{
+		{
+			ActionID: controllers.LeaseRenewActionID,
+			Commands: []string{"ci", "lease", "renew"},
+			Handler: func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+				return renewLease(ctx, callback.User.ID)
+			},
+		},
+		{
+			ActionID:        controllers.LeaseReleaseActionID,
+			Commands:        []string{"ci", "lease", "release"},
+			ReplaceOriginal: true,
+			Handler: func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+				return releaseLease(ctx, callback.User.ID)
+			},
+		},
+	},
+	Category:     data.CategoryCI,
+	HelpMarkdown: "interact with your vSphere CI leases: `ci lease list|renew|release`",
 	ShouldMatch: []string{
 		"ci lease list",
-		"ci lease acquire (optional args) cpus=24 memory=96 networks=1 pools=\"space-separated-pool-names\"",
 		"ci lease release",
 	},
 	ShouldntMatch: []string{
@@ -141,3 +175,65 @@ var LeasesAttributes = data.Attributes{
 		"jira create-with-summary PROJECT Todo",
 	},
 }
+
+var LeaseAcquireAttributes = data.Attributes{
+	Commands:       []string{"ci", "lease", "acquire"},
+	RequireMention: true,
+	Params:         leaseAcquireParams,
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		return acquireLease(ctx, evt.User, getLeaseOptions(args))
+	},
+	Category:     data.CategoryCI,
+	HelpMarkdown: "acquire a vSphere CI lease: `ci lease acquire cpus=24 memory=96 networks=1 pools=<pool name>`",
+	ShouldMatch: []string{
+		"ci lease acquire",
+		"ci lease acquire cpus=24 memory=96 networks=1 pools=\"pool-1\"",
+	},
+	ShouldntMatch: []string{
+		"ci lease list",
+		"ci pools list",
+	},
+}
+
+// leaseWizardSteps asks for the same options as leaseAcquireParams, one at a time
+var leaseWizardSteps = []data.Step{
+	{
+		Param:   leaseAcquireParams[3],
+		Prompt:  "which pool should the lease come from?",
+		Choices: controllers.GetPoolNames,
+	},
+	{
+		Param:  leaseAcquireParams[0],
+		Prompt: "how many vCPUs do you need?",
+	},
+	{
+		Param:  leaseAcquireParams[1],
+		Prompt: "how much memory do you need in GB?",
+	},
+	{
+		Param:  leaseAcquireParams[2],
+		Prompt: "how many networks do you need?",
+	},
+}
+
+var LeaseWizardAttributes = data.Attributes{
+	Commands:       []string{"ci", "lease", "wizard"},
+	RequireMention: true,
+	Params:         leaseAcquireParams,
+	Wizard: &data.Wizard{
+		Steps: leaseWizardSteps,
+		Finish: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, answers *data.ParsedArgs) ([]slack.MsgOption, error) {
+			return acquireLease(ctx, evt.User, getLeaseOptions(answers))
+		},
+	},
+	Category:     data.CategoryCI,
+	HelpMarkdown: "acquire a vSphere CI lease by answering a few questions. options given with the command are not asked: `ci lease wizard [cpus=<vCPUs>] [memory=<GB>] [networks=<count>] [pools=<pool>]`",
+	ShouldMatch: []string{
+		"ci lease wizard",
+		"ci lease wizard cpus=16 pools=vcenter-1",
+	},
+	ShouldntMatch: []string{
+		"ci lease acquire",
+		"ci lease list",
+	},
+}
@@ -13,6 +13,8 @@ func TestValidateLeaseOptions(t *testing.T) {
 		name              string
 		options           []string
 		expectedPoolValue string
+		expectedCpus      int
+		expectError       bool
 	}{
 		{
 			name: "Normal pool name",
@@ -23,6 +25,7 @@ func TestValidateLeaseOptions(t *testing.T) {
 				"pools=pool1",
 			},
 			expectedPoolValue: "pool1",
+			expectedCpus:      4,
 		},
 		{
 			name: "Pool name with quotes around it",
@@ -33,14 +36,42 @@ func TestValidateLeaseOptions(t *testing.T) {
 				"pools=\"pool1\"",
 			},
 			expectedPoolValue: "pool1",
+			expectedCpus:      4,
+		},
+		{
+			name:         "Defaults",
+			options:      []string{},
+			expectedCpus: 24,
+		},
+		{
+			name:        "Invalid cpus",
+			options:     []string{"cpus=four"},
+			expectError: true,
+		},
+		{
+			name:        "Cpus out of bounds",
+			options:     []string{"cpus=1000"},
+			expectError: true,
+		},
+		{
+			name:        "Unknown option",
+			options:     []string{"disks=2"},
+			expectError: true,
 		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			options := getLeaseOptions(tc.options)
+			parsed, err := parseParams(LeaseAcquireAttributes.Params, tc.options, tc.options)
+			if tc.expectError {
+				gs.Expect(err).To(HaveOccurred())
+				return
+			}
+			gs.Expect(err).NotTo(HaveOccurred())
 
+			options := getLeaseOptions(parsed)
 			gs.Expect(options.pool).To(Equal(tc.expectedPoolValue))
+			gs.Expect(options.cpus).To(Equal(tc.expectedCpus))
 		})
 	}
 }
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// threadKey identifies the thread of a message. Messages which are not in a thread start their own thread.
+func threadKey(channel, threadTimeStamp, timeStamp string) string {
+	if len(threadTimeStamp) > 0 {
+		return fmt.Sprintf("%s/%s", channel, threadTimeStamp)
+	}
+	return fmt.Sprintf("%s/%s", channel, timeStamp)
+}
+
+// EventOrderingKey returns the key which orders the handling of an event. Events in the same thread share a key.
+func EventOrderingKey(evt slackevents.EventsAPIEvent) string {
+	switch ev := evt.InnerEvent.Data.(type) {
+	case *slackevents.AppMentionEvent:
+		return threadKey(ev.Channel, ev.ThreadTimeStamp, ev.TimeStamp)
+	case *slackevents.MessageEvent:
+		return threadKey(ev.Channel, ev.ThreadTimeStamp, ev.TimeStamp)
+	case *slackevents.AppHomeOpenedEvent:
+		return fmt.Sprintf("home/%s", ev.User)
+	}
+	return evt.Type
+}
+
+// InteractionOrderingKey returns the key which orders the handling of an interaction. Interactions with the same
+// message share a key.
+func InteractionOrderingKey(callback slack.InteractionCallback) string {
+	if len(callback.Message.Timestamp) > 0 {
+		return threadKey(callback.Channel.ID, callback.Message.ThreadTimestamp, callback.Message.Timestamp)
+	}
+	return callback.User.ID
+}
+
+// SlashCommandOrderingKey returns the key which orders the handling of a slash command. Commands from the same user
+// in the same channel share a key.
+func SlashCommandOrderingKey(cmd slack.SlashCommand) string {
+	return fmt.Sprintf("%s/%s", cmd.ChannelID, cmd.UserID)
+}
@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+)
+
+var (
+	userMentionRegex    = regexp.MustCompile(`^<@([A-Z0-9]+)(\|[^>]*)?>$`)
+	channelMentionRegex = regexp.MustCompile(`^<#([A-Z0-9]+)(\|[^>]*)?>$`)
+)
+
+// parseParams validates args against params. args are the arguments which follow the command. raw are the tokens of
+// the entire message and are retained in the parsed arguments for callbacks which need them.
+func parseParams(params []data.Param, args []string, raw []string) (*data.ParsedArgs, error) {
+	parsed := data.NewParsedArgs(raw)
+
+	keyValue := map[string]data.Param{}
+	var positional []data.Param
+	for _, param := range params {
+		if param.KeyValue {
+			keyValue[param.Name] = param
+		} else {
+			positional = append(positional, param)
+		}
+	}
+
+	provided := map[string]bool{}
+	position := 0
+	for idx := 0; idx < len(args); idx++ {
+		arg := args[idx]
+		if len(arg) == 0 {
+			continue
+		}
+
+		// key=value arguments are only recognized for known keys so that positional values may contain '='
+		if key, value, found := strings.Cut(arg, "="); found {
+			if param, ok := keyValue[key]; ok {
+				if provided[key] {
+					return nil, fmt.Errorf("%s was provided more than once", key)
+				}
+				if err := setParam(parsed, param, value); err != nil {
+					return nil, err
+				}
+				provided[key] = true
+				continue
+			}
+			if position >= len(positional) && len(keyValue) > 0 {
+				return nil, fmt.Errorf("unknown option %s. expected one of %s", key, strings.Join(keyValueNames(params), ", "))
+			}
+		}
+
+		if position >= len(positional) {
+			return nil, fmt.Errorf("unexpected argument %q", arg)
+		}
+		param := positional[position]
+		position++
+		if param.Remainder {
+			parsed.SetTokens(param.Name, nonEmpty(args[idx:]))
+			arg = strings.Join(nonEmpty(args[idx:]), " ")
+			idx = len(args)
+		}
+		if err := setParam(parsed, param, arg); err != nil {
+			return nil, err
+		}
+		provided[param.Name] = true
+	}
+
+	for _, param := range params {
+		if provided[param.Name] {
+			continue
+		}
+		if param.Required {
+			return nil, fmt.Errorf("missing required argument %s", param.Name)
+		}
+		if len(param.Default) > 0 {
+			if err := setParam(parsed, param, param.Default); err != nil {
+				return nil, fmt.Errorf("invalid default: %v", err)
+			}
+		}
+	}
+	return parsed, nil
+}
+
+func setParam(parsed *data.ParsedArgs, param data.Param, value string) error {
+	value = strings.Trim(value, "\"")
+	switch param.Type {
+	case data.ParamInt:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a whole number, got %q", param.Name, value)
+		}
+		if param.Max > param.Min && (number < param.Min || number > param.Max) {
+			return fmt.Errorf("%s must be between %d and %d, got %d", param.Name, param.Min, param.Max, number)
+		}
+		parsed.Set(param.Name, number)
+	case data.ParamEnum:
+		for _, allowed := range param.Values {
+			if strings.EqualFold(allowed, value) {
+				parsed.Set(param.Name, allowed)
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s, got %q", param.Name, strings.Join(param.Values, ", "), value)
+	case data.ParamDuration:
+		duration, err := parseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a duration such as 30m, 4h or 2d, got %q", param.Name, value)
+		}
+		parsed.Set(param.Name, duration)
+	case data.ParamUser:
+		match := userMentionRegex.FindStringSubmatch(value)
+		if match == nil {
+			return fmt.Errorf("%s must mention a Slack user such as @user, got %q", param.Name, value)
+		}
+		parsed.Set(param.Name, match[1])
+	case data.ParamChannel:
+		match := channelMentionRegex.FindStringSubmatch(value)
+		if match == nil {
+			return fmt.Errorf("%s must mention a Slack channel such as #channel, got %q", param.Name, value)
+		}
+		parsed.Set(param.Name, match[1])
+	default:
+		if len(value) == 0 {
+			return fmt.Errorf("%s must not be empty", param.Name)
+		}
+		parsed.Set(param.Name, value)
+	}
+	return nil
+}
+
+// parseDuration extends time.ParseDuration with days, which are more natural for leases and reminders.
+func parseDuration(value string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	return duration, nil
+}
+
+func keyValueNames(params []data.Param) []string {
+	var names []string
+	for _, param := range params {
+		if param.KeyValue {
+			names = append(names, param.Name)
+		}
+	}
+	return names
+}
+
+func nonEmpty(args []string) []string {
+	var values []string
+	for _, arg := range args {
+		if len(arg) > 0 {
+			values = append(values, arg)
+		}
+	}
+	return values
+}
+
+// paramPlaceholder describes the value a parameter accepts
+func paramPlaceholder(param data.Param) string {
+	switch param.Type {
+	case data.ParamInt:
+		if param.Max > param.Min {
+			return fmt.Sprintf("%d-%d", param.Min, param.Max)
+		}
+		return "number"
+	case data.ParamEnum:
+		return strings.Join(param.Values, "|")
+	case data.ParamDuration:
+		return "duration"
+	case data.ParamUser:
+		return "@user"
+	case data.ParamChannel:
+		return "#channel"
+	}
+	return param.Name
+}
+
+// usage generates a usage line for a command from its parameters. for example: `ci lease acquire [cpus=<1-128>]`
+func usage(command []string, params []data.Param) string {
+	parts := append([]string{}, command...)
+	for _, param := range params {
+		var part string
+		if param.KeyValue {
+			part = fmt.Sprintf("%s=<%s>", param.Name, paramPlaceholder(param))
+		} else {
+			part = paramPlaceholder(param)
+			if param.Type == data.ParamInt || param.Type == data.ParamDuration || param.Type == data.ParamUser ||
+				param.Type == data.ParamChannel {
+				part = fmt.Sprintf("%s:%s", param.Name, part)
+			}
+			if param.Remainder {
+				part += "..."
+			}
+			if param.Required {
+				part = fmt.Sprintf("<%s>", part)
+			}
+		}
+		if !param.Required {
+			part = fmt.Sprintf("[%s]", part)
+		}
+		parts = append(parts, part)
+	}
+	return fmt.Sprintf("usage: `%s`", strings.Join(parts, " "))
+}
+
+// usageError is the response to a command whose arguments could not be parsed
+func usageError(err error, command []string, params []data.Param, helpMarkdown string) string {
+	response := fmt.Sprintf("%v\n%s", err, usage(command, params))
+	if len(helpMarkdown) > 0 {
+		response = fmt.Sprintf("%s\n%s", response, helpMarkdown)
+	}
+	return response
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+)
+
+var testParams = []data.Param{
+	{Name: "platform", Required: true},
+	{Name: "state", Type: data.ParamEnum, Values: []string{"success", "failure"}, Default: "success"},
+	{Name: "count", Type: data.ParamInt, KeyValue: true, Min: 1, Max: 10},
+	{Name: "for", Type: data.ParamDuration, KeyValue: true, Default: "1h"},
+	{Name: "owner", Type: data.ParamUser, KeyValue: true},
+	{Name: "in", Type: data.ParamChannel, KeyValue: true},
+}
+
+func TestParseParams(t *testing.T) {
+	parsed, err := parseParams(testParams, []string{"vsphere", "count=3", "FAILURE", "for=2d", "owner=<@U123|someone>", "in=<#C123|standup>"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.String("platform") != "vsphere" || parsed.String("state") != "failure" {
+		t.Errorf("unexpected positional values: %s %s", parsed.String("platform"), parsed.String("state"))
+	}
+	if parsed.Int("count") != 3 {
+		t.Errorf("expected count to be 3, got %d", parsed.Int("count"))
+	}
+	if parsed.Duration("for") != 48*time.Hour {
+		t.Errorf("expected for to be 48h, got %v", parsed.Duration("for"))
+	}
+	if parsed.String("owner") != "U123" {
+		t.Errorf("expected owner to be U123, got %s", parsed.String("owner"))
+	}
+	if parsed.String("in") != "C123" {
+		t.Errorf("expected in to be C123, got %s", parsed.String("in"))
+	}
+
+	parsed, err = parseParams(testParams, []string{"aws"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.String("state") != "success" || parsed.Duration("for") != time.Hour || parsed.Has("count") {
+		t.Errorf("expected defaults to be applied")
+	}
+
+	invalid := map[string][]string{
+		"missing required argument platform":     {},
+		"state must be one of success, failure":  {"aws", "pending"},
+		"count must be between 1 and 10, got 11": {"aws", "count=11"},
+		"count must be a whole number":           {"aws", "count=many"},
+		"count was provided more than once":      {"aws", "count=1", "count=2"},
+		"for must be a duration":                 {"aws", "for=soon"},
+		"owner must mention a Slack user":        {"aws", "owner=someone"},
+		"in must mention a Slack channel":        {"aws", "in=standup"},
+		"unknown option size":                    {"aws", "failure", "size=2"},
+		"unexpected argument \"extra\"":          {"aws", "failure", "extra"},
+	}
+	for expected, args := range invalid {
+		_, err := parseParams(testParams, args, nil)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q for %v, got %v", expected, args, err)
+		}
+	}
+}
+
+func TestParseParamsRemainder(t *testing.T) {
+	params := []data.Param{{Name: "question", Required: true, Remainder: true}}
+	parsed, err := parseParams(params, []string{"how", "do", "I", "set", "x=y?"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.String("question") != "how do I set x=y?" {
+		t.Errorf("unexpected question: %s", parsed.String("question"))
+	}
+}
+
+func TestParseQuotedOptions(t *testing.T) {
+	// the example in the README
+	args := tokenize(`audit user=<@U0123456789> command="ci pools cordon" since=7d limit=50`, true)
+	parsed, err := parseParams(AuditAttributes.Params, args[1:], args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.String("command") != "ci pools cordon" || parsed.String("user") != "U0123456789" || parsed.Int("limit") != 50 {
+		t.Errorf("unexpected values: %+v", parsed.Values())
+	}
+
+	expected := []string{"ci", "lease", "pools=vcenter-1 vcenter-2", "a quoted arg"}
+	if tokens := tokenize(`ci lease pools="vcenter-1 vcenter-2" "a quoted arg"`, true); strings.Join(tokens, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %q, got %q", expected, tokens)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	expected := "usage: `prow results <platform> [success|failure] [count=<1-10>] [for=<duration>] [owner=<@user>] [in=<#channel>]`"
+	if actual := usage([]string{"prow", "results"}, testParams); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
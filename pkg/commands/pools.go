@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	log "github.com/sirupsen/logrus"
+
 	"github.com/openshift-splat-team/splat-bot/data"
 	"github.com/openshift-splat-team/splat-bot/pkg/controllers"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
@@ -13,58 +15,108 @@ import (
 
 func init() {
 	AddCommand(PoolsAttributes)
+	AddCommand(PoolCordonAttributes)
+	AddCommand(PoolUncordonAttributes)
+}
+
+var poolNameParams = []data.Param{
+	{
+		Name:        "pool",
+		Required:    true,
+		Description: "name of the pool",
+	},
 }
 
 var PoolsAttributes = data.Attributes{
 	Commands:       []string{"ci", "pools"},
 	RequireMention: true,
-	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
-		result := ""
-		var err error
-		if len(args) > 2 {
-			switch args[2] {
-			case "uncordon":
-				if len(args) < 4 {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("requires the name or index of the pool")
-				}
-				err = controllers.SetPoolSchedulable(ctx, args[3], true)
-				if err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to uncordon pool: %w", err)
-				}
-				result = "pool is uncordoned"
-			case "cordon":
-				if len(args) < 4 {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("requires the name or index of the pool")
-				}
-				err = controllers.SetPoolSchedulable(ctx, args[3], false)
-				if err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to set pool unschedulable: %w", err)
-				}
-				result = "pool is cordoned"
-			case "status":
-				fallthrough
-			case "list":
-				fallthrough
-			default:
-				result, err := controllers.GetPoolStatus()
-				if err != nil {
-					return nil, fmt.Errorf("failed to fetch pool status: %w", err)
-				}
-				return []slack.MsgOption{result}, nil
-			}
+	Params: []data.Param{
+		{
+			Name:    "action",
+			Type:    data.ParamEnum,
+			Values:  []string{"list", "status"},
+			Default: "list",
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		result, err := controllers.GetPoolStatus()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch pool status: %w", err)
 		}
-
-		return util.StringToBlock(result, false), nil
+		return []slack.MsgOption{result}, nil
 	},
-	RequiredArgs: 0,
-	HelpMarkdown: "interact with vSphere CI pools: `ci pools list|uncordoned|cordoned <pool name>`",
+	Category:     data.CategoryCI,
+	HelpMarkdown: "interact with vSphere CI pools: `ci pools list|cordon|uncordon <pool name>`",
 	ShouldMatch: []string{
 		"ci pools list",
-		"ci pools schedulable pool-1",
-		"ci pools unschedulable pool-1",
+		"ci pools status",
 	},
 	ShouldntMatch: []string{
 		"jira create-with-summary PROJECT bug",
 		"jira create-with-summary PROJECT Todo",
 	},
 }
+
+// setPoolSchedulableAction returns an action which cordons or uncordons the pool named by the button's value and
+// refreshes the pool status it was clicked in.
+func setPoolSchedulableAction(actionID string, schedulable bool, command ...string) data.Action {
+	return data.Action{
+		ActionID:        actionID,
+		Commands:        command,
+		ReplaceOriginal: true,
+		Handler: func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+			if err := controllers.SetPoolSchedulable(ctx, action.Value, schedulable); err != nil {
+				_, postErr := client.PostEphemeral(callback.Channel.ID, callback.User.ID, util.StringToBlock(err.Error(), false)...)
+				if postErr != nil {
+					log.Warnf("failed to notify user: %v", postErr)
+				}
+				return nil, fmt.Errorf("failed to update pool %s: %w", action.Value, err)
+			}
+			result, err := controllers.GetPoolStatus()
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch pool status: %w", err)
+			}
+			return []slack.MsgOption{result}, nil
+		},
+	}
+}
+
+var PoolCordonAttributes = data.Attributes{
+	Commands:       []string{"ci", "pools", "cordon"},
+	RequireMention: true,
+	Params:         poolNameParams,
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		err := controllers.SetPoolSchedulable(ctx, args.String("pool"), false)
+		if err != nil {
+			return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to set pool unschedulable: %w", err)
+		}
+		return util.StringToBlock("pool is cordoned", false), nil
+	},
+	Actions: []data.Action{
+		setPoolSchedulableAction(controllers.PoolCordonActionID, false, "ci", "pools", "cordon"),
+	},
+	Category:      data.CategoryCI,
+	HelpMarkdown:  "stop scheduling leases to a vSphere CI pool: `ci pools cordon <pool name>`",
+	ShouldMatch:   []string{"ci pools cordon pool-1"},
+	ShouldntMatch: []string{"ci pools list", "ci pools uncordon pool-1"},
+}
+
+var PoolUncordonAttributes = data.Attributes{
+	Commands:       []string{"ci", "pools", "uncordon"},
+	RequireMention: true,
+	Params:         poolNameParams,
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		err := controllers.SetPoolSchedulable(ctx, args.String("pool"), true)
+		if err != nil {
+			return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to uncordon pool: %w", err)
+		}
+		return util.StringToBlock("pool is uncordoned", false), nil
+	},
+	Actions: []data.Action{
+		setPoolSchedulableAction(controllers.PoolUncordonActionID, true, "ci", "pools", "uncordon"),
+	},
+	Category:      data.CategoryCI,
+	HelpMarkdown:  "resume scheduling leases to a vSphere CI pool: `ci pools uncordon <pool name>`",
+	ShouldMatch:   []string{"ci pools uncordon pool-1"},
+	ShouldntMatch: []string{"ci pools list", "ci pools cordon pool-1"},
+}
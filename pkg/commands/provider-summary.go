@@ -72,8 +72,16 @@ func getFeedSummary(lastNDays int, provider string, additionalContext ...string)
 var ProviderSummaryAttributes = data.Attributes{
 	Commands:       []string{"provider-summary"},
 	RequireMention: true,
-	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
-		provider := strings.ToLower(args[1])
+	Params: []data.Param{
+		{
+			Name:     "provider",
+			Type:     data.ParamEnum,
+			Required: true,
+			Values:   []string{"aws", "vsphere", "gcp", "azure"},
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		provider := args.String("provider")
 
 		if _, exists := providers[provider]; !exists {
 			return nil, fmt.Errorf("%s is not a supported provider", provider)
@@ -87,7 +95,7 @@ var ProviderSummaryAttributes = data.Attributes{
 
 		return util.StringsToBlockUnfurl(summary, false, false), nil
 	},
-	RequiredArgs: 2,
+	Category:     data.CategoryLLM,
 	HelpMarkdown: "summarize RSS feeds for various providers: `provider-summary [aws|vsphere|gcp|azure]`",
 	ShouldMatch: []string{
 		"provider-summary aws",
@@ -38,17 +38,23 @@ var (
 var ProwGraphAttributes = data.Attributes{
 	Commands:       []string{"prow", "graph"},
 	RequireMention: true,
-	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+	Params: []data.Param{
+		{
+			Name:     "platform",
+			Required: true,
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
 		startProwRetrievalTimers()
 
-		results, err := createProwGraph(args[2])
+		results, err := createProwGraph(args.String("platform"))
 		if err != nil {
 			return nil, err
 		}
 
 		return util.StringToBlock(results, false), nil
 	},
-	RequiredArgs: 3,
+	Category:     data.CategoryCI,
 	HelpMarkdown: "retrieve prow results: `prow graph [platform]`",
 	ShouldMatch: []string{
 		"prow graph vsphere",
@@ -62,17 +68,40 @@ var ProwGraphAttributes = data.Attributes{
 var ProwAttributes = data.Attributes{
 	Commands:       []string{"prow", "results"},
 	RequireMention: true,
-	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+	Params: []data.Param{
+		{
+			Name:     "platform",
+			Required: true,
+		},
+		{
+			Name:     "version",
+			Required: true,
+		},
+		{
+			Name:     "state",
+			Type:     data.ParamEnum,
+			Required: true,
+			Values: []string{
+				string(prowv1.SuccessState),
+				string(prowv1.FailureState),
+				string(prowv1.AbortedState),
+				string(prowv1.ErrorState),
+				string(prowv1.PendingState),
+				string(prowv1.TriggeredState),
+			},
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
 		startProwRetrievalTimers()
 
-		results, err := queryProwResults(args[2], args[3], prowv1.ProwJobState(args[4]))
+		results, err := queryProwResults(args.String("platform"), args.String("version"), prowv1.ProwJobState(args.String("state")))
 		if err != nil {
 			return nil, err
 		}
 
 		return util.StringToBlock(results, false), nil
 	},
-	RequiredArgs: 5,
+	Category:     data.CategoryCI,
 	HelpMarkdown: "retrieve prow results: `prow results [platform] [version] [state]`",
 	ShouldMatch: []string{
 		"prow results vsphere 4.16 success",
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -21,6 +22,7 @@ import (
 	githubql "github.com/shurcooL/githubv4"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
+	"gopkg.in/yaml.v2"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/prstatus"
 )
@@ -32,6 +34,12 @@ const (
 
 var (
 	githubID string
+	// githubEnabled is set when the GitHub App key is found
+	githubEnabled bool
+
+	githubLoginsMu sync.Mutex
+	// githubLogins maps Slack user IDs to GitHub logins
+	githubLogins = map[string]string{}
 
 	boldStyle = slack.RichTextSectionTextStyle{Bold: true}
 )
@@ -48,8 +56,15 @@ var PullRequestAttributes = data.Attributes{
 
 		return generateOutput(args, prList)
 	},
-	AllowNonSplatUsers:  true,
-	RequiredArgs:        2,
+	AllowNonSplatUsers: true,
+	Params: []data.Param{
+		{
+			Name:        "user",
+			Required:    true,
+			Description: "GitHub login of the user whose pull requests are listed",
+		},
+	},
+	Category:            data.CategoryGitHub,
 	HelpMarkdown:        "retrieve list of pull requests open for the specified user: `pull-requests [user]`",
 	ResponseIsEphemeral: true,
 	RespondInChannel:    true,
@@ -74,8 +89,15 @@ var PullRequestAssignedAttributes = data.Attributes{
 
 		return generateOutput(args, prList)
 	},
-	AllowNonSplatUsers:  true,
-	RequiredArgs:        2,
+	AllowNonSplatUsers: true,
+	Params: []data.Param{
+		{
+			Name:        "user",
+			Required:    true,
+			Description: "GitHub login of the user whose assigned pull requests are listed",
+		},
+	},
+	Category:            data.CategoryGitHub,
 	HelpMarkdown:        "retrieve list of pull requests opened that are assigned to the specified user: `pull-requests-assigned [user]`",
 	ResponseIsEphemeral: true,
 	RespondInChannel:    true,
@@ -108,10 +130,37 @@ func init() {
 		log.Infof("Skipping adding of knowledge-based actions.")
 		return
 	}
+	githubEnabled = true
 	AddCommand(PullRequestAttributes)
 	AddCommand(PullRequestAssignedAttributes)
 }
 
+// loadGithubLogins loads the GitHub logins of Slack users from a YAML file which maps Slack user IDs to GitHub
+// logins, such as `U01234567: rvanderp3`.
+func loadGithubLogins(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read GitHub logins %s: %v", path, err)
+	}
+	logins := map[string]string{}
+	if err := yaml.Unmarshal(content, &logins); err != nil {
+		return fmt.Errorf("unable to parse GitHub logins %s: %v", path, err)
+	}
+
+	githubLoginsMu.Lock()
+	defer githubLoginsMu.Unlock()
+	githubLogins = logins
+	return nil
+}
+
+// getGithubLogin returns the GitHub login of the Slack user. false is returned if the login is not known.
+func getGithubLogin(user string) (string, bool) {
+	githubLoginsMu.Lock()
+	defer githubLoginsMu.Unlock()
+	login, ok := githubLogins[user]
+	return login, ok && len(login) > 0
+}
+
 func generateOutput(args []string, prList []prstatus.PullRequest) ([]slack.MsgOption, error) {
 
 	var messageBlocks []slack.Block
@@ -194,9 +243,7 @@ func generateOutput(args []string, prList []prstatus.PullRequest) ([]slack.MsgOp
 	lineReturnSection := slack.NewRichTextSection(lineReturn)
 	messageBlocks = append(messageBlocks, slack.NewRichTextBlock("", lineReturnSection))
 
-	closeText := slack.NewTextBlockObject("plain_text", "Close", true, false)
-	closeButton := slack.NewButtonBlockElement("", "", closeText)
-	closeActionBlock := slack.NewActionBlock("", closeButton)
+	closeActionBlock := slack.NewActionBlock("", util.NewCloseButton())
 	messageBlocks = append(messageBlocks, closeActionBlock)
 
 	log.Printf("Number of blocks: %d", len(messageBlocks))
@@ -362,8 +409,12 @@ func QueryPullRequests(ctx context.Context, ghc GithubQuerier, query string) ([]
 }
 
 func fetchPullRequests(args []string) ([]prstatus.PullRequest, error) {
-	var prList []prstatus.PullRequest
+	return searchPullRequests(context.TODO(), ConstructSearchQuery(args))
+}
 
+// searchPullRequests returns the pull requests matching a GitHub search query such as
+// `is:pr state:open author:rvanderp3`.
+func searchPullRequests(ctx context.Context, query string) ([]prstatus.PullRequest, error) {
 	gitToken, err := getGithubToken()
 	if err != nil {
 		return nil, err
@@ -387,8 +438,7 @@ func fetchPullRequests(args []string) ([]prstatus.PullRequest, error) {
 		log.Debugf("Error creating github client: %v\n", err)
 		return nil, err
 	}
-	query := ConstructSearchQuery(args)
-	prList, err = QueryPullRequests(context.TODO(), githubClient, query)
+	prList, err := QueryPullRequests(ctx, githubClient, query)
 	if err != nil {
 		log.Debugf("Failed to get PRs: %v\n", err)
 		return nil, err
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/schedule"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// reminderInterval is how often reminders are checked to see if they are due
+const reminderInterval = 30 * time.Second
+
+var (
+	reminderMu sync.Mutex
+	// reminders are kept in memory until Initialize opens the store configured by REMINDER_STORE_PATH
+	reminders, _ = schedule.OpenReminders("", clock.RealClock{})
+)
+
+func getReminders() *schedule.ReminderStore {
+	reminderMu.Lock()
+	defer reminderMu.Unlock()
+	return reminders
+}
+
+func setReminders(store *schedule.ReminderStore) {
+	reminderMu.Lock()
+	defer reminderMu.Unlock()
+	reminders = store
+}
+
+// initializeReminders opens the reminder store configured by REMINDER_STORE_PATH
+func initializeReminders() error {
+	path := os.Getenv("REMINDER_STORE_PATH")
+	if len(path) == 0 {
+		log.Warnf("Reminders will be lost when the bot restarts.  Please configure REMINDER_STORE_PATH if you wish to keep them.")
+		return nil
+	}
+	store, err := schedule.OpenReminders(path, clock.RealClock{})
+	if err != nil {
+		return err
+	}
+	setReminders(store)
+	log.Infof("loaded %d reminders from %s", len(store.List("")), path)
+	return nil
+}
+
+// userLocation returns the timezone of the user's Slack profile. UTC is returned if the timezone is not known.
+func userLocation(client util.SlackClientInterface, user string) *time.Location {
+	info, err := client.GetUserInfo(user)
+	if err != nil {
+		log.Warnf("unable to get the timezone of %s: %v", user, err)
+		return time.UTC
+	}
+	if len(info.TZ) > 0 {
+		if location, err := time.LoadLocation(info.TZ); err == nil {
+			return location
+		}
+	}
+	if info.TZOffset != 0 {
+		return time.FixedZone(info.TZLabel, info.TZOffset)
+	}
+	return time.UTC
+}
+
+// sendReminder posts the reminder in its thread or in a DM to the user being reminded
+func sendReminder(client util.SlackClientInterface, reminder schedule.Reminder) error {
+	var text string
+	switch {
+	case len(reminder.Thread) > 0:
+		text = fmt.Sprintf(":alarm_clock: <@%s> a reminder about this thread: %s", reminder.User, reminder.Text)
+	case reminder.Creator == reminder.User:
+		text = fmt.Sprintf(":alarm_clock: you asked me to remind you: %s", reminder.Text)
+	default:
+		text = fmt.Sprintf(":alarm_clock: <@%s> asked me to remind you: %s", reminder.Creator, reminder.Text)
+	}
+	if len(reminder.Link) > 0 && len(reminder.Thread) == 0 {
+		text = fmt.Sprintf("%s (<%s|thread>)", text, reminder.Link)
+	}
+	if getReminders().Now().Sub(reminder.Due) > schedule.MissedRunGrace {
+		text = fmt.Sprintf("%s\n_this reminder was due %s but I was unavailable_", text, slackDate(reminder.Due))
+	}
+
+	options := util.StringToBlock(text, false)
+	channel := reminder.Channel
+	if len(reminder.Thread) > 0 {
+		options = append(options, slack.MsgOptionTS(reminder.Thread))
+	} else {
+		var err error
+		channel, err = getDMChannelIDByUser(client, reminder.User)
+		if err != nil {
+			return err
+		}
+	}
+	if _, _, err := client.PostMessage(channel, options...); err != nil {
+		return fmt.Errorf("unable to post reminder: %v", err)
+	}
+	return nil
+}
+
+// describeReminder describes the reminder to the user who asked for it
+func describeReminder(reminder schedule.Reminder, user string) string {
+	var who string
+	switch {
+	case len(reminder.Thread) > 0:
+		who = fmt.Sprintf("<%s|this thread>", reminder.Link)
+	case reminder.User == user:
+		who = "you"
+	default:
+		who = fmt.Sprintf("<@%s>", reminder.User)
+	}
+	return fmt.Sprintf("*%s* %s for %s: %s", reminder.ID, slackDate(reminder.Due), who, reminder.Text)
+}
+
+var RemindAttributes = data.Attributes{
+	Commands:       []string{"remind"},
+	RequireMention: true,
+	Params: []data.Param{
+		{
+			Name:        "who",
+			Required:    true,
+			Description: "`me`, a user such as @user, or `thread` to nudge the thread the command is sent in",
+		},
+		{
+			Name:        "reminder",
+			Required:    true,
+			Remainder:   true,
+			Description: "when followed by what to remind about. for example `in 2h check the nightly runs` or `tomorrow 9am review the PR`",
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		reminder := schedule.Reminder{
+			Creator: evt.User,
+			User:    evt.User,
+			Link:    util.GetThreadUrl(evt),
+		}
+		who := args.String("who")
+		switch {
+		case strings.EqualFold(who, "me"):
+		case strings.EqualFold(who, "thread"):
+			if len(evt.ThreadTimeStamp) == 0 {
+				return util.StringToBlock("`remind thread` must be sent in the thread to nudge", false), nil
+			}
+			reminder.Channel = evt.Channel
+			reminder.Thread = evt.ThreadTimeStamp
+		case userMentionRegex.MatchString(who):
+			reminder.User = userMentionRegex.FindStringSubmatch(who)[1]
+		default:
+			return util.StringToBlock(fmt.Sprintf("I don't know who %q is. remind `me`, a user such as @user, or the `thread`", who), false), nil
+		}
+
+		// absolute times are in the timezone of the user who asked for the reminder
+		store := getReminders()
+		now := store.Now().In(userLocation(client, evt.User))
+		due, rest, err := schedule.ParseWhen(strings.Fields(args.String("reminder")), now)
+		if err != nil {
+			return util.StringToBlock(fmt.Sprintf("I don't know when to remind you. %v", err), false), nil
+		}
+		reminder.Text = strings.Join(rest, " ")
+		if len(reminder.Text) == 0 {
+			return util.StringToBlock("what should the reminder say? for example `remind me in 2h check the nightly runs`", false), nil
+		}
+		reminder.Due = due
+
+		added, err := store.Add(reminder)
+		if err != nil {
+			return util.StringToBlock(fmt.Sprintf("unable to add the reminder. %v", err), false), nil
+		}
+		return util.StringToBlock(fmt.Sprintf("ok, I'll remind %s", describeReminder(added, evt.User)), false), nil
+	},
+	Category:     data.CategoryGeneral,
+	HelpMarkdown: "remind yourself, someone else or a thread about something later: `remind me in 2h check the nightly runs`, `remind @user tomorrow 9am review the PR` or `remind thread friday 10am follow up`. times are in your Slack timezone",
+	ShouldMatch: []string{
+		"remind me in 2h check the nightly runs",
+		"remind <@U012345> tomorrow 9am review the PR",
+		"remind thread in 1d follow up",
+	},
+	ShouldntMatch: []string{
+		"reminder me in 2h",
+		"schedule list",
+	},
+}
+
+var RemindListAttributes = data.Attributes{
+	Commands:       []string{"remind", "list"},
+	RequireMention: true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		list := getReminders().List(evt.User)
+		if len(list) == 0 {
+			return util.StringToBlock("you have no reminders. add one with `remind me in 2h <text>`", false), nil
+		}
+		var lines []string
+		for _, reminder := range list {
+			lines = append(lines, fmt.Sprintf("- %s", describeReminder(reminder, evt.User)))
+		}
+		return util.StringToBlock(strings.Join(lines, "\n"), false), nil
+	},
+	ResponseIsEphemeral: true,
+	Category:            data.CategoryGeneral,
+	HelpMarkdown:        "list the reminders you created or which are for you: `remind list`",
+	ShouldMatch: []string{
+		"remind list",
+	},
+	ShouldntMatch: []string{
+		"remind me in 2h list",
+		"remind cancel 1",
+	},
+}
+
+var RemindCancelAttributes = data.Attributes{
+	Commands:       []string{"remind", "cancel"},
+	RequireMention: true,
+	Params: []data.Param{
+		{
+			Name:        "id",
+			Required:    true,
+			Description: "ID of the reminder shown by `remind list`",
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		store := getReminders()
+		reminder, err := store.Get(args.String("id"))
+		if err != nil {
+			return util.StringToBlock(fmt.Sprintf("unable to cancel reminder %s. %v", args.String("id"), err), false), nil
+		}
+		if reminder.Creator != evt.User && reminder.User != evt.User {
+			if err := authorizeAdmin(client, evt.User); err != nil {
+				return util.StringToBlock(fmt.Sprintf("only <@%s> or an admin may cancel reminder %s", reminder.Creator, reminder.ID), false), nil
+			}
+		}
+		if err := store.Delete(reminder.ID); err != nil {
+			return util.StringToBlock(fmt.Sprintf("unable to cancel reminder %s. %v", reminder.ID, err), false), nil
+		}
+		return util.StringToBlock(fmt.Sprintf("cancelled reminder %s: %s", reminder.ID, reminder.Text), false), nil
+	},
+	Category:     data.CategoryGeneral,
+	HelpMarkdown: "cancel a reminder you created or which is for you: `remind cancel <id>`",
+	ShouldMatch: []string{
+		"remind cancel 1",
+	},
+	ShouldntMatch: []string{
+		"remind list",
+	},
+}
+
+func init() {
+	AddCommand(RemindAttributes)
+	AddCommand(RemindListAttributes)
+	AddCommand(RemindCancelAttributes)
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/schedule"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// withReminders replaces the reminder store with an empty in-memory store for the duration of a test
+func withReminders(t *testing.T, clock *fakeClock) *schedule.ReminderStore {
+	store, err := schedule.OpenReminders("", clock)
+	if err != nil {
+		t.Fatalf("unable to open reminder store: %v", err)
+	}
+	saved := getReminders()
+	setReminders(store)
+	t.Cleanup(func() {
+		setReminders(saved)
+	})
+	return store
+}
+
+func TestRemindCommands(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("timezone data is not available: %v", err)
+	}
+	os.Setenv("SPLAT_BOT_USER_ID", SPLAT_BOT_USER_ID)
+	// a Wednesday at 10:00 in New York
+	clock := &fakeClock{now: time.Date(2024, 5, 15, 14, 0, 0, 0, time.UTC)}
+	store := withReminders(t, clock)
+	withAttributes(t, RemindAttributes, RemindListAttributes, RemindCancelAttributes)
+
+	client := util.NewFakeClient()
+	client.Users["U1"] = slack.User{TZ: "America/New_York"}
+	seq := 0
+	send := func(user, text, thread string) string {
+		t.Helper()
+		client.Reset()
+		seq++
+		event := threadMessage(fmt.Sprintf("<@%s> %s", SPLAT_BOT_USER_ID, text), user, thread, seq)
+		if len(thread) == 0 {
+			event = buildAppMentionEvent(fmt.Sprintf("<@%s> %s", SPLAT_BOT_USER_ID, text), user, "C1", false)
+		}
+		if err := Handler(context.TODO(), client, event); err != nil {
+			t.Fatalf("unexpected error for %q: %v", text, err)
+		}
+		message, ok := client.LastMessage()
+		if !ok {
+			t.Fatalf("expected a response to %q", text)
+		}
+		return message.Render()
+	}
+
+	if response := send("U1", "remind me in 2h check the nightly runs", ""); !strings.Contains(response, "ok, I'll remind *1* <!date^1715788800^") || !strings.Contains(response, "for you: check the nightly runs") {
+		t.Errorf("expected a reminder in 2 hours, got %q", response)
+	}
+	// 9am is in the timezone of U1
+	if response := send("U1", "remind <@U2> tomorrow 9am review the PR", ""); !strings.Contains(response, "*2* <!date^1715864400^") || !strings.Contains(response, "for <@U2>: review the PR") {
+		t.Errorf("expected a reminder for U2 at 9:00 in New York, got %q", response)
+	}
+	if response := send("U1", "remind thread at 11am follow up", "1700000000.000100"); !strings.Contains(response, "*3* <!date^1715785200^") || !strings.Contains(response, "for <https://redhat-internal.slack.com/archives/C1/p1700000000000100|this thread>: follow up") {
+		t.Errorf("expected a reminder for the thread, got %q", response)
+	}
+	invalid := map[string]string{
+		"remind me check the nightly runs": "I don't know when to remind you",
+		"remind me in 2h":                  "what should the reminder say?",
+		"remind thread in 2h follow up":    "`remind thread` must be sent in the thread to nudge",
+		"remind everyone in 2h follow up":  "I don't know who \"everyone\" is",
+		"remind me today 9am standup":      "has already passed",
+	}
+	for text, expected := range invalid {
+		if response := send("U1", text, ""); !strings.Contains(response, expected) {
+			t.Errorf("expected %q to be rejected with %q, got %q", text, expected, response)
+		}
+	}
+
+	if response := send("U2", "remind list", ""); !strings.Contains(response, "- *2*") || strings.Contains(response, "*1*") {
+		t.Errorf("expected U2 to only see the reminder for them, got %q", response)
+	}
+	if response := send("U3", "remind cancel 2", ""); !strings.Contains(response, "only <@U1> or an admin may cancel reminder 2") {
+		t.Errorf("expected other users to not be able to cancel the reminder, got %q", response)
+	}
+	if response := send("U2", "remind cancel 2", ""); !strings.Contains(response, "cancelled reminder 2: review the PR") {
+		t.Errorf("expected the reminded user to cancel the reminder, got %q", response)
+	}
+
+	// the reminders are sent when they are due
+	client.Reset()
+	clock.now = clock.now.Add(2 * time.Hour)
+	for _, reminder := range store.Due(clock.now) {
+		if err := sendReminder(client, reminder); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	messages := client.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("expected the thread and DM reminders to be sent, got %+v", messages)
+	}
+	if messages[0].Channel != "C1" || messages[0].ThreadTimestamp != "1700000000.000100" || !strings.Contains(messages[0].Render(), "<@U1> a reminder about this thread: follow up") {
+		t.Errorf("expected the thread to be nudged, got %+v", messages[0])
+	}
+	if messages[1].Channel != "DU1" || !strings.Contains(messages[1].Render(), "you asked me to remind you: check the nightly runs") {
+		t.Errorf("expected a DM reminder, got %+v", messages[1])
+	}
+	// the thread reminder was due an hour before the reminders were checked
+	if !strings.Contains(messages[0].Render(), "_this reminder was due <!date^1715785200^") || strings.Contains(messages[1].Render(), "unavailable") {
+		t.Errorf("expected only the thread reminder to be late, got %q and %q", messages[0].Render(), messages[1].Render())
+	}
+}
@@ -0,0 +1,255 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/schedule"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// scheduleInterval is how often schedules are checked for runs which are due
+const scheduleInterval = 30 * time.Second
+
+var (
+	scheduleMu sync.Mutex
+	// schedules are kept in memory until Initialize opens the store configured by SCHEDULE_STORE_PATH
+	schedules, _ = schedule.Open("", clock.RealClock{})
+)
+
+func getSchedules() *schedule.Store {
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+	return schedules
+}
+
+func setSchedules(store *schedule.Store) {
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+	schedules = store
+}
+
+// initializeSchedules opens the schedule store configured by SCHEDULE_STORE_PATH
+func initializeSchedules() error {
+	path := os.Getenv("SCHEDULE_STORE_PATH")
+	if len(path) == 0 {
+		log.Warnf("Schedules will be lost when the bot restarts.  Please configure SCHEDULE_STORE_PATH if you wish to keep them.")
+		return nil
+	}
+	store, err := schedule.Open(path, clock.RealClock{})
+	if err != nil {
+		return err
+	}
+	setSchedules(store)
+	log.Infof("loaded %d schedules from %s", len(store.List()), path)
+	return nil
+}
+
+// StartScheduler runs the commands of schedules and sends reminders when they are due until ctx is done. Responses
+// and reminders are posted with client.
+func StartScheduler(ctx context.Context, client util.SlackClientInterface) {
+	go getSchedules().Run(ctx, scheduleInterval, func(ctx context.Context, s schedule.Schedule) {
+		if err := runSchedule(ctx, client, s); err != nil {
+			log.Warnf("schedule %s failed: %v", s.ID, err)
+		}
+	})
+	go getReminders().Run(ctx, reminderInterval, func(ctx context.Context, reminder schedule.Reminder) error {
+		return sendReminder(client, reminder)
+	})
+}
+
+// runSchedule runs the command of the schedule on behalf of its creator and posts the response in the schedule's
+// channel. The creator's permissions are checked on every run so that a schedule stops working when they are revoked.
+func runSchedule(ctx context.Context, client util.SlackClientInterface, s schedule.Schedule) error {
+	attribute, ok := findAttribute(s.Command, true)
+	if !ok {
+		return fmt.Errorf("command `%s` is no longer available", strings.Join(s.Command, " "))
+	}
+	msg := &slackevents.MessageEvent{
+		Type:    string(slackevents.Message),
+		User:    s.Creator,
+		Channel: s.Channel,
+		Text:    strings.Join(s.Command, " "),
+	}
+	response, err := invokeAttributeFrom(ctx, auditSourceSchedule, client, msg, attribute, s.Command)
+	if err != nil {
+		return err
+	}
+	if len(response) == 0 {
+		return nil
+	}
+	if _, _, err := client.PostMessage(s.Channel, response...); err != nil {
+		return fmt.Errorf("unable to post response: %v", err)
+	}
+	return nil
+}
+
+// validateScheduledCommand checks that the command can be scheduled in the channel by the user
+func validateScheduledCommand(client util.SlackClientInterface, user, channel string, command []string) error {
+	attribute, ok := findAttribute(command, true)
+	if !ok {
+		return fmt.Errorf("I don't know the command `%s`", strings.Join(command, " "))
+	}
+	switch {
+	case attribute.Commands[0] == "schedule":
+		return errors.New("schedules can not manage other schedules")
+	case attribute.Wizard != nil:
+		return errors.New("commands which ask questions can not be scheduled")
+	case attribute.MustBeInThread:
+		return errors.New("commands which must be run in a thread can not be scheduled")
+	case len(attribute.RequireInChannel) > 0 && !slices.Contains(attribute.RequireInChannel, channel):
+		return fmt.Errorf("`%s` can not be run in <#%s>", strings.Join(attribute.Commands, " "), channel)
+	}
+	if len(attribute.Params) > 0 {
+		if _, err := parseParams(attribute.Params, command[min(len(attribute.Commands), len(command)):], command); err != nil {
+			return errors.New(usageError(err, attribute.Commands, attribute.Params, attribute.HelpMarkdown))
+		}
+	}
+	if err := authorizeAttribute(client, user, channel, command, attribute); err != nil {
+		return fmt.Errorf("you may not run `%s` in <#%s>. %v", strings.Join(attribute.Commands, " "), channel, err)
+	}
+	return nil
+}
+
+func describeSchedule(s schedule.Schedule) string {
+	timezone := s.Timezone
+	if len(timezone) == 0 {
+		timezone = "UTC"
+	}
+	return fmt.Sprintf("*%s* `%s` in <#%s> at `%s` (%s) by <@%s>. next run %s", s.ID, strings.Join(s.Command, " "), s.Channel, s.Cron, timezone, s.Creator, slackDate(s.Next))
+}
+
+var ScheduleAddAttributes = data.Attributes{
+	Commands:       []string{"schedule", "add"},
+	RequireMention: true,
+	Params: []data.Param{
+		{
+			Name:        "channel",
+			Type:        data.ParamChannel,
+			Required:    true,
+			Description: "channel the output is posted in",
+		},
+		{
+			Name:        "cron",
+			Required:    true,
+			Description: "when the command runs as a quoted cron expression such as \"0 9 * * 1-5\", or a macro such as @daily",
+		},
+		{
+			Name:        "tz",
+			KeyValue:    true,
+			Description: "timezone the cron expression is evaluated in, such as America/New_York. defaults to UTC",
+		},
+		{
+			Name:        "command",
+			Required:    true,
+			Remainder:   true,
+			Description: "command to run, such as ci pools list",
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		command := args.Tokens("command")
+		if err := validateScheduledCommand(client, evt.User, args.String("channel"), command); err != nil {
+			return util.StringToBlock(fmt.Sprintf("unable to schedule the command. %v", err), false), nil
+		}
+		added, err := getSchedules().Add(schedule.Schedule{
+			Creator:  evt.User,
+			Channel:  args.String("channel"),
+			Cron:     args.String("cron"),
+			Timezone: args.String("tz"),
+			Command:  command,
+		})
+		if err != nil {
+			return util.StringToBlock(fmt.Sprintf("unable to schedule the command. %v", err), false), nil
+		}
+		return util.StringToBlock(fmt.Sprintf("scheduled %s", describeSchedule(added)), false), nil
+	},
+	Category:     data.CategoryGeneral,
+	HelpMarkdown: "run a command on a schedule and post its output in a channel: `schedule add #channel \"0 9 * * 1-5\" ci pools list`",
+	ShouldMatch: []string{
+		"schedule add <#C012345|standup> \"0 9 * * 1-5\" ci pools list",
+		"schedule add <#C012345|standup> @weekly tz=Europe/London provider-summary vsphere",
+	},
+	ShouldntMatch: []string{
+		"schedule list",
+		"schedule delete 1",
+	},
+}
+
+var ScheduleListAttributes = data.Attributes{
+	Commands:       []string{"schedule", "list"},
+	RequireMention: true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		list := getSchedules().List()
+		if len(list) == 0 {
+			return util.StringToBlock("there are no schedules. add one with `schedule add`", false), nil
+		}
+		var lines []string
+		for _, s := range list {
+			lines = append(lines, fmt.Sprintf("- %s", describeSchedule(s)))
+		}
+		return util.StringToBlock(strings.Join(lines, "\n"), false), nil
+	},
+	AllowNonSplatUsers: true,
+	Category:           data.CategoryGeneral,
+	HelpMarkdown:       "list the scheduled commands and when they next run: `schedule list`",
+	ShouldMatch: []string{
+		"schedule list",
+	},
+	ShouldntMatch: []string{
+		"schedule delete 1",
+	},
+}
+
+var ScheduleDeleteAttributes = data.Attributes{
+	Commands:       []string{"schedule", "delete"},
+	RequireMention: true,
+	Params: []data.Param{
+		{
+			Name:        "id",
+			Required:    true,
+			Description: "ID of the schedule shown by `schedule list`",
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		store := getSchedules()
+		s, err := store.Get(args.String("id"))
+		if err != nil {
+			return util.StringToBlock(fmt.Sprintf("unable to delete schedule %s. %v", args.String("id"), err), false), nil
+		}
+		if s.Creator != evt.User {
+			if err := authorizeAdmin(client, evt.User); err != nil {
+				return util.StringToBlock(fmt.Sprintf("only <@%s> or an admin may delete schedule %s", s.Creator, s.ID), false), nil
+			}
+		}
+		if err := store.Delete(s.ID); err != nil {
+			return util.StringToBlock(fmt.Sprintf("unable to delete schedule %s. %v", s.ID, err), false), nil
+		}
+		return util.StringToBlock(fmt.Sprintf("deleted schedule %s which ran `%s` in <#%s>", s.ID, strings.Join(s.Command, " "), s.Channel), false), nil
+	},
+	Category:     data.CategoryGeneral,
+	HelpMarkdown: "delete a schedule you created: `schedule delete <id>`",
+	ShouldMatch: []string{
+		"schedule delete 1",
+	},
+	ShouldntMatch: []string{
+		"schedule list",
+	},
+}
+
+func init() {
+	AddCommand(ScheduleAddAttributes)
+	AddCommand(ScheduleListAttributes)
+	AddCommand(ScheduleDeleteAttributes)
+}
@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
+	"github.com/openshift-splat-team/splat-bot/pkg/schedule"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// withSchedules replaces the schedule store with an empty in-memory store for the duration of a test
+func withSchedules(t *testing.T, clock *fakeClock) *schedule.Store {
+	store, err := schedule.Open("", clock)
+	if err != nil {
+		t.Fatalf("unable to open schedule store: %v", err)
+	}
+	saved := getSchedules()
+	setSchedules(store)
+	t.Cleanup(func() {
+		setSchedules(saved)
+	})
+	return store
+}
+
+func TestScheduleCommands(t *testing.T) {
+	os.Setenv("SPLAT_BOT_USER_ID", SPLAT_BOT_USER_ID)
+	store := withSchedules(t, &fakeClock{now: time.Date(2024, 5, 15, 8, 0, 0, 0, time.UTC)})
+	withAttributes(t, ScheduleAddAttributes, ScheduleListAttributes, ScheduleDeleteAttributes, LeaseWizardAttributes,
+		data.Attributes{
+			Commands:           []string{"ci", "pools", "list"},
+			AllowNonSplatUsers: true,
+			Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+				return util.StringToBlock("pool-1 is healthy", false), nil
+			},
+		},
+	)
+
+	client := util.NewFakeClient()
+	send := func(user, text string) string {
+		t.Helper()
+		client.Reset()
+		event := buildAppMentionEvent(fmt.Sprintf("<@%s> %s", SPLAT_BOT_USER_ID, text), user, "C1", false)
+		if err := Handler(context.TODO(), client, event); err != nil {
+			t.Fatalf("unexpected error for %q: %v", text, err)
+		}
+		message, ok := client.LastMessage()
+		if !ok {
+			t.Fatalf("expected a response to %q", text)
+		}
+		return message.Render()
+	}
+
+	response := send("U1", `schedule add <#C2|standup> "0 9 * * 1-5" tz=America/New_York ci pools list`)
+	if !strings.Contains(response, "scheduled *1* `ci pools list` in <#C2> at `0 9 * * 1-5` (America/New_York) by <@U1>. next run <!date^") {
+		t.Errorf("expected the schedule to be added, got %q", response)
+	}
+	invalid := map[string]string{
+		`schedule add <#C2|standup> "0 9 * *" ci pools list`:         "must have 5 fields",
+		`schedule add <#C2|standup> @daily ci unknown`:               "I don't know the command `ci unknown`",
+		`schedule add <#C2|standup> @daily ci lease wizard`:          "commands which ask questions can not be scheduled",
+		`schedule add <#C2|standup> @daily schedule list`:            "schedules can not manage other schedules",
+		`schedule add standup @daily ci pools list`:                  "channel must mention a Slack channel",
+		`schedule add <#C2|standup> @daily tz=Nowhere ci pools list`: "unknown timezone \"Nowhere\"",
+	}
+	for text, expected := range invalid {
+		if response := send("U1", text); !strings.Contains(response, expected) {
+			t.Errorf("expected %q to be rejected with %q, got %q", text, expected, response)
+		}
+	}
+	if schedules := store.List(); len(schedules) != 1 {
+		t.Fatalf("expected only the valid schedule to be added, got %+v", schedules)
+	}
+
+	// quoted arguments of the command are kept
+	send("U1", `schedule add <#C2|standup> @daily ci pools list owner="a b"`)
+	if added, err := store.Get("2"); err != nil || !reflect.DeepEqual(added.Command, []string{"ci", "pools", "list", "owner=a b"}) {
+		t.Fatalf("expected the tokens of the command to be kept, got %+v: %v", added.Command, err)
+	}
+	send("U1", "schedule delete 2")
+
+	if response := send("U2", "schedule list"); !strings.Contains(response, "- *1* `ci pools list` in <#C2>") {
+		t.Errorf("expected the schedule to be listed, got %q", response)
+	}
+	if response := send("U2", "schedule delete 1"); !strings.Contains(response, "only <@U1> or an admin may delete schedule 1") {
+		t.Errorf("expected other users to not be able to delete the schedule, got %q", response)
+	}
+	if response := send("U1", "schedule delete 1"); !strings.Contains(response, "deleted schedule 1") {
+		t.Errorf("expected the creator to delete the schedule, got %q", response)
+	}
+	if response := send("U1", "schedule list"); !strings.Contains(response, "there are no schedules") {
+		t.Errorf("expected no schedules to be listed, got %q", response)
+	}
+}
+
+func TestRunSchedule(t *testing.T) {
+	auditLog := withAuditLog(t)
+	withAttributes(t, data.Attributes{
+		Commands:           []string{"ci", "pools", "list"},
+		AllowNonSplatUsers: true,
+		Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+			return util.StringToBlock(fmt.Sprintf("pools requested by <@%s>", evt.User), false), nil
+		},
+	})
+
+	client := util.NewFakeClient()
+	s := schedule.Schedule{ID: "1", Creator: "U1", Channel: "C2", Cron: "@daily", Command: []string{"ci", "pools", "list"}}
+	if err := runSchedule(context.TODO(), client, s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	message, ok := client.LastMessage()
+	if !ok || message.Channel != "C2" || message.Ephemeral || !strings.Contains(message.Render(), "pools requested by <@U1>") {
+		t.Errorf("expected the response to be posted in C2 on behalf of U1, got %+v", message)
+	}
+
+	records, err := auditLog.Query(audit.Filter{})
+	if err != nil {
+		t.Fatalf("unable to query audit log: %v", err)
+	}
+	if len(records) != 1 || records[0].Source != auditSourceSchedule || records[0].User != "U1" {
+		t.Errorf("expected the run to be audited as the creator's, got %+v", records)
+	}
+
+	s.Command = []string{"ci", "lease", "list"}
+	if err := runSchedule(context.TODO(), client, s); err == nil || !strings.Contains(err.Error(), "no longer available") {
+		t.Errorf("expected a schedule whose command was removed to fail, got %v", err)
+	}
+}
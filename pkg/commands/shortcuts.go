@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// AskAboutMessageCallbackID is the callback ID of the message shortcut which asks the bot about a message
+	AskAboutMessageCallbackID = "ask_splat_bot"
+	// SummarizeThreadCallbackID is the callback ID of the message shortcut which summarizes a thread
+	SummarizeThreadCallbackID = "summarize_thread"
+
+	summarizeThreadPrompt util.Prompt = "Summarize the following Slack conversation in a few sentences. Call out any decisions which were made and any questions which remain open:"
+)
+
+var (
+	shortcutMu sync.Mutex
+	shortcuts  = []data.Shortcut{}
+)
+
+func init() {
+	AddShortcut(JiraCreateFromMessageShortcut)
+	AddShortcut(JiraCreateShortcut)
+	AddShortcut(AskAboutMessageShortcut)
+	AddShortcut(SummarizeThreadShortcut)
+}
+
+// AddShortcut adds a handler for a message or global shortcut.
+func AddShortcut(shortcut data.Shortcut) {
+	shortcutMu.Lock()
+	defer shortcutMu.Unlock()
+	log.Printf("adding shortcut: %s", shortcut.CallbackID)
+	shortcuts = append(shortcuts, shortcut)
+}
+
+func getShortcuts() []data.Shortcut {
+	shortcutMu.Lock()
+	defer shortcutMu.Unlock()
+
+	newShortcuts := make([]data.Shortcut, len(shortcuts))
+
+	copy(newShortcuts, shortcuts)
+	return newShortcuts
+}
+
+// shortcutMessage returns the message a message shortcut was used on as a message event, which allows the
+// shortcut to reuse the handling of messages.
+func shortcutMessage(callback slack.InteractionCallback) *slackevents.MessageEvent {
+	return &slackevents.MessageEvent{
+		Type:            "message",
+		Channel:         callback.Channel.ID,
+		User:            callback.User.ID,
+		Text:            callback.Message.Text,
+		TimeStamp:       callback.Message.Timestamp,
+		ThreadTimeStamp: callback.Message.ThreadTimestamp,
+	}
+}
+
+// messagePermalink returns the permalink of the message a message shortcut was used on. An empty string is returned
+// if the permalink could not be retrieved.
+func messagePermalink(client util.SlackClientInterface, callback slack.InteractionCallback) string {
+	if len(callback.Message.Timestamp) == 0 {
+		return ""
+	}
+	permalink, err := client.GetPermalink(&slack.PermalinkParameters{
+		Channel: callback.Channel.ID,
+		Ts:      callback.Message.Timestamp,
+	})
+	if err != nil {
+		log.Warnf("unable to get permalink: %v", err)
+		return ""
+	}
+	return permalink
+}
+
+// linkTo returns a link to permalink with the given text or just the text if there is no permalink
+func linkTo(permalink, text string) string {
+	if len(permalink) == 0 {
+		return text
+	}
+	return fmt.Sprintf("<%s|%s>", permalink, text)
+}
+
+var AskAboutMessageShortcut = data.Shortcut{
+	CallbackID:         AskAboutMessageCallbackID,
+	Name:               "Ask SPLAT bot about this",
+	AllowNonSplatUsers: true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback) ([]slack.MsgOption, error) {
+		// handle the message as though the user had asked the bot about it
+		msg := shortcutMessage(callback)
+		msg.Text = fmt.Sprintf("%s %s", util.BotMention(), msg.Text)
+		permalink := messagePermalink(client, callback)
+		for _, candidate := range matchAttributes(msg, true) {
+			// only the knowledge answers. commands in someone else's message are not run on behalf of the user who
+			// asked about it.
+			if len(candidate.attribute.Commands) > 0 {
+				continue
+			}
+			response, err := invokeAttribute(ctx, client, msg, candidate.attribute, candidate.args)
+			if err != nil {
+				return response, err
+			}
+			if len(response) > 0 {
+				return util.PrependContext(fmt.Sprintf("about %s:", linkTo(permalink, "this message")), response)
+			}
+		}
+		return util.StringToBlock(fmt.Sprintf("I don't know anything about %s yet. Ask me for `help` to see everything I can do.",
+			linkTo(permalink, "that message")), false), nil
+	},
+	Category:     data.CategoryKnowledge,
+	HelpMarkdown: "ask the bot about a message: choose *Ask SPLAT bot about this* from the message's menu",
+}
+
+var SummarizeThreadShortcut = data.Shortcut{
+	CallbackID: SummarizeThreadCallbackID,
+	Name:       "Summarize thread",
+	Callback: func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback) ([]slack.MsgOption, error) {
+		msg := shortcutMessage(callback)
+		if len(msg.ThreadTimeStamp) == 0 {
+			msg.ThreadTimeStamp = msg.TimeStamp
+		}
+		summary, err := util.HandlePrompt(ctx, summarizeThreadPrompt, client, msg)
+		if err != nil {
+			return util.StringToBlock(fmt.Sprintf("unable to summarize the thread: %v", err), false), err
+		}
+		return util.StringToBlock(fmt.Sprintf("Summary of %s:\n%s", linkTo(messagePermalink(client, callback), "this thread"), summary), false), nil
+	},
+	Category:     data.CategoryLLM,
+	HelpMarkdown: "summarize a thread: choose *Summarize thread* from the menu of any message in the thread",
+}
+
+// ShortcutHandler dispatches message and global shortcuts to the registered shortcuts.
+func ShortcutHandler(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback) error {
+	var shortcut *data.Shortcut
+	for _, registered := range getShortcuts() {
+		if registered.CallbackID == callback.CallbackID {
+			shortcut = &registered
+			break
+		}
+	}
+	if shortcut == nil {
+		return fmt.Errorf("no shortcut registered for callback ID %s", callback.CallbackID)
+	}
+	log.Debugf("found shortcut: %s", shortcut.CallbackID)
+
+	// global shortcuts are not associated with a channel so responses are sent as a DM
+	channel := callback.Channel.ID
+	if len(channel) == 0 {
+		var err error
+		channel, err = getDMChannelIDByUser(client, callback.User.ID)
+		if err != nil {
+			return fmt.Errorf("unable to respond to shortcut: %v", err)
+		}
+	}
+
+	err := authorizeUser(client, callback.User.ID, channel, shortcut.Commands, shortcut.AllowNonSplatUsers)
+	if err != nil {
+		return denyUser(client, callback.User.ID, channel, err)
+	}
+
+	response, err := shortcut.Callback(ctx, client, callback)
+	if err != nil {
+		log.Warnf("failed processing shortcut: %v", err)
+	}
+	if len(response) == 0 {
+		return nil
+	}
+
+	threadTimeStamp := callback.Message.ThreadTimestamp
+	if len(threadTimeStamp) == 0 {
+		threadTimeStamp = callback.Message.Timestamp
+	}
+	if len(threadTimeStamp) > 0 {
+		response = append(response, slack.MsgOptionTS(threadTimeStamp))
+	}
+
+	if shortcut.RespondInThread || len(callback.Channel.ID) == 0 {
+		_, _, err = client.PostMessage(channel, response...)
+	} else {
+		_, err = client.PostEphemeral(channel, callback.User.ID, response...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed responding to shortcut: %v", err)
+	}
+	return nil
+}
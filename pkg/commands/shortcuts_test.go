@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// withShortcuts replaces the registered shortcuts for the duration of a test
+func withShortcuts(t *testing.T, testShortcuts ...data.Shortcut) {
+	shortcutMu.Lock()
+	saved := shortcuts
+	shortcuts = []data.Shortcut{}
+	shortcutMu.Unlock()
+
+	for _, shortcut := range testShortcuts {
+		AddShortcut(shortcut)
+	}
+	t.Cleanup(func() {
+		shortcutMu.Lock()
+		shortcuts = saved
+		shortcutMu.Unlock()
+	})
+}
+
+func TestShortcutHandler(t *testing.T) {
+	var received *slack.InteractionCallback
+	withShortcuts(t, data.Shortcut{
+		CallbackID:         "echo",
+		AllowNonSplatUsers: true,
+		Callback: func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback) ([]slack.MsgOption, error) {
+			received = &callback
+			return util.StringToBlock(shortcutMessage(callback).Text, false), nil
+		},
+	})
+
+	client := util.NewFakeClient()
+	callback := slack.InteractionCallback{
+		Type:       slack.InteractionTypeMessageAction,
+		CallbackID: "echo",
+		User:       slack.User{ID: "user"},
+		Channel:    slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "channel"}}},
+		Message:    slack.Message{Msg: slack.Msg{Text: "hello", Timestamp: "1.1"}},
+	}
+
+	if err := ShortcutHandler(context.TODO(), client, callback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received == nil || received.Message.Text != "hello" {
+		t.Fatalf("expected the shortcut to receive the message")
+	}
+	message, _ := client.LastMessage()
+	if !message.Ephemeral || message.Channel != "channel" || message.User != "user" || message.ThreadTimestamp != "1.1" {
+		t.Fatalf("expected the response to be posted ephemerally in the thread, got %+v", message)
+	}
+	if message.Text != "hello" {
+		t.Fatalf("expected the response to echo the message, got %q", message.Text)
+	}
+
+	// global shortcuts respond in a DM
+	client.Reset()
+	callback.Type = slack.InteractionTypeShortcut
+	callback.Channel = slack.Channel{}
+	if err := ShortcutHandler(context.TODO(), client, callback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	message, _ = client.LastMessage()
+	if message.Ephemeral || message.Channel != "Duser" {
+		t.Fatalf("expected the response to be sent as a DM, got %+v", message)
+	}
+
+	callback.CallbackID = "unknown"
+	if err := ShortcutHandler(context.TODO(), client, callback); err == nil {
+		t.Fatalf("expected an unknown shortcut to be rejected")
+	}
+}
+
+func TestAskAboutMessageShortcut(t *testing.T) {
+	os.Setenv("SPLAT_BOT_USER_ID", SPLAT_BOT_USER_ID)
+	released := false
+	withAttributes(t,
+		data.Attributes{
+			Commands:           []string{"ci", "lease", "release"},
+			AllowNonSplatUsers: true,
+			Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+				released = true
+				return util.StringToBlock("released", false), nil
+			},
+		},
+		data.Attributes{
+			AllowNonSplatUsers: true,
+			MessageOfInterest: func(args []string, attribute data.Attributes, channel string) bool {
+				return true
+			},
+			Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+				if !strings.Contains(evt.Text, "proxy") {
+					return nil, nil
+				}
+				return []slack.MsgOption{slack.MsgOptionBlocks(slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "configure the proxy in install-config.yaml", false, false), nil, nil))}, nil
+			},
+		},
+	)
+
+	client := util.NewFakeClient()
+	ask := func(text string) string {
+		t.Helper()
+		callback := slack.InteractionCallback{
+			User:    slack.User{ID: "U1"},
+			Channel: slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}},
+			Message: slack.Message{Msg: slack.Msg{Text: text, Timestamp: "1.1"}},
+		}
+		response, err := AskAboutMessageShortcut.Callback(context.TODO(), client, callback)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return util.RenderMsgOptions(response...)
+	}
+
+	if response := ask("how do I set a proxy?"); !strings.Contains(response, "about <https://fake.slack.test/archives/C1/p11|this message>:\nconfigure the proxy") {
+		t.Errorf("expected the answer to link to the message, got:\n%s", response)
+	}
+	if response := ask("ci lease release"); !strings.Contains(response, "I don't know anything about") {
+		t.Errorf("expected the bot not to know about the message, got:\n%s", response)
+	}
+	if released {
+		t.Errorf("expected a command in the message not to be run on behalf of the user who asked about it")
+	}
+}
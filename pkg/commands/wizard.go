@@ -0,0 +1,317 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// defaultWizardTimeout is how long a wizard waits for an answer when the wizard does not set a Timeout
+	defaultWizardTimeout = 10 * time.Minute
+
+	wizardCancel = "cancel"
+	wizardSkip   = "skip"
+)
+
+var wizards = newWizardSessions(nil)
+
+// wizardSession is a wizard in progress in a thread
+type wizardSession struct {
+	mu      sync.Mutex
+	wizard  *data.Wizard
+	command []string
+	user    string
+	channel string
+	thread  string
+	step    int
+	answers *data.ParsedArgs
+	expires time.Time
+}
+
+// wizardSessions tracks the wizards in progress. Only one wizard may be in progress in a thread.
+type wizardSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*wizardSession
+	clock    cache.Clock
+}
+
+func newWizardSessions(clk cache.Clock) *wizardSessions {
+	if clk == nil {
+		clk = clock.RealClock{}
+	}
+	return &wizardSessions{
+		sessions: map[string]*wizardSession{},
+		clock:    clk,
+	}
+}
+
+func sessionKey(channel, thread string) string {
+	return fmt.Sprintf("%s/%s", channel, thread)
+}
+
+// timeout returns how long the wizard waits for an answer
+func timeout(wizard *data.Wizard) time.Duration {
+	if wizard.Timeout > 0 {
+		return wizard.Timeout
+	}
+	return defaultWizardTimeout
+}
+
+// wizardParams returns the params of a wizard's command without their defaults. The steps offer the defaults instead,
+// so a step is only skipped when its argument was given.
+func wizardParams(params []data.Param) []data.Param {
+	withoutDefaults := make([]data.Param, len(params))
+	for idx, param := range params {
+		param.Default = ""
+		withoutDefaults[idx] = param
+	}
+	return withoutDefaults
+}
+
+// start begins the wizard of the attribute in the thread of msg and returns the first question. Steps which were
+// answered by the arguments of the command are not asked, and the wizard finishes immediately if they answer every
+// step.
+func (w *wizardSessions) start(ctx context.Context, client util.SlackClientInterface, msg *slackevents.MessageEvent, attribute data.Attributes, args *data.ParsedArgs) []slack.MsgOption {
+	thread := msg.ThreadTimeStamp
+	if len(thread) == 0 {
+		thread = msg.TimeStamp
+	}
+	session := &wizardSession{
+		wizard:  attribute.Wizard,
+		command: attribute.Commands,
+		user:    msg.User,
+		channel: msg.Channel,
+		thread:  thread,
+		answers: args,
+		expires: w.clock.Now().Add(timeout(attribute.Wizard)),
+	}
+	session.skipAnswered()
+	if session.step >= len(session.wizard.Steps) {
+		log.Debugf("the arguments of %v answered every step", attribute.Commands)
+		return session.finish(ctx, client, msg)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.prune()
+	key := sessionKey(msg.Channel, thread)
+	if existing, ok := w.sessions[key]; ok {
+		return util.StringToBlock(fmt.Sprintf("`%s` is already in progress in this thread. reply `%s` to stop it.", strings.Join(existing.command, " "), wizardCancel), false)
+	}
+	w.sessions[key] = session
+	log.Debugf("started %v for %s in %s", attribute.Commands, msg.User, key)
+
+	return util.StringToBlock(fmt.Sprintf("answer the following questions by replying in this thread. reply `%s` at any time to stop.\n%s",
+		wizardCancel, session.prompt(ctx)), false)
+}
+
+// prune removes the sessions which have timed out. w.mu must be held.
+func (w *wizardSessions) prune() {
+	now := w.clock.Now()
+	for key, session := range w.sessions {
+		if now.After(session.expires) {
+			delete(w.sessions, key)
+		}
+	}
+}
+
+// lookup returns the session in the thread. The session is removed and expired is true if it has timed out.
+func (w *wizardSessions) lookup(channel, thread string) (session *wizardSession, expired bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	key := sessionKey(channel, thread)
+	session, ok := w.sessions[key]
+	if !ok {
+		return nil, false
+	}
+	if w.clock.Now().After(session.expires) {
+		delete(w.sessions, key)
+		return session, true
+	}
+	return session, false
+}
+
+func (w *wizardSessions) remove(session *wizardSession) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	key := sessionKey(session.channel, session.thread)
+	if w.sessions[key] == session {
+		delete(w.sessions, key)
+	}
+}
+
+// handle routes a reply to the wizard in progress in its thread. true is returned if the reply was handled by a
+// wizard. Replies from users other than the user who started the wizard are not handled.
+func (w *wizardSessions) handle(ctx context.Context, client util.SlackClientInterface, msg *slackevents.MessageEvent) (bool, error) {
+	if len(msg.ThreadTimeStamp) == 0 || len(msg.SubType) > 0 {
+		return false, nil
+	}
+	session, expired := w.lookup(msg.Channel, msg.ThreadTimeStamp)
+	if session == nil || session.user != msg.User {
+		return false, nil
+	}
+	if expired {
+		log.Debugf("%v for %s timed out", session.command, session.user)
+		return true, session.reply(client, fmt.Sprintf("`%s` timed out waiting for an answer. start it again with `%s`.",
+			strings.Join(session.command, " "), strings.Join(session.command, " ")))
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	answer := strings.TrimSpace(msg.Text)
+	if util.ContainsBotMention(answer) {
+		answer = strings.TrimSpace(strings.Replace(answer, util.BotMention(), "", 1))
+	}
+	if strings.EqualFold(answer, wizardCancel) {
+		w.remove(session)
+		return true, session.reply(client, fmt.Sprintf("`%s` cancelled.", strings.Join(session.command, " ")))
+	}
+
+	if err := session.answer(ctx, answer); err != nil {
+		return true, session.reply(client, fmt.Sprintf("%v\n%s", err, session.prompt(ctx)))
+	}
+	session.expires = w.clock.Now().Add(timeout(session.wizard))
+
+	if session.step < len(session.wizard.Steps) {
+		return true, session.reply(client, session.prompt(ctx))
+	}
+
+	w.remove(session)
+	response := session.finish(ctx, client, msg)
+	if len(response) == 0 {
+		return true, nil
+	}
+	_, _, err := client.PostMessage(session.channel, append(response, slack.MsgOptionTS(session.thread))...)
+	if err != nil {
+		return true, fmt.Errorf("failed responding to message: %v", err)
+	}
+	return true, nil
+}
+
+// skipAnswered advances past the steps which have already been answered
+func (s *wizardSession) skipAnswered() {
+	for s.step < len(s.wizard.Steps) && s.answers.Has(s.wizard.Steps[s.step].Param.Name) {
+		s.step++
+	}
+}
+
+// prompt returns the question asked by the current step
+func (s *wizardSession) prompt(ctx context.Context) string {
+	step := s.wizard.Steps[s.step]
+	question := step.Prompt
+	if len(question) == 0 {
+		question = step.Param.Description
+	}
+	if len(question) == 0 {
+		question = step.Param.Name
+	}
+
+	var hints []string
+	if step.Choices != nil {
+		choices, err := step.Choices(ctx)
+		if err != nil {
+			log.Warnf("unable to get the choices for %s: %v", step.Param.Name, err)
+		} else if len(choices) > 0 {
+			hints = append(hints, fmt.Sprintf("one of %s", strings.Join(choices, ", ")))
+		}
+	} else if step.Param.Type == data.ParamEnum || step.Param.Type == data.ParamInt || step.Param.Type == data.ParamDuration || step.Param.Type == data.ParamUser ||
+		step.Param.Type == data.ParamChannel {
+		hints = append(hints, paramPlaceholder(step.Param))
+	}
+	if !step.Param.Required {
+		if len(step.Param.Default) > 0 {
+			hints = append(hints, fmt.Sprintf("`%s` to use the default of %s", wizardSkip, step.Param.Default))
+		} else {
+			hints = append(hints, fmt.Sprintf("`%s` to leave it out", wizardSkip))
+		}
+	}
+
+	text := fmt.Sprintf("*%d/%d* %s", s.step+1, len(s.wizard.Steps), question)
+	if len(hints) > 0 {
+		text = fmt.Sprintf("%s (%s)", text, strings.Join(hints, ", "))
+	}
+	return text
+}
+
+// answer validates the answer to the current step and moves on to the next unanswered step
+func (s *wizardSession) answer(ctx context.Context, answer string) error {
+	step := s.wizard.Steps[s.step]
+	if strings.EqualFold(answer, wizardSkip) {
+		if step.Param.Required {
+			return fmt.Errorf("%s is required", step.Param.Name)
+		}
+		if len(step.Param.Default) > 0 {
+			if err := setParam(s.answers, step.Param, step.Param.Default); err != nil {
+				return fmt.Errorf("invalid default: %v", err)
+			}
+		}
+	} else {
+		if step.Choices != nil {
+			choices, err := step.Choices(ctx)
+			if err == nil && len(choices) > 0 {
+				found := false
+				for _, choice := range choices {
+					if strings.EqualFold(choice, answer) {
+						answer = choice
+						found = true
+						break
+					}
+				}
+				if !found {
+					return fmt.Errorf("%s must be one of %s, got %q", step.Param.Name, strings.Join(choices, ", "), answer)
+				}
+			}
+		}
+		if err := setParam(s.answers, step.Param, answer); err != nil {
+			return err
+		}
+	}
+	s.step++
+	s.skipAnswered()
+	return nil
+}
+
+// finish calls the wizard's Finish with the answers. The completed wizard is audited as a separate invocation from
+// the command which started it.
+func (s *wizardSession) finish(ctx context.Context, client util.SlackClientInterface, msg *slackevents.MessageEvent) []slack.MsgOption {
+	record := newAuditRecord(auditSourceWizard, s.user, s.channel, s.command, s.answers.Raw)
+	record.Params = s.answers.Values()
+	ctx = audit.NewContext(ctx, record)
+
+	// the wizard acts on behalf of the message which started it
+	evt := *msg
+	evt.Text = strings.Join(s.answers.Raw, " ")
+
+	response, err := s.wizard.Finish(ctx, client, &evt, s.answers)
+	if err != nil {
+		log.Warnf("failed processing message: %v, %v", err, response)
+		finishAuditRecord(record, audit.OutcomeError, err)
+	} else {
+		finishAuditRecord(record, audit.OutcomeSuccess, nil)
+	}
+	return response
+}
+
+// reply posts text in the thread of the session
+func (s *wizardSession) reply(client util.SlackClientInterface, text string) error {
+	options := append(util.StringToBlock(text, false), slack.MsgOptionTS(s.thread))
+	_, _, err := client.PostMessage(s.channel, options...)
+	if err != nil {
+		return fmt.Errorf("failed responding to message: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// withWizardSessions replaces the wizards in progress for the duration of a test
+func withWizardSessions(t *testing.T, clock *fakeClock) {
+	saved := wizards
+	wizards = newWizardSessions(clock)
+	t.Cleanup(func() {
+		wizards = saved
+	})
+}
+
+// threadMessage builds a message from user in a thread
+func threadMessage(text, user, thread string, seq int) slackevents.EventsAPIEvent {
+	return slackevents.EventsAPIEvent{
+		Type: "message",
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: "message",
+			Data: &slackevents.MessageEvent{
+				Type:            "message",
+				Text:            text,
+				Channel:         "C1",
+				ChannelType:     slack.TYPE_CHANNEL,
+				User:            user,
+				TimeStamp:       fmt.Sprintf("%s%d", thread, seq),
+				ThreadTimeStamp: thread,
+			},
+		},
+	}
+}
+
+func testWizard(finished **data.ParsedArgs) data.Attributes {
+	return data.Attributes{
+		Commands:           []string{"order"},
+		AllowNonSplatUsers: true,
+		Params: []data.Param{
+			{
+				Name:     "size",
+				KeyValue: true,
+			},
+			{
+				Name:     "count",
+				Type:     data.ParamInt,
+				KeyValue: true,
+				Default:  "1",
+			},
+			{
+				Name:      "item",
+				Remainder: true,
+			},
+		},
+		Wizard: &data.Wizard{
+			Timeout: time.Minute,
+			Steps: []data.Step{
+				{
+					Param:  data.Param{Name: "item", Required: true},
+					Prompt: "what would you like?",
+				},
+				{
+					Param: data.Param{Name: "size"},
+					Choices: func(ctx context.Context) ([]string, error) {
+						return []string{"small", "large"}, nil
+					},
+				},
+				{
+					Param:  data.Param{Name: "count", Type: data.ParamInt, Min: 1, Max: 10, Default: "1"},
+					Prompt: "how many?",
+				},
+			},
+			Finish: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, answers *data.ParsedArgs) ([]slack.MsgOption, error) {
+				*finished = answers
+				return util.StringToBlock(fmt.Sprintf("ordered %d %s %s", answers.Int("count"), answers.String("size"), answers.String("item")), false), nil
+			},
+		},
+	}
+}
+
+func TestWizard(t *testing.T) {
+	os.Setenv("SPLAT_BOT_USER_ID", SPLAT_BOT_USER_ID)
+	clock := &fakeClock{now: time.Now()}
+	withWizardSessions(t, clock)
+
+	var finished *data.ParsedArgs
+	withAttributes(t, testWizard(&finished))
+
+	client := util.NewFakeClient()
+	seq := 0
+	expectReply := func(text string, expected string) {
+		t.Helper()
+		seq++
+		client.Reset()
+		if err := Handler(context.TODO(), client, threadMessage(text, "U1", "100.1", seq)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		message, ok := client.LastMessage()
+		if !ok {
+			t.Fatalf("expected a reply to %q", text)
+		}
+		if message.ThreadTimestamp != "100.1" {
+			t.Fatalf("expected the reply to be in the thread, got %+v", message)
+		}
+		if !strings.Contains(message.Text, expected) {
+			t.Fatalf("expected the reply to %q to contain %q, got %q", text, expected, message.Text)
+		}
+		clock.now = clock.now.Add(time.Second)
+	}
+
+	// the message which starts the wizard is the root of the thread
+	start := threadMessage("order", "U1", "", 0)
+	start.InnerEvent.Data.(*slackevents.MessageEvent).TimeStamp = "100.1"
+	if err := Handler(context.TODO(), client, start); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	message, _ := client.LastMessage()
+	if !strings.Contains(message.Text, "*1/3* what would you like?") {
+		t.Fatalf("expected the first question, got %q", message.Text)
+	}
+
+	// replies from other users are not answers
+	client.Reset()
+	if err := Handler(context.TODO(), client, threadMessage("coffee", "U2", "100.1", 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.Messages()) != 0 {
+		t.Fatalf("expected other users to be ignored, got %+v", client.Messages())
+	}
+
+	expectReply("skip", "item is required")
+	expectReply("coffee", "*2/3* size (one of small, large, `skip` to leave it out)")
+	expectReply("medium", "size must be one of small, large")
+	expectReply("LARGE", "*3/3* how many? (1-10, `skip` to use the default of 1)")
+	expectReply("20", "count must be between 1 and 10, got 20")
+	expectReply("<@testbot> 2", "ordered 2 large coffee")
+
+	if finished == nil || finished.String("size") != "large" {
+		t.Fatalf("expected the wizard to finish with the answers, got %+v", finished)
+	}
+
+	// the wizard is done, so replies are handled as usual
+	client.Reset()
+	if err := Handler(context.TODO(), client, threadMessage("cancel", "U1", "100.1", 100)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.Messages()) != 0 {
+		t.Fatalf("expected the finished wizard to no longer handle replies, got %+v", client.Messages())
+	}
+}
+
+func TestWizardArgsAnswerSteps(t *testing.T) {
+	withWizardSessions(t, &fakeClock{now: time.Now()})
+
+	var finished *data.ParsedArgs
+	attribute := testWizard(&finished)
+	msg := &slackevents.MessageEvent{User: "U1", Channel: "C1", TimeStamp: "200.1"}
+	parsed, err := parseParams(attribute.Params, []string{"iced", "tea"}, []string{"order", "iced", "tea"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response := wizards.start(context.TODO(), util.NewFakeClient(), msg, attribute, parsed)
+	text := util.RenderMsgOptions(response...)
+	if !strings.Contains(text, "*2/3* size") {
+		t.Fatalf("expected the item to be answered by the arguments, got %q", text)
+	}
+
+	// only one wizard may be in progress in a thread
+	response = wizards.start(context.TODO(), util.NewFakeClient(), msg, attribute, parsed)
+	if text := util.RenderMsgOptions(response...); !strings.Contains(text, "`order` is already in progress") {
+		t.Fatalf("expected a second wizard to be rejected, got %q", text)
+	}
+}
+
+func TestWizardArgsAnswerEveryStep(t *testing.T) {
+	os.Setenv("SPLAT_BOT_USER_ID", SPLAT_BOT_USER_ID)
+	withWizardSessions(t, &fakeClock{now: time.Now()})
+
+	var finished *data.ParsedArgs
+	withAttributes(t, testWizard(&finished))
+	client := util.NewFakeClient()
+
+	start := threadMessage("order size=large count=2 iced tea", "U1", "", 0)
+	start.InnerEvent.Data.(*slackevents.MessageEvent).TimeStamp = "500.1"
+	if err := Handler(context.TODO(), client, start); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if message, _ := client.LastMessage(); message.Text != "ordered 2 large iced tea" {
+		t.Fatalf("expected the wizard to finish without asking, got %q", message.Text)
+	}
+	if finished == nil {
+		t.Fatalf("expected the wizard to finish with the arguments")
+	}
+	if session, _ := wizards.lookup("C1", "500.1"); session != nil {
+		t.Fatalf("expected no wizard to be left in progress")
+	}
+}
+
+func TestWizardCancelAndTimeout(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	withWizardSessions(t, clock)
+
+	var finished *data.ParsedArgs
+	attribute := testWizard(&finished)
+	client := util.NewFakeClient()
+
+	wizards.start(context.TODO(), client, &slackevents.MessageEvent{User: "U1", Channel: "C1", TimeStamp: "300.1"}, attribute, data.NewParsedArgs(nil))
+	handled, err := wizards.handle(context.TODO(), client, threadMessage("Cancel", "U1", "300.1", 1).InnerEvent.Data.(*slackevents.MessageEvent))
+	if !handled || err != nil {
+		t.Fatalf("expected cancel to be handled, got %v %v", handled, err)
+	}
+	if message, _ := client.LastMessage(); message.Text != "`order` cancelled." {
+		t.Fatalf("expected the wizard to be cancelled, got %q", message.Text)
+	}
+	if session, _ := wizards.lookup("C1", "300.1"); session != nil {
+		t.Fatalf("expected the cancelled wizard to be removed")
+	}
+
+	wizards.start(context.TODO(), client, &slackevents.MessageEvent{User: "U1", Channel: "C1", TimeStamp: "400.1"}, attribute, data.NewParsedArgs(nil))
+	clock.now = clock.now.Add(2 * time.Minute)
+	handled, err = wizards.handle(context.TODO(), client, threadMessage("coffee", "U1", "400.1", 1).InnerEvent.Data.(*slackevents.MessageEvent))
+	if !handled || err != nil {
+		t.Fatalf("expected the late answer to be handled, got %v %v", handled, err)
+	}
+	if message, _ := client.LastMessage(); !strings.Contains(message.Text, "timed out") {
+		t.Fatalf("expected the wizard to time out, got %q", message.Text)
+	}
+	if finished != nil {
+		t.Fatalf("expected the wizard not to finish")
+	}
+	if handled, _ := wizards.handle(context.TODO(), client, threadMessage("coffee", "U1", "400.1", 2).InnerEvent.Data.(*slackevents.MessageEvent)); handled {
+		t.Fatalf("expected the timed out wizard to be removed")
+	}
+}
@@ -0,0 +1,203 @@
+package console
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/commands"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// Options describe who sends the messages read by the console and where they are sent.
+type Options struct {
+	User    string
+	Channel string
+	// ChannelType is the type of channel the message is sent in, such as channel or im.
+	ChannelType string
+	// Thread the timestamp of the thread the messages are sent in. Messages are not sent in a thread when empty.
+	Thread string
+	// Mention when true, the bot is mentioned at the start of each message.
+	Mention bool
+}
+
+// Console drives the commands without Slack. Each line read by the console is sent to commands.Handler as a message
+// and the responses are printed as readable text.
+type Console struct {
+	options Options
+	client  *Client
+	epoch   int64
+	seq     int
+
+	// handler is replaced in tests
+	handler func(ctx context.Context, client util.SlackClientInterface, evt slackevents.EventsAPIEvent) error
+}
+
+// New returns a console which prints responses to out.
+func New(out io.Writer, options Options) *Console {
+	return &Console{
+		options: options,
+		client:  NewClient(out),
+		epoch:   time.Now().Unix(),
+		handler: commands.Handler,
+	}
+}
+
+// Client returns the client which prints the messages posted by the bot.
+func (c *Console) Client() *Client {
+	return c.client
+}
+
+// Event returns the event Slack would deliver for a message containing text.
+func (c *Console) Event(text string) slackevents.EventsAPIEvent {
+	c.seq++
+	// timestamps must be unique for the message to not be dropped as a duplicate
+	timestamp := fmt.Sprintf("%d.%06d", c.epoch, c.seq)
+
+	evt := slackevents.EventsAPIEvent{
+		Type: slackevents.CallbackEvent,
+		Data: &slackevents.EventsAPICallbackEvent{
+			Type:    slackevents.CallbackEvent,
+			EventID: fmt.Sprintf("Ev%d%06d", c.epoch, c.seq),
+		},
+	}
+	if c.options.Mention {
+		evt.InnerEvent = slackevents.EventsAPIInnerEvent{
+			Type: string(slackevents.AppMention),
+			Data: &slackevents.AppMentionEvent{
+				Type:            string(slackevents.AppMention),
+				User:            c.options.User,
+				Channel:         c.options.Channel,
+				Text:            fmt.Sprintf("%s %s", util.BotMention(), text),
+				TimeStamp:       timestamp,
+				ThreadTimeStamp: c.options.Thread,
+			},
+		}
+		return evt
+	}
+	evt.InnerEvent = slackevents.EventsAPIInnerEvent{
+		Type: string(slackevents.Message),
+		Data: &slackevents.MessageEvent{
+			Type:            string(slackevents.Message),
+			User:            c.options.User,
+			Channel:         c.options.Channel,
+			ChannelType:     c.options.ChannelType,
+			Text:            text,
+			TimeStamp:       timestamp,
+			ThreadTimeStamp: c.options.Thread,
+		},
+	}
+	return evt
+}
+
+// Send sends a message containing text to the commands and waits for it to be handled.
+func (c *Console) Send(ctx context.Context, text string) error {
+	return c.handler(ctx, c.client, c.Event(text))
+}
+
+// Run sends each line read from in as a message. Blank lines and lines starting with # are skipped so that scripts
+// can be commented. Errors returned while handling a message are printed rather than stopping the console.
+func (c *Console) Run(ctx context.Context, in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		c.client.printf("> %s\n", line)
+		if err := c.Send(ctx, line); err != nil {
+			c.client.printf("error: %v\n", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("unable to read input: %v", err)
+	}
+	return nil
+}
+
+// Client records messages like util.FakeClient and also prints them as they are posted.
+type Client struct {
+	*util.FakeClient
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewClient returns a client which prints the messages it is asked to post to out.
+func NewClient(out io.Writer) *Client {
+	return &Client{
+		FakeClient: util.NewFakeClient(),
+		out:        out,
+	}
+}
+
+func (c *Client) printf(format string, args ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.out, format, args...)
+}
+
+// print writes the posted message with the timestamp
+func (c *Client) print(timestamp string) {
+	var message util.FakeMessage
+	for _, message = range c.Messages() {
+		if message.Timestamp == timestamp {
+			break
+		}
+	}
+
+	var where []string
+	where = append(where, message.Channel)
+	if message.Ephemeral {
+		where = append(where, "ephemeral to "+message.User)
+	}
+	if len(message.ThreadTimestamp) > 0 {
+		where = append(where, "thread "+message.ThreadTimestamp)
+	}
+	if len(message.ResponseURL) > 0 {
+		where = append(where, "replacing original")
+	}
+
+	rendered := strings.ReplaceAll(message.Render(), "\n", "\n  ")
+	c.printf("[%s]\n  %s\n", strings.Join(where, ", "), rendered)
+}
+
+func (c *Client) PostEphemeral(channelID string, userID string, options ...slack.MsgOption) (string, error) {
+	timestamp, err := c.FakeClient.PostEphemeral(channelID, userID, options...)
+	if err == nil {
+		c.print(timestamp)
+	}
+	return timestamp, err
+}
+
+func (c *Client) PostMessage(channelID string, options ...slack.MsgOption) (string, string, error) {
+	channel, timestamp, err := c.FakeClient.PostMessage(channelID, options...)
+	if err == nil {
+		c.print(timestamp)
+	}
+	return channel, timestamp, err
+}
+
+func (c *Client) OpenViewContext(ctx context.Context, triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	c.printf("[modal %s opened. modals can not be submitted from the console]\n", view.CallbackID)
+	return c.FakeClient.OpenViewContext(ctx, triggerID, view)
+}
+
+func (c *Client) PublishViewContext(ctx context.Context, userID string, view slack.HomeTabViewRequest, hash string) (*slack.ViewResponse, error) {
+	response, err := c.FakeClient.PublishViewContext(ctx, userID, view, hash)
+	if err == nil {
+		rendered := strings.ReplaceAll(util.RenderBlocks(view.Blocks.BlockSet), "\n", "\n  ")
+		c.printf("[home tab of %s published]\n  %s\n", userID, rendered)
+	}
+	return response, err
+}
@@ -0,0 +1,77 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+func TestEvent(t *testing.T) {
+	os.Setenv("SPLAT_BOT_USER_ID", "USPLATBOT")
+
+	c := New(&bytes.Buffer{}, Options{User: "U1", Channel: "C1", Thread: "1.1", Mention: true})
+	first := c.Event("ci lease list")
+	mention, ok := first.InnerEvent.Data.(*slackevents.AppMentionEvent)
+	if !ok {
+		t.Fatalf("expected an app mention, got %T", first.InnerEvent.Data)
+	}
+	if mention.Text != "<@USPLATBOT> ci lease list" || mention.User != "U1" || mention.Channel != "C1" || mention.ThreadTimeStamp != "1.1" {
+		t.Fatalf("unexpected mention: %+v", mention)
+	}
+
+	second := c.Event("ci lease list")
+	if second.InnerEvent.Data.(*slackevents.AppMentionEvent).TimeStamp == mention.TimeStamp {
+		t.Fatalf("expected each message to have a unique timestamp")
+	}
+
+	c = New(&bytes.Buffer{}, Options{User: "U1", Channel: "D1", ChannelType: "im"})
+	msg, ok := c.Event("hello").InnerEvent.Data.(*slackevents.MessageEvent)
+	if !ok || msg.Text != "hello" || msg.ChannelType != "im" {
+		t.Fatalf("expected a message in a DM, got %+v", c.Event("hello").InnerEvent.Data)
+	}
+}
+
+func TestRun(t *testing.T) {
+	out := &bytes.Buffer{}
+	c := New(out, Options{User: "U1", Channel: "C1"})
+
+	var received []string
+	c.handler = func(ctx context.Context, client util.SlackClientInterface, evt slackevents.EventsAPIEvent) error {
+		msg := evt.InnerEvent.Data.(*slackevents.MessageEvent)
+		received = append(received, msg.Text)
+		_, err := client.PostEphemeral(msg.Channel, msg.User, util.StringToBlock("you said "+msg.Text, false)...)
+		return err
+	}
+
+	script := "# leases\nci lease list\n\nci pools list\n"
+	if err := c.Run(context.TODO(), strings.NewReader(script)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(received, ",") != "ci lease list,ci pools list" {
+		t.Fatalf("expected comments and blank lines to be skipped, got %v", received)
+	}
+
+	expected := "> ci lease list\n[C1, ephemeral to U1]\n  you said ci lease list\n> ci pools list\n[C1, ephemeral to U1]\n  you said ci pools list\n"
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestRunCommands(t *testing.T) {
+	os.Setenv("SPLAT_BOT_USER_ID", "USPLATBOT")
+
+	out := &bytes.Buffer{}
+	c := New(out, Options{User: "U1", Channel: "C1", Mention: true})
+	if err := c.Run(context.TODO(), strings.NewReader("ci lease acquire cpus=0\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "cpus must be between 1 and 128, got 0") {
+		t.Fatalf("expected the usage to be printed, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,101 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/controllers"
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+// DefaultCRDPath is where the vSphere capacity manager CRDs are vendored, relative to the root of the repository.
+var DefaultCRDPath = filepath.Join("vendor", "github.com", "openshift-splat-team", "vsphere-capacity-manager", "config", "crd", "bases")
+
+// StartEnvtest starts a local API server with the vSphere capacity manager CRDs installed. Pools are created from
+// the pool-*.yaml files in dataDir, if set. The returned function stops the API server. The control plane binaries
+// are found using KUBEBUILDER_ASSETS.
+func StartEnvtest(ctx context.Context, crdPath, dataDir string) (*rest.Config, func() error, error) {
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     []string{crdPath},
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := testEnv.Start()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to start envtest: %v", err)
+	}
+
+	if err := seedEnvtest(ctx, cfg, dataDir); err != nil {
+		_ = testEnv.Stop()
+		return nil, nil, err
+	}
+	return cfg, testEnv.Stop, nil
+}
+
+func seedEnvtest(ctx context.Context, cfg *rest.Config, dataDir string) error {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("could not add types to scheme: %v", err)
+	}
+	if err := v1.Install(scheme); err != nil {
+		return fmt.Errorf("could not add types to scheme: %v", err)
+	}
+	k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("unable to create client: %v", err)
+	}
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: controllers.VcmNamespace,
+		},
+	}
+	if err := k8sClient.Create(ctx, namespace); err != nil {
+		return fmt.Errorf("unable to create namespace %s: %v", controllers.VcmNamespace, err)
+	}
+
+	if len(dataDir) == 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return fmt.Errorf("unable to read envtest data: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "pool-") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dataDir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %v", entry.Name(), err)
+		}
+		pool := &v1.Pool{}
+		if err := yaml.Unmarshal(content, pool); err != nil {
+			return fmt.Errorf("unable to parse %s: %v", entry.Name(), err)
+		}
+		status := pool.Status.DeepCopy()
+		pool.Namespace = controllers.VcmNamespace
+		pool.Name = strings.ToLower(pool.Name)
+		if err := k8sClient.Create(ctx, pool); err != nil {
+			return fmt.Errorf("unable to create pool %s: %v", pool.Name, err)
+		}
+		// the status is dropped on create
+		status.DeepCopyInto(&pool.Status)
+		if err := k8sClient.Status().Update(ctx, pool); err != nil {
+			return fmt.Errorf("unable to set the status of pool %s: %v", pool.Name, err)
+		}
+		log.Infof("created pool %s", pool.Name)
+	}
+	return nil
+}
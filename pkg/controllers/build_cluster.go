@@ -1,54 +1,53 @@
 package controllers
 
 import (
-	"os"
+	"context"
+	"fmt"
 
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
-	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
 	"k8s.io/klog/v2/textlogger"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
 
-func init() {
-	if os.Getenv("UNIT") != "" {
-		log.Printf("!!! controllers are disabled for unit tests")
-		return
-	}
+// Start connects to the cluster described by cfg and starts the reconcilers which back the pool and lease commands.
+// Lease details are sent to users with slackClient. When slackClient is nil, a client is created from the
+// environment. The bot's metrics are served on metricsAddress at /metrics. The reconcilers run until ctx is cancelled.
+func Start(ctx context.Context, cfg *rest.Config, slackClient util.SlackClientInterface, metricsAddress string) error {
 	logger := textlogger.NewLogger(textlogger.NewConfig())
 	ctrl.SetLogger(logger)
 
-	mgr, err := manager.New(config.GetConfigOrDie(), manager.Options{})
+	mgr, err := manager.New(cfg, manager.Options{
+		Metrics: metricsserver.Options{BindAddress: metricsAddress},
+	})
 	if err != nil {
-		log.Printf("could not create manager: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("could not create manager: %v", err)
 	}
 
 	err = v1.AddToScheme(mgr.GetScheme())
 	if err != nil {
-		log.Printf("could not add types to scheme: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("could not add types to scheme: %v", err)
 	}
 
 	if err := (&PoolReconciler{}).
 		SetupWithManager(mgr); err != nil {
-		log.Printf("unable to create PoolReconciler: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("unable to create PoolReconciler: %v", err)
 	}
 
-	if err := (&LeaseReconciler{}).
+	if err := (&LeaseReconciler{slackClient: slackClient}).
 		SetupWithManager(mgr); err != nil {
-		log.Printf("unable to create LeaseReconciler: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("unable to create LeaseReconciler: %v", err)
 	}
 
 	go func() {
-		if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
-			log.Printf("could not start manager: %v", err)
-			os.Exit(1)
+		if err := mgr.Start(ctx); err != nil {
+			log.Fatalf("could not start manager: %v", err)
 		}
 	}()
+	return nil
 }
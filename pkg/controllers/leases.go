@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	awstypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
 	log "github.com/sirupsen/logrus"
@@ -38,16 +40,92 @@ const (
 	lease_details_sent         = "lease-details-sent"
 
 	VcmNamespace = "vsphere-infra-helpers"
+
+	// LeaseRenewActionID is the action_id of buttons which renew the lease of the user who clicks them
+	LeaseRenewActionID = "lease-renew"
+	// LeaseReleaseActionID is the action_id of buttons which release the leases of the user who clicks them
+	LeaseReleaseActionID = "lease-release"
 )
 
 var (
 	leaseMu    sync.Mutex
 	leases     = make(map[string]*v1.Lease)
 	userLeases = make(map[string]*v1.Lease)
+
+	leaseChangeMu       sync.Mutex
+	leaseChangeHandlers []LeaseChangeHandler
 )
 
+// LeaseChangeHandler is called when a lease owned by user is created, updated or deleted, including when the lease
+// is resynced. client is the Slack client used by the LeaseReconciler. Handlers are called by Reconcile, so they must
+// return quickly and do any slow work asynchronously.
+type LeaseChangeHandler func(ctx context.Context, client util.SlackClientInterface, user string)
+
+// OnLeaseChange registers a handler which is called when the leases of a user change.
+func OnLeaseChange(handler LeaseChangeHandler) {
+	leaseChangeMu.Lock()
+	defer leaseChangeMu.Unlock()
+	leaseChangeHandlers = append(leaseChangeHandlers, handler)
+}
+
+func notifyLeaseChange(ctx context.Context, client util.SlackClientInterface, user string) {
+	leaseChangeMu.Lock()
+	handlers := make([]LeaseChangeHandler, len(leaseChangeHandlers))
+	copy(handlers, leaseChangeHandlers)
+	leaseChangeMu.Unlock()
+
+	for _, handler := range handlers {
+		handler(ctx, client, user)
+	}
+}
+
+// LeaseSummary describes a lease owned by a user
+type LeaseSummary struct {
+	Name   string
+	VCpus  int
+	Memory int
+	// Pool the lease was fulfilled from, or the pool it requires if it has not been fulfilled.
+	Pool        string
+	Phase       v1.Phase
+	NetworkOnly bool
+	Expires     time.Time
+}
+
+// GetUserLeases returns the leases owned by the user, including network-only leases, ordered by name.
+func GetUserLeases(user string) []LeaseSummary {
+	leaseMu.Lock()
+	defer leaseMu.Unlock()
+
+	var summaries []LeaseSummary
+	for _, lease := range leases {
+		if lease.Annotations[SplatBotLeaseOwner] != user || lease.DeletionTimestamp != nil {
+			continue
+		}
+		pool := lease.Status.Name
+		if len(pool) == 0 {
+			pool = lease.Spec.RequiredPool
+		}
+		summaries = append(summaries, LeaseSummary{
+			Name:        lease.Name,
+			VCpus:       lease.Spec.VCpus,
+			Memory:      lease.Spec.Memory,
+			Pool:        pool,
+			Phase:       lease.Status.Phase,
+			NetworkOnly: hasLabel(lease, network_only_lease),
+			Expires:     getLeaseExpiration(lease),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
 func GetPoolNames(ctx context.Context) ([]string, error) {
 	var poolNames []string
+	if k8sclient == nil {
+		return poolNames, errNotConnected
+	}
 	poolList := &v1.PoolList{}
 
 	if err := k8sclient.List(ctx, poolList, &client.ListOptions{
@@ -64,6 +142,9 @@ func GetPoolNames(ctx context.Context) ([]string, error) {
 }
 
 func AcquireLease(ctx context.Context, user string, cpus, memory int, pool string, networks int) (*v1.Lease, error) {
+	if k8sclient == nil {
+		return nil, errNotConnected
+	}
 	leaseMu.Lock()
 	if _, exists := userLeases[user]; exists {
 		leaseMu.Unlock()
@@ -126,6 +207,7 @@ func AcquireLease(ctx context.Context, user string, cpus, memory int, pool strin
 			if err != nil {
 				return nil, fmt.Errorf("failed to create network-only lease: %w", err)
 			}
+			audit.Touched(ctx, "create", "Lease", networkOnlyLease.Namespace, networkOnlyLease.Name)
 		}
 	}
 	log.Infof("creating primary lease")
@@ -133,6 +215,7 @@ func AcquireLease(ctx context.Context, user string, cpus, memory int, pool strin
 	if err != nil {
 		return nil, fmt.Errorf("failed to create lease: %v", err)
 	}
+	audit.Touched(ctx, "create", "Lease", lease.Namespace, lease.Name)
 	leaseMu.Lock()
 	userLeases[user] = lease
 	leaseMu.Unlock()
@@ -164,6 +247,7 @@ func RemoveLease(ctx context.Context, user string) error {
 		if err != nil {
 			return fmt.Errorf("failed to delete lease: %v", err)
 		}
+		audit.Touched(ctx, "delete", "Lease", lease.Namespace, lease.Name)
 	}
 	leaseMu.Lock()
 	delete(userLeases, user)
@@ -216,6 +300,7 @@ func RenewLease(ctx context.Context, user string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to renew lease: %v. you might try again", err)
 	}
+	audit.Touched(ctx, "update", "Lease", userLease.Namespace, userLease.Name)
 
 	return getLeaseExpiration(userLease).String(), nil
 }
@@ -282,6 +367,9 @@ type LeaseReconciler struct {
 
 	// userReconciler reconciles users associated with leases
 	userReconciler *UserReconciler
+
+	// slackClient sends lease details to users. When nil, a client is created from the environment.
+	slackClient util.SlackClientInterface
 }
 
 func (l *LeaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -300,7 +388,7 @@ func (l *LeaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	l.RESTMapper = mgr.GetRESTMapper()
 
 	// SetupWithManager
-	l.userReconciler = &UserReconciler{}
+	l.userReconciler = &UserReconciler{client: l.slackClient}
 
 	if err := (l.userReconciler).
 		SetupWithManager(mgr); err != nil {
@@ -486,9 +574,12 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 				if !hasLabel(lease, network_only_lease) {
 					userLeases[user] = lease
 				}
+				defer notifyLeaseChange(ctx, l.userReconciler.client, user)
 			}
 		}
+		leaseMu.Lock()
 		leases[lease.Name] = lease
+		leaseMu.Unlock()
 	} else {
 		leaseMu.Lock()
 		log.Infof("Handling delete of lease %v", lease.Name)
@@ -500,6 +591,9 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			}
 		}
 		leaseMu.Unlock()
+		if user, found := lease.Annotations[SplatBotLeaseOwner]; found {
+			defer notifyLeaseChange(ctx, l.userReconciler.client, user)
+		}
 		if hasFinalizer(lease) {
 			// Check to see if lease is pending.  If so, then just continue since there is nothing to clean up.
 			if !hasLabel(lease, network_only_lease) && (lease.Status.Phase != "Pending" && lease.Status.Phase != "") {
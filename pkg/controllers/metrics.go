@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
+)
+
+var (
+	poolVCpusDesc = prometheus.NewDesc(metrics.Namespace+"_pool_vcpus",
+		"Number of vCPUs in the pool.", []string{"pool"}, nil)
+	poolVCpusAvailableDesc = prometheus.NewDesc(metrics.Namespace+"_pool_vcpus_available",
+		"Number of vCPUs in the pool which are not leased.", []string{"pool"}, nil)
+	poolMemoryDesc = prometheus.NewDesc(metrics.Namespace+"_pool_memory_gigabytes",
+		"Memory in the pool in GB.", []string{"pool"}, nil)
+	poolMemoryAvailableDesc = prometheus.NewDesc(metrics.Namespace+"_pool_memory_available_gigabytes",
+		"Memory in the pool which is not leased in GB.", []string{"pool"}, nil)
+	leasesDesc = prometheus.NewDesc(metrics.Namespace+"_leases",
+		"Number of active leases by phase.", []string{"phase"}, nil)
+	leasesPendingDesc = prometheus.NewDesc(metrics.Namespace+"_leases_pending_fulfillment",
+		"Number of leases which have not been fulfilled or failed.", nil, nil)
+)
+
+// cacheCollector reports the pools and leases cached by the reconcilers when metrics are scraped so that the metrics
+// are never out of step with the caches.
+type cacheCollector struct{}
+
+func init() {
+	crmetrics.Registry.MustRegister(cacheCollector{})
+}
+
+func (cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolVCpusDesc
+	ch <- poolVCpusAvailableDesc
+	ch <- poolMemoryDesc
+	ch <- poolMemoryAvailableDesc
+	ch <- leasesDesc
+	ch <- leasesPendingDesc
+}
+
+func (cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, pool := range GetPoolCapacity() {
+		ch <- prometheus.MustNewConstMetric(poolVCpusDesc, prometheus.GaugeValue, float64(pool.VCpus), pool.Name)
+		ch <- prometheus.MustNewConstMetric(poolVCpusAvailableDesc, prometheus.GaugeValue, float64(pool.VCpusAvailable), pool.Name)
+		ch <- prometheus.MustNewConstMetric(poolMemoryDesc, prometheus.GaugeValue, float64(pool.Memory), pool.Name)
+		ch <- prometheus.MustNewConstMetric(poolMemoryAvailableDesc, prometheus.GaugeValue, float64(pool.MemoryAvailable), pool.Name)
+	}
+
+	phases, pending := countLeases()
+	for phase, count := range phases {
+		ch <- prometheus.MustNewConstMetric(leasesDesc, prometheus.GaugeValue, float64(count), phase)
+	}
+	ch <- prometheus.MustNewConstMetric(leasesPendingDesc, prometheus.GaugeValue, float64(pending))
+}
+
+// countLeases returns the number of leases which are not being deleted by phase and the number of those leases
+// which are waiting to be fulfilled. A lease which has not been reconciled yet has no phase and is counted as
+// pending.
+func countLeases() (map[string]int, int) {
+	leaseMu.Lock()
+	defer leaseMu.Unlock()
+
+	phases := map[string]int{}
+	pending := 0
+	for _, lease := range leases {
+		if lease.DeletionTimestamp != nil {
+			continue
+		}
+		phase := lease.Status.Phase
+		if len(phase) == 0 {
+			phase = v1.PHASE_PENDING
+		}
+		phases[string(phase)]++
+		if phase != v1.PHASE_FULFILLED && phase != v1.PHASE_FAILED {
+			pending++
+		}
+	}
+	return phases, pending
+}
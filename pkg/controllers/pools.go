@@ -2,10 +2,13 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
 	log "github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
@@ -17,13 +20,25 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const (
+	// PoolCordonActionID is the action_id prefix of buttons which cordon the pool named by their value
+	PoolCordonActionID = "pool-cordon"
+	// PoolUncordonActionID is the action_id prefix of buttons which uncordon the pool named by their value
+	PoolUncordonActionID = "pool-uncordon"
+)
+
 var (
 	poolsMu   sync.Mutex
 	pools     = make(map[string]*v1.Pool)
 	k8sclient client.Client
+
+	errNotConnected = errors.New("the vSphere capacity manager is not available")
 )
 
 func SetPoolSchedulable(ctx context.Context, name string, schedulable bool) error {
+	if k8sclient == nil {
+		return errNotConnected
+	}
 	pool := &v1.Pool{}
 	name = strings.ReplaceAll(name, "<http://vcenter.ci|vcenter.ci>", "vcenter.ci")
 	err := k8sclient.Get(ctx, types.NamespacedName{
@@ -37,9 +52,58 @@ func SetPoolSchedulable(ctx context.Context, name string, schedulable bool) erro
 	if err != nil {
 		return fmt.Errorf("could not update pool %s: %v", name, err)
 	}
+	audit.Touched(ctx, "update", "Pool", pool.Namespace, pool.Name)
+	// reflect the change in the pool status right away rather than waiting for the pool to be reconciled
+	poolsMu.Lock()
+	pools[pool.Name] = pool
+	poolsMu.Unlock()
 	return nil
 }
 
+// poolScheduleButton returns a button which cordons or uncordons the pool depending on its current state
+func poolScheduleButton(pool *v1.Pool) *slack.ButtonBlockElement {
+	if pool.Spec.NoSchedule {
+		return slack.NewButtonBlockElement(fmt.Sprintf("%s-%s", PoolUncordonActionID, pool.Name), pool.Name,
+			slack.NewTextBlockObject(slack.PlainTextType, "uncordon", false, false))
+	}
+	button := slack.NewButtonBlockElement(fmt.Sprintf("%s-%s", PoolCordonActionID, pool.Name), pool.Name,
+		slack.NewTextBlockObject(slack.PlainTextType, "cordon", false, false))
+	button.Style = slack.StyleDanger
+	return button
+}
+
+// PoolCapacity describes the capacity of a pool
+type PoolCapacity struct {
+	Name            string
+	VCpus           int
+	VCpusAvailable  int
+	Memory          int
+	MemoryAvailable int
+	NoSchedule      bool
+}
+
+// GetPoolCapacity returns the capacity of each pool, ordered by name.
+func GetPoolCapacity() []PoolCapacity {
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+
+	var capacity []PoolCapacity
+	for _, pool := range pools {
+		capacity = append(capacity, PoolCapacity{
+			Name:            pool.Name,
+			VCpus:           pool.Spec.VCpus,
+			VCpusAvailable:  pool.Status.VCpusAvailable,
+			Memory:          pool.Spec.Memory,
+			MemoryAvailable: pool.Status.MemoryAvailable,
+			NoSchedule:      pool.Spec.NoSchedule,
+		})
+	}
+	sort.Slice(capacity, func(i, j int) bool {
+		return capacity[i].Name < capacity[j].Name
+	})
+	return capacity
+}
+
 func GetPoolStatus() (slack.MsgOption, error) {
 	poolsMu.Lock()
 	defer poolsMu.Unlock()
@@ -75,8 +139,7 @@ func GetPoolStatus() (slack.MsgOption, error) {
 		}...))
 
 		rtBlocks = append(rtBlocks, slack.NewRichTextBlock(fmt.Sprintf("pool-status-%s", idx), rtElems...))
-		/*rtBlocks = append(rtBlocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.PlainTextType, ".", false, false), nil, slack.NewAccessory(
-		slack.NewButtonBlockElement(fmt.Sprintf("cordon-%s", idx), "cordon", slack.NewTextBlockObject(slack.PlainTextType, "cordon", false, false)))))*/
+		rtBlocks = append(rtBlocks, slack.NewActionBlock(fmt.Sprintf("pool-actions-%s", idx), poolScheduleButton(pool)))
 		rtBlocks = append(rtBlocks, slack.NewDividerBlock())
 	}
 
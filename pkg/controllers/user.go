@@ -63,11 +63,13 @@ func (l *UserReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	l.Scheme = mgr.GetScheme()
 	l.Recorder = mgr.GetEventRecorderFor("pools-controller")
 	l.RESTMapper = mgr.GetRESTMapper()
-	slackClient, err := util.GetSlackClient()
-	if err != nil {
-		return fmt.Errorf("unable to get slack client: %v", err)
+	if l.client == nil {
+		slackClient, err := util.GetSlackClient()
+		if err != nil {
+			return fmt.Errorf("unable to get slack client: %v", err)
+		}
+		l.client = slackClient
 	}
-	l.client = slackClient
 	l.LeaseChan = make(chan *v1.Lease)
 	vcenters := os.Getenv("ACCOUNT_MINTING_VCENTERS")
 	if vcenters == "" {
@@ -205,7 +207,37 @@ Credentials are valid for vCenters:
 	if err != nil {
 		return fmt.Errorf("failed to post message: %v", err)
 	}
-	return err
+	_, _, err = client.PostMessage(channel.ID, leaseActions()...)
+	if err != nil {
+		return fmt.Errorf("failed to post lease actions: %v", err)
+	}
+	return nil
+}
+
+// LeaseActionsBlock returns the buttons which renew and release the lease of the user who clicks them
+func LeaseActionsBlock(blockID string) *slack.ActionBlock {
+	renew := slack.NewButtonBlockElement(LeaseRenewActionID, "", slack.NewTextBlockObject(slack.PlainTextType, "Renew", false, false))
+	renew.Style = slack.StylePrimary
+	release := slack.NewButtonBlockElement(LeaseReleaseActionID, "", slack.NewTextBlockObject(slack.PlainTextType, "Release", false, false))
+	release.Style = slack.StyleDanger
+	release.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject(slack.PlainTextType, "Release your lease?", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Your lease and its associated resources will be deleted.", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Release", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false))
+	return slack.NewActionBlock(blockID, renew, release)
+}
+
+// leaseActions returns a message with buttons to manage the user's lease
+func leaseActions() []slack.MsgOption {
+	text := "Done with your lease or need more time?"
+	return []slack.MsgOption{
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionBlocks(
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.PlainTextType, text, false, false), nil, nil),
+			LeaseActionsBlock("lease-actions"),
+		),
+	}
 }
 
 func hasAnnotation(lease *v1.Lease, key string) bool {
@@ -0,0 +1,177 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// abandonAfter is how long a job may keep running after its context is done before its worker is freed for other
+// jobs
+const abandonAfter = time.Second
+
+var (
+	// ErrFull is returned by Dispatch when too many jobs are waiting to run
+	ErrFull = errors.New("too many jobs are waiting to run")
+	// ErrStopped is returned by Dispatch once the dispatcher is stopped
+	ErrStopped = errors.New("the dispatcher is stopped")
+)
+
+// Job is a unit of work, such as the handling of a Slack event.
+type Job func(ctx context.Context) error
+
+type queuedJob struct {
+	name string
+	job  Job
+}
+
+// Dispatcher runs jobs concurrently on a limited number of workers. Jobs which share an ordering key, such as the
+// events of a Slack thread, are queued together and run one at a time in the order they were dispatched, so a slow
+// job only delays the jobs with its own key.
+type Dispatcher struct {
+	// workers holds a token for each job which is running
+	workers chan struct{}
+	// capacity the number of jobs which may be queued or running before Dispatch rejects jobs
+	capacity int
+	timeout  time.Duration
+
+	mu sync.Mutex
+	// ctx is nil until the dispatcher is started
+	ctx     context.Context
+	stopped bool
+	pending int
+	// queues holds the jobs of each key which haven't started yet. A key is present while its jobs are being drained.
+	queues map[string][]queuedJob
+	wg     sync.WaitGroup
+}
+
+// New returns a dispatcher which runs up to workers jobs at once. Up to capacity jobs may be queued or running before
+// Dispatch rejects jobs. Jobs are cancelled when they run longer than timeout. A timeout of 0 disables the deadline.
+func New(workers, capacity int, timeout time.Duration) *Dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Dispatcher{
+		workers:  make(chan struct{}, workers),
+		capacity: capacity,
+		timeout:  timeout,
+		queues:   map[string][]queuedJob{},
+	}
+}
+
+// Start starts running jobs, including those dispatched before the dispatcher was started. Jobs are run with a
+// context derived from ctx.
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ctx = ctx
+	for key := range d.queues {
+		d.wg.Add(1)
+		go d.drain(ctx, key)
+	}
+	log.Infof("started %d event workers with a timeout of %v", cap(d.workers), d.timeout)
+}
+
+// Stop stops accepting jobs and waits for the queued jobs to finish. Jobs which were abandoned after their deadline
+// are not waited for.
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	d.stopped = true
+	d.mu.Unlock()
+	d.wg.Wait()
+}
+
+// Dispatch queues a job without blocking. Jobs with the same key are run in the order they are dispatched. name is
+// used to identify the job in logs. ErrFull is returned when the dispatcher is over capacity and ErrStopped once it
+// is stopped, in which case the job is dropped.
+func (d *Dispatcher) Dispatch(key, name string, job Job) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return ErrStopped
+	}
+	if d.pending >= d.capacity {
+		return ErrFull
+	}
+	d.pending++
+	queue, draining := d.queues[key]
+	d.queues[key] = append(queue, queuedJob{name: name, job: job})
+	if !draining && d.ctx != nil {
+		d.wg.Add(1)
+		go d.drain(d.ctx, key)
+	}
+	return nil
+}
+
+// drain runs the jobs queued for key one at a time until none are left
+func (d *Dispatcher) drain(ctx context.Context, key string) {
+	defer d.wg.Done()
+	for {
+		d.mu.Lock()
+		queue := d.queues[key]
+		if len(queue) == 0 {
+			delete(d.queues, key)
+			d.mu.Unlock()
+			return
+		}
+		queued := queue[0]
+		d.queues[key] = queue[1:]
+		d.mu.Unlock()
+
+		d.workers <- struct{}{}
+		d.run(ctx, key, queued)
+		<-d.workers
+
+		d.mu.Lock()
+		d.pending--
+		d.mu.Unlock()
+	}
+}
+
+// run runs a job until it returns or, if it ignores its context, shortly after its deadline. The next job of the key
+// may then start while an abandoned job is still running.
+func (d *Dispatcher) run(ctx context.Context, key string, queued queuedJob) {
+	if d.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- runRecovered(ctx, queued.job)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		select {
+		case err = <-done:
+		case <-time.After(abandonAfter):
+			log.Warnf("%s: %s is still running %v after it was cancelled, freeing its worker", key, queued.name, time.Since(start))
+			return
+		}
+	}
+	if err != nil {
+		log.Warnf("%s: %s failed after %v: %v", key, queued.name, time.Since(start), err)
+		return
+	}
+	log.Debugf("%s: %s finished in %v", key, queued.name, time.Since(start))
+}
+
+// runRecovered runs the job, returning a panic as an error so that it doesn't take down the worker
+func runRecovered(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("recovered from panic: %v\n%s", recovered, debug.Stack())
+		}
+	}()
+	return job(ctx)
+}
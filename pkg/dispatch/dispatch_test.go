@@ -0,0 +1,141 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func dispatch(t *testing.T, dispatcher *Dispatcher, key string, job Job) {
+	t.Helper()
+	if err := dispatcher.Dispatch(key, key, job); err != nil {
+		t.Fatalf("unable to dispatch a job for %s: %v", key, err)
+	}
+}
+
+func TestDispatchOrdering(t *testing.T) {
+	dispatcher := New(4, 20, 0)
+	dispatcher.Start(context.TODO())
+
+	var mu sync.Mutex
+	order := map[string][]int{}
+	for idx := 0; idx < 20; idx++ {
+		key := fmt.Sprintf("thread-%d", idx%3)
+		dispatch(t, dispatcher, key, func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order[key] = append(order[key], idx)
+			return nil
+		})
+	}
+	dispatcher.Stop()
+
+	for key, indexes := range order {
+		for idx := 1; idx < len(indexes); idx++ {
+			if indexes[idx] < indexes[idx-1] {
+				t.Fatalf("jobs for %s ran out of order: %v", key, indexes)
+			}
+		}
+	}
+}
+
+func TestDispatchConcurrency(t *testing.T) {
+	dispatcher := New(2, 10, 0)
+	dispatcher.Start(context.TODO())
+	defer dispatcher.Stop()
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	dispatch(t, dispatcher, "slow", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	// a job queued behind the slow job of the same key must not hold a worker
+	dispatch(t, dispatcher, "slow", func(ctx context.Context) error {
+		return nil
+	})
+	dispatch(t, dispatcher, "other", func(ctx context.Context) error {
+		close(done)
+		return nil
+	})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("a slow job blocked a job for another thread")
+	}
+	close(release)
+}
+
+func TestDispatchCapacity(t *testing.T) {
+	dispatcher := New(1, 2, 0)
+	dispatcher.Start(context.TODO())
+
+	release := make(chan struct{})
+	for idx := 0; idx < 2; idx++ {
+		dispatch(t, dispatcher, fmt.Sprintf("key-%d", idx), func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+	}
+	// Dispatch doesn't block when the dispatcher is full
+	if err := dispatcher.Dispatch("key-2", "job", func(ctx context.Context) error { return nil }); !errors.Is(err, ErrFull) {
+		t.Fatalf("expected the job to be rejected, got %v", err)
+	}
+	close(release)
+	dispatcher.Stop()
+
+	if err := dispatcher.Dispatch("key-0", "job", func(ctx context.Context) error { return nil }); !errors.Is(err, ErrStopped) {
+		t.Fatalf("expected the job to be rejected once the dispatcher is stopped, got %v", err)
+	}
+}
+
+func TestDispatchAbandonsJobsWhichIgnoreTheirDeadline(t *testing.T) {
+	dispatcher := New(1, 10, 10*time.Millisecond)
+	dispatcher.Start(context.TODO())
+	defer dispatcher.Stop()
+
+	stuck := make(chan struct{})
+	defer close(stuck)
+	done := make(chan struct{})
+	dispatch(t, dispatcher, "stuck", func(ctx context.Context) error {
+		<-stuck
+		return nil
+	})
+	dispatch(t, dispatcher, "other", func(ctx context.Context) error {
+		close(done)
+		return nil
+	})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("a job which ignored its deadline kept its worker")
+	}
+}
+
+func TestDispatchTimeoutAndPanic(t *testing.T) {
+	dispatcher := New(1, 10, 10*time.Millisecond)
+	dispatcher.Start(context.TODO())
+
+	var deadlineErr error
+	dispatch(t, dispatcher, "key", func(ctx context.Context) error {
+		panic("callback failed")
+	})
+	dispatch(t, dispatcher, "key", func(ctx context.Context) error {
+		<-ctx.Done()
+		deadlineErr = ctx.Err()
+		return ctx.Err()
+	})
+	dispatcher.Stop()
+
+	if !errors.Is(deadlineErr, context.DeadlineExceeded) {
+		t.Fatalf("expected the job to be cancelled by its deadline, got %v", deadlineErr)
+	}
+	if err := runRecovered(context.TODO(), func(ctx context.Context) error { panic("boom") }); err == nil {
+		t.Fatalf("expected a panic to be returned as an error")
+	}
+}
@@ -0,0 +1,198 @@
+package knowledge
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+)
+
+// cooldownsFile sets the cooldowns of the assets in a prompt directory which don't set their own. It must be at the
+// top of the directory and is not loaded as an asset.
+const cooldownsFile = "cooldowns.yaml"
+
+// cooldownMaxEntries bounds the number of answers which are remembered. The oldest answer is forgotten, and its
+// cooldown cut short, when more answers are within their cooldowns.
+const cooldownMaxEntries = 4096
+
+// cooldownConfig is the content of the cooldowns file
+type cooldownConfig struct {
+	// Default the cooldowns in every channel
+	Default data.Cooldown `yaml:"default"`
+	// Channels the cooldowns in each channel, keyed by channel name, which override the default
+	Channels map[string]data.Cooldown `yaml:"channels"`
+}
+
+// parseCooldown parses a cooldown duration. Empty durations are 0.
+func parseCooldown(value string) (time.Duration, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("cooldowns must be durations such as 30m or 24h, got %q", value)
+	}
+	return duration, nil
+}
+
+// validateCooldown checks the durations of a cooldown can be parsed
+func validateCooldown(cooldown data.Cooldown) error {
+	if _, err := parseCooldown(cooldown.Channel); err != nil {
+		return err
+	}
+	_, err := parseCooldown(cooldown.User)
+	return err
+}
+
+// loadCooldowns loads the cooldowns file at path. A missing file sets no cooldowns.
+func loadCooldowns(path string) (cooldownConfig, string, error) {
+	var config cooldownConfig
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, "", nil
+	}
+	if err != nil {
+		return config, "", fmt.Errorf("error reading: %v", err)
+	}
+	if err := yaml.UnmarshalStrict(content, &config); err != nil {
+		return cooldownConfig{}, "", fmt.Errorf("error unmarshalling: %v", err)
+	}
+	if err := validateCooldown(config.Default); err != nil {
+		return cooldownConfig{}, "", fmt.Errorf("invalid default cooldown: %v", err)
+	}
+	for channel, cooldown := range config.Channels {
+		if err := validateCooldown(cooldown); err != nil {
+			return cooldownConfig{}, "", fmt.Errorf("invalid cooldown for %s: %v", channel, err)
+		}
+	}
+	return config, string(content), nil
+}
+
+// cooldown is how long an asset waits before answering again in the channel a message was sent in and before
+// answering the user who sent it again
+type cooldown struct {
+	Channel time.Duration
+	User    time.Duration
+}
+
+// resolveCooldown returns the cooldown of the asset in the channel named channel. Each duration is taken from the
+// asset, the channel's cooldowns or the default cooldowns, whichever sets it first.
+func (c cooldownConfig) resolveCooldown(asset data.KnowledgeAsset, channel string) cooldown {
+	first := func(values ...string) time.Duration {
+		for _, value := range values {
+			if len(value) > 0 {
+				// the durations are validated when they are loaded
+				duration, _ := parseCooldown(value)
+				return duration
+			}
+		}
+		return 0
+	}
+	inChannel := c.Channels[channel]
+	return cooldown{
+		Channel: first(asset.Cooldown.Channel, inChannel.Channel, c.Default.Channel),
+		User:    first(asset.Cooldown.User, inChannel.User, c.Default.User),
+	}
+}
+
+// hasChannelCooldowns is true when the cooldowns depend on the name of the channel
+func (c cooldownConfig) hasChannelCooldowns() bool {
+	return len(c.Channels) > 0
+}
+
+// cooldownStore remembers when each asset last answered in each channel and answered each user. Answers are forgotten
+// once the cooldown which applied when they were given has passed. It is safe for concurrent use.
+type cooldownStore struct {
+	// mu makes checking and recording an answer atomic so that concurrent messages don't both get answered
+	mu       sync.Mutex
+	clock    clock.PassiveClock
+	answered *cache.LRUExpireCache
+}
+
+func newCooldownStore(clock clock.PassiveClock) *cooldownStore {
+	return &cooldownStore{
+		clock:    clock,
+		answered: cache.NewLRUExpireCacheWithClock(cooldownMaxEntries, clock),
+	}
+}
+
+var cooldowns = newCooldownStore(clock.RealClock{})
+
+func channelCooldownKey(asset, channel string) string {
+	return fmt.Sprintf("channel/%s/%s", asset, channel)
+}
+
+func userCooldownKey(asset, user string) string {
+	return fmt.Sprintf("user/%s/%s", asset, user)
+}
+
+// since returns how long ago the answer identified by key was given, if it was given within the cooldown
+func (s *cooldownStore) since(key string, cooldown time.Duration) (time.Duration, bool) {
+	if cooldown <= 0 {
+		return 0, false
+	}
+	answered, ok := s.answered.Get(key)
+	if !ok {
+		return 0, false
+	}
+	since := s.clock.Now().Sub(answered.(time.Time))
+	return since, since < cooldown
+}
+
+// coolingLocked returns why the asset may not answer a message from user in channel, if it is cooling down. The caller
+// must hold mu.
+func (s *cooldownStore) coolingLocked(asset, channel, user string, cooldown cooldown) (string, bool) {
+	if since, ok := s.since(channelCooldownKey(asset, channel), cooldown.Channel); ok {
+		return fmt.Sprintf("the asset answered in this channel %s ago and its channel cooldown is %s", since.Round(time.Second), cooldown.Channel), true
+	}
+	if len(user) == 0 {
+		return "", false
+	}
+	if since, ok := s.since(userCooldownKey(asset, user), cooldown.User); ok {
+		return fmt.Sprintf("the asset answered <@%s> %s ago and its user cooldown is %s", user, since.Round(time.Second), cooldown.User), true
+	}
+	return "", false
+}
+
+// cooling returns why the asset may not answer a message from user in channel, if it is cooling down
+func (s *cooldownStore) cooling(asset, channel, user string, cooldown cooldown) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.coolingLocked(asset, channel, user, cooldown)
+}
+
+// claim records that the asset answers a message from user in channel. false is returned, and nothing is recorded,
+// if the asset is cooling down.
+func (s *cooldownStore) claim(asset, channel, user string, cooldown cooldown) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, cooling := s.coolingLocked(asset, channel, user, cooldown); cooling {
+		return false
+	}
+	now := s.clock.Now()
+	if cooldown.Channel > 0 {
+		s.add(channelCooldownKey(asset, channel), now, cooldown.Channel)
+	}
+	if cooldown.User > 0 && len(user) > 0 {
+		s.add(userCooldownKey(asset, user), now, cooldown.User)
+	}
+	return true
+}
+
+// add remembers the answer identified by key for the cooldown. The caller must hold mu.
+func (s *cooldownStore) add(key string, answered time.Time, cooldown time.Duration) {
+	if _, ok := s.answered.Get(key); !ok {
+		// Keys only returns the answers which are still within their cooldowns
+		if cooling := s.answered.Keys(); len(cooling) >= cooldownMaxEntries {
+			log.Warnf("%d knowledge answers are within their cooldowns. forgetting %v, which may answer again before its cooldown passes", len(cooling), cooling[0])
+		}
+	}
+	s.answered.Add(key, answered, cooldown)
+}
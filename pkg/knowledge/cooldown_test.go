@@ -0,0 +1,153 @@
+package knowledge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+func TestResolveCooldown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), cooldownsFile)
+	content := `default:
+  channel: 30m
+  user: 24h
+channels:
+  forum-quiet:
+    channel: 2h
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("unable to write cooldowns: %v", err)
+	}
+	config, _, err := loadCooldowns(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		asset    data.Cooldown
+		channel  string
+		expected cooldown
+	}{
+		{name: "default", channel: "forum-busy", expected: cooldown{Channel: 30 * time.Minute, User: 24 * time.Hour}},
+		{name: "channel overrides default", channel: "forum-quiet", expected: cooldown{Channel: 2 * time.Hour, User: 24 * time.Hour}},
+		{name: "asset overrides channel", asset: data.Cooldown{Channel: "5m"}, channel: "forum-quiet", expected: cooldown{Channel: 5 * time.Minute, User: 24 * time.Hour}},
+		{name: "asset disables default", asset: data.Cooldown{User: "0"}, channel: "forum-busy", expected: cooldown{Channel: 30 * time.Minute}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resolved := config.resolveCooldown(data.KnowledgeAsset{Cooldown: test.asset}, test.channel)
+			if resolved != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, resolved)
+			}
+		})
+	}
+
+	if err := os.WriteFile(path, []byte("default:\n  channel: soon\n"), 0o644); err != nil {
+		t.Fatalf("unable to write cooldowns: %v", err)
+	}
+	if _, _, err := loadCooldowns(path); err == nil || !strings.Contains(err.Error(), `got "soon"`) {
+		t.Errorf("expected an invalid duration to fail to load, got %v", err)
+	}
+}
+
+func TestKnowledgeCooldown(t *testing.T) {
+	withKnowledgeSet(t)
+	clock := clocktesting.NewFakeClock(time.Now())
+	savedCooldowns := cooldowns
+	cooldowns = newCooldownStore(clock)
+	client := util.NewFakeClient()
+	client.Channels["C1"] = "forum-one"
+	client.Channels["C2"] = "forum-two"
+	t.Cleanup(func() {
+		cooldowns = savedCooldowns
+	})
+
+	dir := t.TempDir()
+	writePrompt(t, dir, "proxy", "  tokens: [\"proxy\"]\ncooldown:\n  channel: 30m")
+	if err := os.WriteFile(filepath.Join(dir, cooldownsFile), []byte("default:\n  user: 24h\n"), 0o644); err != nil {
+		t.Fatalf("unable to write cooldowns: %v", err)
+	}
+	report, err := reloadKnowledge(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Loaded) != 1 || !report.CooldownsChanged {
+		t.Fatalf("expected one asset and the cooldowns to load, got %+v", report)
+	}
+
+	answered := func(channel, user string) bool {
+		t.Helper()
+		response, err := defaultKnowledgeHandler(context.TODO(), client, &slackevents.MessageEvent{Channel: channel, User: user}, []string{"proxy"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return len(response) > 0
+	}
+	if !answered("C1", "U1") {
+		t.Fatalf("expected the first message to be answered")
+	}
+	if answered("C1", "U2") {
+		t.Errorf("expected the asset not to repeat in the channel within its cooldown")
+	}
+	if !answered("C2", "U2") {
+		t.Errorf("expected the asset to answer in another channel")
+	}
+
+	clock.Step(31 * time.Minute)
+	if answered("C1", "U1") {
+		t.Errorf("expected the asset not to answer the same user within a day")
+	}
+	response, err := KnowledgeExplainAttributes.Callback(context.TODO(), client, &slackevents.MessageEvent{Channel: "C1", User: "U1"}, []string{"knowledge", "explain", "proxy", "proxy"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rendered := util.RenderMsgOptions(response...); !strings.Contains(rendered, "but is cooling down: the asset answered <@U1> 31m0s ago and its user cooldown is 24h0m0s") ||
+		!strings.Contains(rendered, "the bot would not respond because *proxy* is cooling down") {
+		t.Errorf("expected explain to show the cooldown, got:\n%s", rendered)
+	}
+	if !answered("C1", "U3") {
+		t.Errorf("expected the asset to answer in the channel once its cooldown passed")
+	}
+}
+
+func TestKnowledgeCooldownDoesNotPromoteWeakerMatches(t *testing.T) {
+	withKnowledgeSet(t)
+	savedCooldowns := cooldowns
+	cooldowns = newCooldownStore(clocktesting.NewFakeClock(time.Now()))
+	t.Cleanup(func() {
+		cooldowns = savedCooldowns
+	})
+	client := util.NewFakeClient()
+	client.Channels["C1"] = "forum-one"
+
+	dir := t.TempDir()
+	writePrompt(t, dir, "proxy-mirror", "  tokens: [\"proxy\", \"mirror\"]\ncooldown:\n  channel: 30m")
+	writePrompt(t, dir, "proxy", "  tokens: [\"proxy\"]")
+	if _, err := reloadKnowledge(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	answer := func(user string) string {
+		t.Helper()
+		response, err := defaultKnowledgeHandler(context.TODO(), client, &slackevents.MessageEvent{Channel: "C1", User: user}, []string{"proxy", "mirror"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return util.RenderMsgOptions(response...)
+	}
+	if response := answer("U1"); !strings.Contains(response, "proxy-mirror prompt") {
+		t.Fatalf("expected the best match to answer, got:\n%s", response)
+	}
+	if response := answer("U2"); len(response) > 0 {
+		t.Errorf("expected no answer while the best match is cooling down, got:\n%s", response)
+	}
+}
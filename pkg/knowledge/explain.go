@@ -0,0 +1,193 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const explainUsage = "usage: `knowledge explain \"<message>\"` or `knowledge explain <asset name> \"<message>\"`"
+
+// explainTokenMatch describes whether each condition in the tree rooted at match is satisfied by the tokens and
+// text of a message
+func explainTokenMatch(match *data.TokenMatch, tokens map[string]string, text string, depth int, lines []string) []string {
+	padding := strings.Repeat("    ", depth)
+	if match.CompiledExpr != nil {
+		result, err := expr.Run(match.CompiledExpr, exprEnv(tokens, text))
+		if err != nil {
+			return append(lines, fmt.Sprintf("%s✗ expr: %s => error: %v", padding, match.Expr, err))
+		}
+		satisfied, _ := result.(bool)
+		return append(lines, fmt.Sprintf("%s%s expr: %s => %v", padding, checkMark(satisfied), match.Expr, result))
+	}
+
+	matchType := "and"
+	if match.Type == "or" {
+		matchType = "or"
+	}
+	line := fmt.Sprintf("%s%s %s", padding, checkMark(isTokenMatch(match, tokens, text)), matchType)
+	if modes := match.Options.Modes(); len(modes) > 0 {
+		line = fmt.Sprintf("%s (%s)", line, strings.Join(modes, ", "))
+	}
+	if len(match.Tokens) > 0 {
+		var present []string
+		for _, token := range match.Tokens {
+			present = append(present, fmt.Sprintf("%s %s", token, checkMark(match.Options.Present(tokens, token))))
+		}
+		line = fmt.Sprintf("%s tokens: %s", line, strings.Join(present, ", "))
+	}
+	if len(match.Phrase) > 0 {
+		line = fmt.Sprintf("%s phrase: %q %s", line, match.Phrase, checkMark(util.ContainsPhrase(text, match.Phrase)))
+	}
+	if len(match.Sequence) > 0 {
+		line = fmt.Sprintf("%s sequence: %q %s", line, match.Sequence, checkMark(util.ContainsSequence(text, match.Sequence...)))
+	}
+	if match.CompiledRegex != nil {
+		line = fmt.Sprintf("%s regex: %s %s", line, match.Regex, checkMark(util.MatchesRegex(match.CompiledRegex, text)))
+	}
+	if len(match.Terms) > 0 {
+		line = fmt.Sprintf("%s terms:", line)
+	}
+	lines = append(lines, line)
+	for idx := range match.Terms {
+		lines = explainTokenMatch(&match.Terms[idx], tokens, text, depth+1, lines)
+	}
+	return lines
+}
+
+func checkMark(satisfied bool) string {
+	if satisfied {
+		return "✓"
+	}
+	return "✗"
+}
+
+// sortedTokens returns the normalized tokens of a message in a stable order
+func sortedTokens(tokens map[string]string) string {
+	var sorted []string
+	for token := range tokens {
+		sorted = append(sorted, fmt.Sprintf("`%s`", token))
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, " ")
+}
+
+// outcome describes how the bot would respond to a message matched by ranked
+func outcome(ranked []scoredMatch) string {
+	responding, suggest := respondingMatches(ranked)
+	if len(responding) == 0 {
+		if len(ranked) > 0 && len(ranked[0].Cooling) > 0 {
+			return fmt.Sprintf("the bot would not respond because *%s* is cooling down", ranked[0].Asset.Name)
+		}
+		return "the bot would not respond"
+	}
+	if !suggest {
+		return fmt.Sprintf("the bot would answer with *%s*", responding[0].Asset.Name)
+	}
+	var names []string
+	for _, match := range responding {
+		names = append(names, fmt.Sprintf("*%s*", match.Asset.Name))
+	}
+	return fmt.Sprintf("the bot would suggest %s", strings.Join(names, ", "))
+}
+
+// explainAsset describes why the asset named name did or did not match the message
+func explainAsset(name string, evaluations []evaluation, ranked []scoredMatch) (string, bool) {
+	for _, result := range evaluations {
+		if !strings.EqualFold(result.Asset.Name, name) {
+			continue
+		}
+		var builder strings.Builder
+		switch {
+		case len(result.Skipped) > 0:
+			fmt.Fprintf(&builder, "*%s* was skipped: %s\n", result.Asset.Name, result.Skipped)
+		case result.Matched && len(result.Cooling) > 0:
+			fmt.Fprintf(&builder, "*%s* matched with a score of %s but is cooling down: %s\n", result.Asset.Name, result.Score, result.Cooling)
+		case result.Matched:
+			fmt.Fprintf(&builder, "*%s* matched with a score of %s\n", result.Asset.Name, result.Score)
+		default:
+			fmt.Fprintf(&builder, "*%s* did not match\n", result.Asset.Name)
+		}
+		if len(result.Skipped) == 0 {
+			fmt.Fprintf(&builder, "tokens: %s\n", sortedTokens(result.Tokens))
+			fmt.Fprintf(&builder, "```\n%s\n```\n", strings.Join(explainTokenMatch(&result.Asset.On, result.Tokens, result.Text, 0, nil), "\n"))
+		}
+		builder.WriteString(outcome(ranked))
+		return builder.String(), true
+	}
+	return "", false
+}
+
+// explainMessage lists the assets which match the message
+func explainMessage(evaluations []evaluation, ranked []scoredMatch, args []string) string {
+	var builder strings.Builder
+	if len(ranked) == 0 {
+		fmt.Fprintf(&builder, "no knowledge assets match. tokens: %s\n", sortedTokens(util.NormalizeTokens(args)))
+	} else {
+		fmt.Fprintf(&builder, "%d knowledge assets match:\n", len(ranked))
+		for _, match := range ranked {
+			fmt.Fprintf(&builder, "• *%s* score %s\n", match.Asset.Name, match.Score)
+			if len(match.Cooling) > 0 {
+				fmt.Fprintf(&builder, "  cooling down: %s\n", match.Cooling)
+			}
+		}
+	}
+	for _, result := range evaluations {
+		if len(result.Skipped) > 0 {
+			fmt.Fprintf(&builder, "• *%s* was skipped: %s\n", result.Asset.Name, result.Skipped)
+		}
+	}
+	builder.WriteString(outcome(ranked))
+	return builder.String()
+}
+
+var KnowledgeExplainAttributes = data.Attributes{
+	Commands:       []string{"knowledge", "explain"},
+	RequireMention: true,
+	AdminOnly:      true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		rest := args[2:]
+		if len(rest) == 0 {
+			return util.StringToBlock(explainUsage, false), nil
+		}
+		message := rest[len(rest)-1]
+		messageArgs := strings.Split(message, " ")
+
+		// the message is matched as if it was sent at the top level of the channel the command was sent in
+		event := *evt
+		event.ThreadTimeStamp = ""
+		evaluations, err := evaluateKnowledge(client, messageArgs, &event)
+		if err != nil {
+			return util.StringToBlock(fmt.Sprintf("unable to match the message. %v", err), false), nil
+		}
+		ranked := rankEvaluations(evaluations)
+
+		if len(rest) == 1 {
+			return util.StringToBlockUnfurl(explainMessage(evaluations, ranked, messageArgs), false, false), nil
+		}
+		name := strings.Join(rest[:len(rest)-1], " ")
+		explanation, ok := explainAsset(name, evaluations, ranked)
+		if !ok {
+			return util.StringToBlock(fmt.Sprintf("I don't know the knowledge asset `%s`. put the message in quotes. %s", name, explainUsage), false), nil
+		}
+		return util.StringToBlockUnfurl(explanation, false, false), nil
+	},
+	Category:     data.CategoryKnowledge,
+	HelpMarkdown: "show why a message did or did not match a knowledge asset, or every asset which matches the message: `knowledge explain \"<message>\"` or `knowledge explain <asset name> \"<message>\"`. the message is matched as if it was sent in the channel the command is sent in",
+	ShouldMatch: []string{
+		"knowledge explain \"how do I install on vsphere\"",
+		"knowledge explain install \"how do I install on vsphere\"",
+	},
+	ShouldntMatch: []string{
+		"knowledge",
+		"explain knowledge",
+	},
+}
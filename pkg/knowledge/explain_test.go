@@ -0,0 +1,74 @@
+package knowledge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+func TestKnowledgeExplain(t *testing.T) {
+	withKnowledgeSet(t)
+	client := util.NewFakeClient()
+	client.Channels["CEXPLAIN"] = "forum-explain"
+
+	dir := t.TempDir()
+	writePrompt(t, dir, "proxy", `  type: or
+  tokens: ["proxy", "mirror"]
+  terms:
+  - type: or
+    tokens: ["configure", "configuring"]
+  - tokens: ["registry", "mirror"]`)
+	writePrompt(t, dir, "upgrade", `  expr: containsAny(tokens, ["upgrade", "update"])`)
+	writePrompt(t, dir, "elsewhere", "  tokens: [\"proxy\"]\nmust_be_in_channels: [\"forum-other\"]")
+	if _, err := reloadKnowledge(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	explain := func(args ...string) string {
+		t.Helper()
+		response, err := KnowledgeExplainAttributes.Callback(context.TODO(), client, &slackevents.MessageEvent{Channel: "CEXPLAIN", ThreadTimeStamp: "1.1"}, append([]string{"knowledge", "explain"}, args...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return util.RenderMsgOptions(response...)
+	}
+
+	response := explain("proxy", "Configuring a Proxy for the upgrade")
+	for _, expected := range []string{
+		"*proxy* matched with a score of 4 (tokens: 2, terms: 1, depth: 1, priority: 0)",
+		"tokens: `a` `configuring` `for` `proxy` `the` `upgrade`",
+		"✓ or tokens: proxy ✓, mirror ✗ terms:\n    ✓ or tokens: configure ✗, configuring ✓\n    ✗ and tokens: registry ✗, mirror ✗",
+		"the bot would answer with *proxy*",
+	} {
+		if !strings.Contains(response, expected) {
+			t.Errorf("expected the explanation to contain %q, got:\n%s", expected, response)
+		}
+	}
+	if response := explain("UPGRADE", "how do I update?"); !strings.Contains(response, `✓ expr: containsAny(tokens, ["upgrade", "update"]) => true`) {
+		t.Errorf("expected the expression to be evaluated, got:\n%s", response)
+	}
+	if response := explain("elsewhere", "proxy"); !strings.Contains(response, "*elsewhere* was skipped: the asset only responds in forum-other") {
+		t.Errorf("expected the channel restriction to be explained, got:\n%s", response)
+	}
+
+	response = explain("configure a proxy and upgrade")
+	for _, expected := range []string{
+		"2 knowledge assets match:\n• *proxy* score 4",
+		"• *upgrade* score 1",
+		"• *elsewhere* was skipped",
+		"the bot would answer with *proxy*",
+	} {
+		if !strings.Contains(response, expected) {
+			t.Errorf("expected the explanation to contain %q, got:\n%s", expected, response)
+		}
+	}
+	if response := explain("nothing here"); !strings.Contains(response, "no knowledge assets match. tokens: `here` `nothing`") || !strings.Contains(response, "the bot would not respond") {
+		t.Errorf("expected no assets to match, got:\n%s", response)
+	}
+	if response := explain("how", "do", "I", "proxy"); !strings.Contains(response, "I don't know the knowledge asset `how do I`") {
+		t.Errorf("expected an unknown asset to be reported, got:\n%s", response)
+	}
+}
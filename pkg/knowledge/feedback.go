@@ -0,0 +1,363 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// feedbackDayLayout is the format of the UTC day answers and votes are counted in
+	feedbackDayLayout = "2006-01-02"
+	// voteRetention is how long votes are kept. Votes are kept to recognize a user changing their vote and to decide
+	// whether an asset is muted, so answers older than this may no longer be rated.
+	voteRetention = 30 * 24 * time.Hour
+	// countsRetention is how long the counts of each day are kept
+	countsRetention = 400 * 24 * time.Hour
+)
+
+// ErrAnswerTooOld is returned when a vote is cast on an answer which is older than the vote retention
+var ErrAnswerTooOld = errors.New("the answer is too old to rate")
+
+// FeedbackCounts are the answers an asset gave in a channel on a day and the votes on those answers
+type FeedbackCounts struct {
+	Asset   string `json:"asset"`
+	Channel string `json:"channel"`
+	// Day the UTC day formatted as YYYY-MM-DD
+	Day       string `json:"day"`
+	Answers   int    `json:"answers"`
+	Helpful   int    `json:"helpful"`
+	Unhelpful int    `json:"unhelpful"`
+}
+
+// Vote is a user's rating of a knowledge answer
+type Vote struct {
+	Asset   string `json:"asset"`
+	Channel string `json:"channel"`
+	// Message the timestamp of the answer
+	Message string    `json:"message"`
+	User    string    `json:"user"`
+	Helpful bool      `json:"helpful"`
+	Time    time.Time `json:"time"`
+}
+
+// Mute records that an asset no longer answers messages because its answers were rated unhelpful
+type Mute struct {
+	Asset  string    `json:"asset"`
+	Reason string    `json:"reason"`
+	Time   time.Time `json:"time"`
+}
+
+// MutePolicy decides when an asset is muted. Assets are never muted when UnhelpfulPercent is 0.
+type MutePolicy struct {
+	// UnhelpfulPercent the percentage of votes which must rate the asset unhelpful for it to be muted
+	UnhelpfulPercent int
+	// MinVotes the number of votes needed before the asset may be muted
+	MinVotes int
+}
+
+// AssetStats are the answers and votes of an asset summed over a period
+type AssetStats struct {
+	Asset     string
+	Answers   int
+	Helpful   int
+	Unhelpful int
+}
+
+// Votes returns the number of votes on the answers of the asset
+func (s AssetStats) Votes() int {
+	return s.Helpful + s.Unhelpful
+}
+
+// HelpfulPercent returns the percentage of votes which rated the asset helpful
+func (s AssetStats) HelpfulPercent() int {
+	if s.Votes() == 0 {
+		return 0
+	}
+	return s.Helpful * 100 / s.Votes()
+}
+
+// UnhelpfulPercent returns the percentage of votes which rated the asset unhelpful
+func (s AssetStats) UnhelpfulPercent() int {
+	if s.Votes() == 0 {
+		return 0
+	}
+	return s.Unhelpful * 100 / s.Votes()
+}
+
+// feedbackFile is the content of the file the feedback is persisted to
+type feedbackFile struct {
+	// Counts keyed by countsKey
+	Counts map[string]FeedbackCounts `json:"counts"`
+	// Votes keyed by voteKey
+	Votes map[string]Vote `json:"votes"`
+	Mutes []Mute          `json:"mutes"`
+	// Unmuted when each asset was last unmuted. Only votes cast afterwards count toward muting the asset again.
+	Unmuted map[string]time.Time `json:"unmuted,omitempty"`
+}
+
+func countsKey(asset, channel, day string) string {
+	return fmt.Sprintf("%s/%s/%s", day, channel, asset)
+}
+
+func voteKey(vote Vote) string {
+	return fmt.Sprintf("%s/%s/%s", vote.Asset, vote.Message, vote.User)
+}
+
+// count adds to the counts of the asset in the channel on the day of t
+func (f *feedbackFile) count(asset, channel string, t time.Time, answers, helpful, unhelpful int) {
+	if f.Counts == nil {
+		f.Counts = map[string]FeedbackCounts{}
+	}
+	day := t.UTC().Format(feedbackDayLayout)
+	key := countsKey(asset, channel, day)
+	counts, ok := f.Counts[key]
+	if !ok {
+		counts = FeedbackCounts{Asset: asset, Channel: channel, Day: day}
+	}
+	counts.Answers += answers
+	counts.Helpful += helpful
+	counts.Unhelpful += unhelpful
+	f.Counts[key] = counts
+}
+
+// prune removes the votes and counts which are older than their retention
+func (f *feedbackFile) prune(now time.Time) {
+	for key, vote := range f.Votes {
+		if now.Sub(vote.Time) > voteRetention {
+			delete(f.Votes, key)
+		}
+	}
+	oldest := now.Add(-countsRetention).UTC().Format(feedbackDayLayout)
+	for key, counts := range f.Counts {
+		if counts.Day < oldest {
+			delete(f.Counts, key)
+		}
+	}
+}
+
+// answerTime returns when the answer with the Slack timestamp ts was posted
+func answerTime(ts string) (time.Time, bool) {
+	seconds, err := strconv.ParseFloat(ts, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
+
+// FeedbackStore counts the answers given by knowledge assets and the votes on them, and persists them as JSON.
+// Feedback is only kept in memory when the store has no path. Answers and votes are persisted by Flush, and
+// mutes are persisted immediately.
+type FeedbackStore struct {
+	clock clock.PassiveClock
+	store *util.JSONStore[feedbackFile]
+}
+
+// OpenFeedback returns a store which persists feedback to the file at path. Existing feedback is loaded from the
+// file.
+func OpenFeedback(path string, clock clock.PassiveClock) (*FeedbackStore, error) {
+	store, err := util.OpenJSONStore[feedbackFile](path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load knowledge feedback: %v", err)
+	}
+	return &FeedbackStore{clock: clock, store: store}, nil
+}
+
+// Flush persists the answers and votes recorded since the feedback was last persisted, after forgetting the votes and
+// counts which are older than their retention
+func (s *FeedbackStore) Flush() error {
+	now := s.clock.Now()
+	s.store.Defer(func(file *feedbackFile) {
+		file.prune(now)
+	})
+	if err := s.store.Flush(); err != nil {
+		return fmt.Errorf("unable to save knowledge feedback: %v", err)
+	}
+	return nil
+}
+
+// Run flushes the feedback every interval until ctx is done
+func (s *FeedbackStore) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				log.Warnf("%v", err)
+			}
+		}
+	}
+}
+
+// RecordAnswer counts an answer given by the asset in the channel
+func (s *FeedbackStore) RecordAnswer(asset, channel string) {
+	now := s.clock.Now()
+	s.store.Defer(func(file *feedbackFile) {
+		file.count(asset, channel, now, 1, 0, 0)
+	})
+}
+
+// RecordVote counts the vote. A user has one vote per answer, so a second vote on an answer replaces the first. false
+// is returned when the user already voted the same way. ErrAnswerTooOld is returned for answers older than the vote
+// retention, whose votes may have been forgotten.
+func (s *FeedbackStore) RecordVote(vote Vote) (bool, error) {
+	now := s.clock.Now()
+	if answered, ok := answerTime(vote.Message); ok && now.Sub(answered) > voteRetention {
+		return false, ErrAnswerTooOld
+	}
+	vote.Time = now
+	recorded := false
+	s.store.Defer(func(file *feedbackFile) {
+		if file.Votes == nil {
+			file.Votes = map[string]Vote{}
+		}
+		key := voteKey(vote)
+		if existing, ok := file.Votes[key]; ok {
+			if existing.Helpful == vote.Helpful {
+				return
+			}
+			// the vote is counted on the day it was first cast
+			if existing.Helpful {
+				file.count(existing.Asset, existing.Channel, existing.Time, 0, -1, 0)
+			} else {
+				file.count(existing.Asset, existing.Channel, existing.Time, 0, 0, -1)
+			}
+			vote.Time = existing.Time
+		}
+		file.Votes[key] = vote
+		if vote.Helpful {
+			file.count(vote.Asset, vote.Channel, vote.Time, 0, 1, 0)
+		} else {
+			file.count(vote.Asset, vote.Channel, vote.Time, 0, 0, 1)
+		}
+		recorded = true
+	})
+	return recorded, nil
+}
+
+// Stats returns the answers and votes of each asset since the day of since, ordered by asset name. Only the answers
+// and votes in channel are counted unless channel is empty.
+func (s *FeedbackStore) Stats(since time.Time, channel string) []AssetStats {
+	first := since.UTC().Format(feedbackDayLayout)
+	byAsset := map[string]*AssetStats{}
+	s.store.View(func(file *feedbackFile) {
+		for _, counts := range file.Counts {
+			if counts.Day < first || (len(channel) > 0 && counts.Channel != channel) {
+				continue
+			}
+			stats, ok := byAsset[counts.Asset]
+			if !ok {
+				stats = &AssetStats{Asset: counts.Asset}
+				byAsset[counts.Asset] = stats
+			}
+			stats.Answers += counts.Answers
+			stats.Helpful += counts.Helpful
+			stats.Unhelpful += counts.Unhelpful
+		}
+	})
+	var stats []AssetStats
+	for _, asset := range byAsset {
+		stats = append(stats, *asset)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Asset < stats[j].Asset
+	})
+	return stats
+}
+
+// Muted returns the mute of the asset, if it is muted
+func (s *FeedbackStore) Muted(asset string) (Mute, bool) {
+	var found Mute
+	muted := false
+	s.store.View(func(file *feedbackFile) {
+		for _, mute := range file.Mutes {
+			if mute.Asset == asset {
+				found, muted = mute, true
+				return
+			}
+		}
+	})
+	return found, muted
+}
+
+// CheckMute mutes the asset if the votes cast on it since it was last unmuted, within the vote retention, meet the
+// policy. The mute is returned when the asset is newly muted.
+func (s *FeedbackStore) CheckMute(asset string, policy MutePolicy) (Mute, bool, error) {
+	if policy.UnhelpfulPercent <= 0 {
+		return Mute{}, false, nil
+	}
+	now := s.clock.Now()
+	var mute Mute
+	muted := false
+	err := s.store.Update(func(file *feedbackFile) error {
+		for _, existing := range file.Mutes {
+			if existing.Asset == asset {
+				return nil
+			}
+		}
+		unmuted := file.Unmuted[asset]
+		stats := AssetStats{Asset: asset}
+		for _, vote := range file.Votes {
+			if vote.Asset != asset || now.Sub(vote.Time) > voteRetention || !vote.Time.After(unmuted) {
+				continue
+			}
+			if vote.Helpful {
+				stats.Helpful++
+			} else {
+				stats.Unhelpful++
+			}
+		}
+		if stats.Votes() == 0 || stats.Votes() < policy.MinVotes || stats.UnhelpfulPercent() < policy.UnhelpfulPercent {
+			return nil
+		}
+		mute = Mute{
+			Asset:  asset,
+			Reason: fmt.Sprintf("%d of %d votes rated its answers unhelpful", stats.Unhelpful, stats.Votes()),
+			Time:   now,
+		}
+		file.Mutes = append(file.Mutes, mute)
+		muted = true
+		return nil
+	})
+	if err != nil {
+		return Mute{}, false, err
+	}
+	return mute, muted, nil
+}
+
+// Unmute allows the asset to answer messages again. Votes cast before the asset is unmuted no longer count toward
+// muting it. false is returned if the asset was not muted.
+func (s *FeedbackStore) Unmute(asset string) (bool, error) {
+	now := s.clock.Now()
+	unmuted := false
+	err := s.store.Update(func(file *feedbackFile) error {
+		for idx, mute := range file.Mutes {
+			if mute.Asset != asset {
+				continue
+			}
+			file.Mutes = append(file.Mutes[:idx], file.Mutes[idx+1:]...)
+			if file.Unmuted == nil {
+				file.Unmuted = map[string]time.Time{}
+			}
+			file.Unmuted[asset] = now
+			unmuted = true
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return unmuted, nil
+}
@@ -0,0 +1,229 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// withFeedback replaces the feedback store and mute policy for the duration of a test
+func withFeedback(t *testing.T, store *FeedbackStore, policy MutePolicy) {
+	savedStore := getFeedback()
+	savedPolicy := getMutePolicy()
+	setFeedback(store)
+	setMutePolicy(policy)
+	t.Cleanup(func() {
+		setFeedback(savedStore)
+		setMutePolicy(savedPolicy)
+	})
+}
+
+func TestFeedbackStore(t *testing.T) {
+	clock := clocktesting.NewFakePassiveClock(time.Date(2024, 5, 15, 14, 0, 0, 0, time.UTC))
+	path := filepath.Join(t.TempDir(), "feedback.json")
+	store, err := OpenFeedback(path, clock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, channel := range []string{"C1", "C1", "C2"} {
+		store.RecordAnswer("proxy", channel)
+	}
+	first := fmt.Sprintf("%d.000100", clock.Now().Add(-time.Hour).Unix())
+	second := fmt.Sprintf("%d.000200", clock.Now().Add(-time.Hour).Unix())
+	for _, vote := range []struct {
+		vote     Vote
+		recorded bool
+	}{
+		{Vote{Asset: "proxy", Channel: "C1", Message: first, User: "U1", Helpful: true}, true},
+		{Vote{Asset: "proxy", Channel: "C1", Message: first, User: "U1", Helpful: true}, false},
+		{Vote{Asset: "proxy", Channel: "C1", Message: first, User: "U1", Helpful: false}, true},
+		{Vote{Asset: "proxy", Channel: "C1", Message: first, User: "U2", Helpful: true}, true},
+		{Vote{Asset: "proxy", Channel: "C2", Message: second, User: "U1", Helpful: true}, true},
+	} {
+		recorded, err := store.RecordVote(vote.vote)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if recorded != vote.recorded {
+			t.Errorf("expected the vote %+v to be recorded: %t, got %t", vote.vote, vote.recorded, recorded)
+		}
+	}
+
+	since := clock.Now().Add(-time.Hour)
+	expected := []AssetStats{{Asset: "proxy", Answers: 3, Helpful: 2, Unhelpful: 1}}
+	if stats := store.Stats(since, ""); !reflect.DeepEqual(stats, expected) {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+	expected = []AssetStats{{Asset: "proxy", Answers: 2, Helpful: 1, Unhelpful: 1}}
+	if stats := store.Stats(since, "C1"); !reflect.DeepEqual(stats, expected) {
+		t.Errorf("expected %+v in C1, got %+v", expected, stats)
+	}
+
+	reopened, err := OpenFeedback(path, clock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats := reopened.Stats(since, "C1"); len(stats) != 0 {
+		t.Errorf("expected the feedback not to be persisted before it is flushed, got %+v", stats)
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reopened, err = OpenFeedback(path, clock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats := reopened.Stats(since, "C1"); !reflect.DeepEqual(stats, expected) {
+		t.Errorf("expected the feedback to be persisted, got %+v", stats)
+	}
+
+	clock.SetTime(clock.Now().Add(48 * time.Hour))
+	if stats := reopened.Stats(clock.Now().Add(-24*time.Hour), ""); len(stats) != 0 {
+		t.Errorf("expected no stats for the last day, got %+v", stats)
+	}
+}
+
+func TestFeedbackOldAnswers(t *testing.T) {
+	clock := clocktesting.NewFakePassiveClock(time.Date(2024, 5, 15, 14, 0, 0, 0, time.UTC))
+	store, _ := OpenFeedback("", clock)
+	message := fmt.Sprintf("%d.000100", clock.Now().Unix())
+	if _, err := store.RecordVote(Vote{Asset: "proxy", Channel: "C1", Message: message, User: "U1", Helpful: false}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the vote is forgotten once it is older than the retention, so changing it must not count it twice
+	clock.SetTime(clock.Now().Add(voteRetention + time.Hour))
+	if err := store.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.RecordVote(Vote{Asset: "proxy", Channel: "C1", Message: message, User: "U1", Helpful: true}); !errors.Is(err, ErrAnswerTooOld) {
+		t.Errorf("expected the vote on an old answer to be rejected, got %v", err)
+	}
+	expected := []AssetStats{{Asset: "proxy", Unhelpful: 1}}
+	if stats := store.Stats(time.Time{}, ""); !reflect.DeepEqual(stats, expected) {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+}
+
+func TestRankStats(t *testing.T) {
+	stats := []AssetStats{
+		{Asset: "quiet", Answers: 1},
+		{Asset: "mixed", Answers: 10, Helpful: 1, Unhelpful: 1},
+		{Asset: "liked", Answers: 2, Helpful: 2},
+		{Asset: "disliked", Answers: 5, Unhelpful: 3},
+	}
+	for by, expected := range map[string][]string{
+		"helpful":   {"liked", "mixed", "disliked", "quiet"},
+		"volume":    {"mixed", "disliked", "liked", "quiet"},
+		"unhelpful": {"disliked", "mixed", "liked", "quiet"},
+	} {
+		ranked := append([]AssetStats{}, stats...)
+		rankStats(ranked, by)
+		var names []string
+		for _, asset := range ranked {
+			names = append(names, asset.Asset)
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("expected ranking by %s to be %v, got %v", by, expected, names)
+		}
+	}
+}
+
+func TestFeedbackMute(t *testing.T) {
+	withKnowledgeSet(t)
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	store, _ := OpenFeedback("", clock)
+	withFeedback(t, store, MutePolicy{UnhelpfulPercent: 60, MinVotes: 2})
+	dir := t.TempDir()
+	writePrompt(t, dir, "proxy", "  tokens: [\"proxy\"]\nowners: [\"UOWNER\"]")
+	if _, err := reloadKnowledge(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := util.NewFakeClient()
+	ctx := context.TODO()
+	event := &slackevents.MessageEvent{Channel: "C1"}
+
+	answer := func() string {
+		t.Helper()
+		response, err := defaultKnowledgeHandler(ctx, client, event, []string{"proxy"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return util.RenderMsgOptions(response...)
+	}
+	if response := answer(); !strings.Contains(response, "proxy prompt") || !strings.Contains(response, "[:+1: Helpful] [:-1: Not helpful]") {
+		t.Fatalf("expected the answer to have feedback buttons, got:\n%s", response)
+	}
+
+	vote := func(user string, helpful bool) string {
+		t.Helper()
+		action := KnowledgeCommandAttributes.Actions[0]
+		if !helpful {
+			action = KnowledgeCommandAttributes.Actions[1]
+		}
+		callback := slack.InteractionCallback{User: slack.User{ID: user}, Channel: slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}}}
+		callback.Message.Timestamp = fmt.Sprintf("%d.000100", clock.Now().Unix())
+		response, err := action.Handler(ctx, client, callback, &slack.BlockAction{ActionID: action.ActionID, Value: "proxy"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return util.RenderMsgOptions(response...)
+	}
+	if response := vote("U1", false); response != "thanks for the feedback!" {
+		t.Errorf("expected the vote to be recorded, got %q", response)
+	}
+	if _, muted := store.Muted("proxy"); muted {
+		t.Fatalf("expected proxy not to be muted before it has enough votes")
+	}
+	vote("U2", false)
+	if _, muted := store.Muted("proxy"); !muted {
+		t.Fatalf("expected proxy to be muted")
+	}
+	message, ok := client.LastMessage()
+	if !ok || message.Channel != "DUOWNER" || !strings.Contains(message.Render(), "*proxy* was muted because 2 of 2 votes rated its answers unhelpful") {
+		t.Errorf("expected the owner to be notified, got %+v", message)
+	}
+	if response := answer(); len(response) > 0 {
+		t.Errorf("expected a muted asset not to answer, got:\n%s", response)
+	}
+
+	args := data.NewParsedArgs(nil)
+	args.Set("by", "unhelpful")
+	args.Set("since", 24*time.Hour)
+	args.Set("limit", 20)
+	response, err := KnowledgeStatsAttributes.ParsedCallback(ctx, client, event, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rendered := util.RenderMsgOptions(response...); !strings.Contains(rendered, "proxy (muted)  1        0        2          0%         100%") {
+		t.Errorf("expected the stats to show proxy, got:\n%s", rendered)
+	}
+
+	args = data.NewParsedArgs(nil)
+	args.Set("asset", "proxy")
+	if _, err := KnowledgeUnmuteAttributes.ParsedCallback(ctx, client, event, args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response := answer(); !strings.Contains(response, "proxy prompt") {
+		t.Errorf("expected the unmuted asset to answer, got:\n%s", response)
+	}
+	// votes cast before the asset was unmuted don't count toward muting it again
+	clock.SetTime(clock.Now().Add(time.Minute))
+	vote("U3", false)
+	if _, muted := store.Muted("proxy"); muted {
+		t.Errorf("expected proxy not to be muted again by one vote")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 
@@ -13,11 +14,10 @@ import (
 	"github.com/openshift-splat-team/splat-bot/data"
 	"github.com/openshift-splat-team/splat-bot/pkg/commands"
 	"github.com/openshift-splat-team/splat-bot/pkg/knowledge/platforms"
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
-	"go.uber.org/thriftrw/ptr"
-	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -29,46 +29,13 @@ const (
 )
 
 var (
-	knowledgeAssets  = []data.KnowledgeAsset{}
 	knowledgeEntries = []data.Knowledge{}
-	channelIDMap     = map[string]string{}
-	slackClient      util.SlackClientInterface
-	exprOptions      = []expr.Option{}
+	// channelIDMap caches the names of channels by ID. Messages are matched concurrently so it is guarded by channelMu.
+	channelIDMap = map[string]string{}
+	channelMu    sync.Mutex
+	exprOptions  = []expr.Option{}
 )
 
-func DumpMatchTree(match data.TokenMatch, depth *int64, messages []string) []string {
-	if depth == nil {
-		depth = ptr.Int64(0)
-	} else {
-		*depth++
-	}
-	if messages == nil {
-		messages = []string{}
-	}
-	padding := strings.Repeat(" ", int(*depth))
-	matchType := "OR"
-	if len(match.Type) > 0 {
-		matchType = match.Type
-	}
-	messages = append(messages, fmt.Sprintf("%s Match: %t; Match Type: %s", padding, match.Satisfied, matchType))
-	messages = append(messages, fmt.Sprintf("%s Immediate Tokens: %s", padding, strings.Join(match.Tokens, ",")))
-	if len(match.Terms) > 0 {
-		messages = append(messages, fmt.Sprintf("%s Number of Descendant Terms(all terms must match in addition to tokens): %d", padding, len(match.Terms)))
-		for _, term := range match.Terms {
-			messages = DumpMatchTree(term, depth, messages)
-		}
-	}
-	*depth--
-	return messages
-}
-
-func getCachedClient() (util.SlackClientInterface, error) {
-	if slackClient == nil {
-		return util.GetClient()
-	}
-	return slackClient, nil
-}
-
 func IsMatch(asset data.KnowledgeAsset, tokens []string) bool {
 	if DEBUG_CONDITION_MATCHING {
 		log.Printf("+++++++++++++++++++++++++++++++++++++++IsMatch")
@@ -78,7 +45,7 @@ func IsMatch(asset data.KnowledgeAsset, tokens []string) bool {
 			log.Printf("---------------------------------------IsMatch")
 		}()
 	}
-	return isTokenMatch(&asset.On, util.NormalizeTokens(tokens))
+	return isTokenMatch(&asset.On, util.NormalizeTokens(tokens), strings.Join(tokens, " "))
 }
 
 func IsStringMatch(asset data.KnowledgeAsset, str string) bool {
@@ -94,19 +61,23 @@ func IsStringMatch(asset data.KnowledgeAsset, str string) bool {
 	return IsMatch(asset, tokens)
 }
 
-var depth = 0
+// isTokenMatch checks if the condition is satisfied by the normalized tokens and the text of a message
+func isTokenMatch(match *data.TokenMatch, tokens map[string]string, text string) bool {
+	return isTokenMatchAt(match, tokens, text, 1)
+}
 
-func isTokenMatch(match *data.TokenMatch, tokens map[string]string) bool {
+// isTokenMatchAt checks if the condition, nested depth deep in the tree of conditions, is satisfied. depth indents
+// the debug logs.
+func isTokenMatchAt(match *data.TokenMatch, tokens map[string]string, text string, depth int) bool {
 	if match.CompiledExpr != nil {
 		log.Debugf("checking message against expression: %s", match.Expr)
-		result, err := expr.Run(match.CompiledExpr, map[string]interface{}{"tokens": tokens})
+		result, err := expr.Run(match.CompiledExpr, exprEnv(tokens, text))
 		if err != nil {
 			log.Warnf("unable to run expression on match condition: %v", err)
 			return false
 		}
 		return result.(bool)
 	}
-	depth++
 	padding := strings.Repeat("  ", depth)
 	log.Debugf("%s+isTokenMatch", padding)
 	tokensMatch := true
@@ -114,19 +85,24 @@ func isTokenMatch(match *data.TokenMatch, tokens map[string]string) bool {
 
 	if len(match.Tokens) > 0 {
 		if or {
-			tokensMatch = util.TokensPresentOR(tokens, match.Tokens...)
+			tokensMatch = match.Options.AnyPresent(tokens, match.Tokens...)
 			log.Debugf("%sdo any tokens match? %v", padding, tokensMatch)
 		} else {
-			tokensMatch = util.TokensPresentAND(tokens, match.Tokens...)
+			tokensMatch = match.Options.AllPresent(tokens, match.Tokens...)
 			log.Debugf("%sdo all tokens match? %v", padding, tokensMatch)
 		}
 	}
 
+	if tokensMatch && hasTextConditions(match) {
+		tokensMatch = isTextMatch(match, text)
+		log.Debugf("%sdo the phrase, sequence and regex match? %v", padding, tokensMatch)
+	}
+
 	log.Debugf("%stokensMatch: %t; number of match terms: %d", padding, tokensMatch, len(match.Terms))
 	if tokensMatch && len(match.Terms) > 0 {
 		satisfied := 0
 		for idx := range match.Terms {
-			tokenMatch := isTokenMatch(&match.Terms[idx], tokens)
+			tokenMatch := isTokenMatchAt(&match.Terms[idx], tokens, text, depth+1)
 			if tokenMatch {
 				satisfied++
 				log.Debugf("%s+term satisfied: %d", padding, satisfied)
@@ -142,25 +118,19 @@ func isTokenMatch(match *data.TokenMatch, tokens map[string]string) bool {
 	}
 
 	log.Debugf("%s-tokensMatch: %t", padding, tokensMatch)
-	depth--
-	match.Satisfied = tokensMatch
 	return tokensMatch
 }
 
-func defaultKnowledgeEventHandler(ctx context.Context, client util.SlackClientInterface, eventsAPIEvent *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
-	return defaultKnowledgeHandler(ctx, args, eventsAPIEvent)
-}
-
-func getChannelName(channelID string) (string, error) {
-	slackClient, err := getCachedClient()
-	if err != nil {
-		return "", fmt.Errorf("unable to get client: %v", err)
-	}
-	if name, ok := channelIDMap[channelID]; ok {
+// getChannelName looks up the name of a channel with the client the message was received by
+func getChannelName(client util.SlackClientInterface, channelID string) (string, error) {
+	channelMu.Lock()
+	name, ok := channelIDMap[channelID]
+	channelMu.Unlock()
+	if ok {
 		return name, nil
 	}
 
-	channel, err := slackClient.GetConversationInfo(
+	channel, err := client.GetConversationInfo(
 		&slack.GetConversationInfoInput{
 			ChannelID: channelID,
 		},
@@ -168,22 +138,52 @@ func getChannelName(channelID string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("error getting channel info: %v", err)
 	}
+	channelMu.Lock()
 	channelIDMap[channelID] = channel.Name
+	channelMu.Unlock()
 	return channel.Name, nil
 }
 
-func defaultKnowledgeHandler(ctx context.Context, args []string, eventsAPIEvent *slackevents.MessageEvent) ([]slack.MsgOption, error) {
+// evaluation is the outcome of matching a message against a knowledge asset
+type evaluation struct {
+	Asset data.KnowledgeAsset
+	// Skipped why the asset was not matched against the message, if it was not
+	Skipped string
+	// Tokens the normalized tokens the asset was matched against, including the tokens added by channel contexts
+	Tokens map[string]string
+	// Text the text of the message
+	Text    string
+	Matched bool
+	Score   Score
+	// Cooldown the cooldown of the asset in the channel the message was sent in
+	Cooldown cooldown
+	// Cooling why the asset may not answer the message, if it matched while cooling down
+	Cooling string
+}
+
+// evaluateKnowledge matches the message against each of the active knowledge assets
+func evaluateKnowledge(client util.SlackClientInterface, args []string, eventsAPIEvent *slackevents.MessageEvent) ([]evaluation, error) {
 	var channel string
 	var err error
-	var matches []data.KnowledgeAsset
+	var evaluations []evaluation
+	// channel contexts add tokens but phrases, sequences and regexes are only matched against the message
+	text := strings.Join(args, " ")
 
+	// the active set may be replaced by a reload while the message is matched
+	set := getKnowledgeSet()
+	knowledgeAssets := set.assets
 	for idx, entry := range knowledgeAssets {
+		if mute, muted := getFeedback().Muted(entry.Name); muted {
+			evaluations = append(evaluations, evaluation{Asset: entry, Skipped: fmt.Sprintf("the asset was muted on %s because %s", mute.Time.Format(feedbackDayLayout), mute.Reason)})
+			continue
+		}
 		if !entry.WatchThreads && eventsAPIEvent.ThreadTimeStamp != "" {
+			evaluations = append(evaluations, evaluation{Asset: entry, Skipped: "the asset does not respond in threads"})
 			continue
 		}
 		if entry.ChannelContext != nil {
 			if channel == "" {
-				channel, err = getChannelName(eventsAPIEvent.Channel)
+				channel, err = getChannelName(client, eventsAPIEvent.Channel)
 				if err != nil {
 					return nil, fmt.Errorf("error getting channel name: %v", err)
 				}
@@ -201,7 +201,7 @@ func defaultKnowledgeHandler(ctx context.Context, args []string, eventsAPIEvent
 		}
 		if len(entry.RequireInChannel) > 0 {
 			if channel == "" {
-				channel, err = getChannelName(eventsAPIEvent.Channel)
+				channel, err = getChannelName(client, eventsAPIEvent.Channel)
 				if err != nil {
 					return nil, fmt.Errorf("error getting channel name: %v", err)
 				}
@@ -214,30 +214,101 @@ func defaultKnowledgeHandler(ctx context.Context, args []string, eventsAPIEvent
 				}
 			}
 			if !allowed {
+				evaluations = append(evaluations, evaluation{Asset: entry, Skipped: fmt.Sprintf("the asset only responds in %s", strings.Join(entry.RequireInChannel, ", "))})
 				continue
 			}
 		}
-		if isTokenMatch(&knowledgeAssets[idx].On, util.NormalizeTokens(args)) {
-			matches = append(matches, entry)
+		result := evaluation{Asset: entry, Tokens: util.NormalizeTokens(args), Text: text}
+		if !isTokenMatch(&knowledgeAssets[idx].On, result.Tokens, text) {
+			evaluations = append(evaluations, result)
+			continue
+		}
+		if set.cooldowns.hasChannelCooldowns() && channel == "" {
+			channel, err = getChannelName(client, eventsAPIEvent.Channel)
+			if err != nil {
+				return nil, fmt.Errorf("error getting channel name: %v", err)
+			}
+		}
+		result.Cooldown = set.cooldowns.resolveCooldown(entry, channel)
+		// a cooling asset still takes part in ranking so that a weaker asset doesn't respond in its place
+		result.Cooling, _ = cooldowns.cooling(entry.Name, eventsAPIEvent.Channel, eventsAPIEvent.User, result.Cooldown)
+		result.Matched = true
+		result.Score = scoreAsset(knowledgeAssets[idx], result.Tokens, text)
+		evaluations = append(evaluations, result)
+	}
+	return evaluations, nil
+}
+
+// matchKnowledge returns the assets which match the message ranked by their score
+func matchKnowledge(client util.SlackClientInterface, args []string, eventsAPIEvent *slackevents.MessageEvent) ([]scoredMatch, error) {
+	evaluations, err := evaluateKnowledge(client, args, eventsAPIEvent)
+	if err != nil {
+		return nil, err
+	}
+	return rankEvaluations(evaluations), nil
+}
+
+// rankEvaluations returns the assets which matched ranked by their score
+func rankEvaluations(evaluations []evaluation) []scoredMatch {
+	var matches []scoredMatch
+	for _, result := range evaluations {
+		if result.Matched {
+			matches = append(matches, scoredMatch{Asset: result.Asset, Score: result.Score, Cooldown: result.Cooldown, Cooling: result.Cooling})
+		}
+	}
+	rankMatches(matches)
+	return matches
+}
+
+// suggestionsResponse lists assets which matched a message equally well along with their links
+func suggestionsResponse(matches []scoredMatch) []slack.MsgOption {
+	lines := []string{"You might be asking about:"}
+	for _, match := range matches {
+		line := fmt.Sprintf("• *%s*", match.Asset.Name)
+		if len(match.Asset.URLS) > 0 {
+			line = fmt.Sprintf("%s: %s", line, strings.Join(match.Asset.URLS, ", "))
 		}
+		lines = append(lines, line)
+	}
+	return util.StringToBlockUnfurl(strings.Join(lines, "\n"), false, false)
+}
+
+func defaultKnowledgeHandler(ctx context.Context, client util.SlackClientInterface, eventsAPIEvent *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+	matches, err := matchKnowledge(client, args, eventsAPIEvent)
+	if err != nil {
+		return nil, err
 	}
 
 	var response []slack.MsgOption
-	// TO-DO: how can we handle multiple matches? for now we'll just use the first one
-	if len(matches) > 0 {
-		match := matches[0]
+	responding, suggest := respondingMatches(matches)
+	// a concurrent message may have been answered by the same assets since they were matched
+	var strong []scoredMatch
+	for idx, match := range responding {
+		if cooldowns.claim(match.Asset.Name, eventsAPIEvent.Channel, eventsAPIEvent.User, match.Cooldown) {
+			strong = append(strong, match)
+		} else if idx == 0 {
+			return nil, nil
+		}
+	}
+	if suggest {
+		for _, match := range strong {
+			metrics.KnowledgeMatches.WithLabelValues(match.Asset.Name).Inc()
+		}
+		return suggestionsResponse(strong), nil
+	}
+	if len(strong) > 0 {
+		match := strong[0].Asset
+		log.Debugf("answering with knowledge asset %s, score %s", match.Name, strong[0].Score)
+		metrics.KnowledgeMatches.WithLabelValues(match.Name).Inc()
+		getFeedback().RecordAnswer(match.Name, eventsAPIEvent.Channel)
 		// TO-DO: add support for LLM invocation
 		//if match.InvokeLLM {}
 
 		responseText := fmt.Sprintf(DEFAULT_URL_PROMPT, match.MarkdownPrompt)
 
-		if len(match.URLS) > 0 {
-			//response = append(response, slack.MsgOptionText(strings.Join(match.URLS, "\n"), false))
-			response = append(response, util.StringsToBlockWithURLs([]string{responseText}, match.URLS)...)
-		} else {
-			response = append(response, slack.MsgOptionText(responseText, true))
-		}
-
+		// the answer is followed by buttons which rate whether it was helpful
+		blocks := util.StringsToBlocksWithURLs([]string{responseText}, match.URLS)
+		response = append(response, slack.MsgOptionBlocks(append(blocks, feedbackBlock(match.Name))...))
 	}
 	return response, nil
 }
@@ -262,78 +333,14 @@ func getKnowledgeEntryPaths(path string, paths []string) ([]string, error) {
 	return paths, nil
 }
 
-func loadKnowledgeEntries(dir string) error {
-	files, err := getKnowledgeEntryPaths(dir, []string{})
-	if err != nil {
-		return fmt.Errorf("error reading knowledge prompts directory: %v", err)
-	}
-
-	for _, filePath := range files {
-		log.Debugf("loading knowledge entry from %s", filePath)
-		knowledgeModel, err := os.ReadFile(filePath)
-		if err != nil {
-			return fmt.Errorf("error reading file %s: %v", filePath, err)
-		}
-		var asset data.KnowledgeAsset
-		err = yaml.Unmarshal([]byte(knowledgeModel), &asset)
-		if err != nil {
-			log.Warnf("error unmarshalling file %s: %v", filePath, err)
-			continue
-		}
-		// if the name of a known platform appears in the path add platform specific terms
-		// to 'On' which must be met before the knowledge asset is considered a match
-		if contextTerms := platforms.GetPathContextTerms(filePath); contextTerms != nil {
-			asset.On.Terms = append(asset.On.Terms, contextTerms...)
-		}
-
-		if len(asset.On.Expr) > 0 {
-			platformExpressions := platforms.GetPathContextExpr(filePath)
-			if len(platformExpressions) > 0 {
-				asset.On.Expr = fmt.Sprintf("%s and %s", platformExpressions, asset.On.Expr)
-			}
-			asset.On.CompiledExpr, err = expr.Compile(asset.On.Expr, exprOptions...)
-			if err != nil {
-				return fmt.Errorf("error compiling knowledge expression: %v", err)
-			}
-		}
-
-		knowledgeAssets = append(knowledgeAssets, asset)
-	}
-
-	return nil
-}
-
 func init() {
-	exprOptions = append(exprOptions, expr.Function("containsAny", func(params ...any) (any, error) {
-		tokenMap := params[0].(map[string]string)
-		result := false
-		for _, param := range params[1].([]any) {
-			if _, exists := tokenMap[param.(string)]; exists {
-				result = true
-				break
-			}
-		}
-		log.Debugf("containsAny: %v; %v", result, params[1].([]any))
-		return result, nil
-	}))
-	exprOptions = append(exprOptions, expr.Function("containsAll", func(params ...any) (any, error) {
-		tokenMap := params[0].(map[string]string)
-		result := len(params[1].([]any)) > 0
-		for _, param := range params[1].([]any) {
-			if _, exists := tokenMap[param.(string)]; !exists {
-				result = false
-				break
-			}
-		}
-		log.Debugf("containsAll: %v; %v", result, params[1].([]any))
-		return result, nil
-	}))
+	exprOptions = exprFunctions(util.MatchOptions{}, nil)
 
 	promptPath := os.Getenv("PROMPT_PATH")
 	if promptPath == "" {
 		promptPath = "/usr/src/app/knowledge_prompts"
 	}
-	err := loadKnowledgeEntries(promptPath)
+	report, err := reloadKnowledge(promptPath)
 	// TODO: Need way for local developers to be able to still start application if they are not testing knowledge stuff.
 	//       For now, we will disable the commands tha require this.
 	if err != nil {
@@ -341,14 +348,30 @@ func init() {
 		log.Infof("Skipping adding of knowledge-based actions.")
 		return
 	}
+	for _, failed := range report.Failed {
+		log.Warnf("unable to load knowledge entry %s: %v", failed.Path, failed.Err)
+	}
+	if err := initializeFeedback(); err != nil {
+		log.Warnf("unable to load knowledge feedback: %v", err)
+	}
 	commands.AddCommand(KnowledgeCommandAttributes)
+	commands.AddCommand(KnowledgeReloadAttributes)
+	commands.AddCommand(KnowledgeExplainAttributes)
+	commands.AddCommand(KnowledgeStatsAttributes)
+	commands.AddCommand(KnowledgeUnmuteAttributes)
 }
 
 var KnowledgeCommandAttributes = data.Attributes{
-	Callback:           defaultKnowledgeEventHandler,
+	Callback: defaultKnowledgeHandler,
+	Actions: []data.Action{
+		feedbackAction(FeedbackHelpfulActionID, true),
+		feedbackAction(FeedbackUnhelpfulActionID, false),
+	},
 	DontGlobQuotes:     true,
 	RequireMention:     false,
 	AllowNonSplatUsers: true,
+	Category:           data.CategoryKnowledge,
+	HelpMarkdown:       "answers common questions when a message matches one of the bot's knowledge entries. no mention is needed",
 	MessageOfInterest: func(args []string, attribute data.Attributes, channel string) bool {
 		for _, entry := range knowledgeEntries {
 			if entry.MessageOfInterest(args, attribute, channel) {
@@ -2,14 +2,20 @@ package knowledge
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/expr-lang/expr"
 	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/commands"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
+	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/slackutilsx"
 	"gopkg.in/yaml.v3"
 )
 
@@ -116,7 +122,7 @@ func TestExpr(t *testing.T) {
 				t.Fatalf("unable to compile expression: %v", err)
 				return
 			}
-			result, err := expr.Run(program, map[string]interface{}{"tokens": tokens})
+			result, err := expr.Run(program, exprEnv(tokens, "this is a test of expressions"))
 			if err != nil {
 				t.Fatalf("unable to execute expression: %v", err)
 				return
@@ -158,10 +164,59 @@ func TestYamlLogic(t *testing.T) {
 	}
 }
 
+// responseContent returns the text a response to the asset must contain
+func responseContent(asset data.KnowledgeAsset) []string {
+	expected := append([]string{strings.SplitN(strings.TrimSpace(asset.MarkdownPrompt), "\n", 2)[0]}, asset.URLS...)
+	if len(asset.URLS) == 0 {
+		// responses without links are escaped
+		expected[0] = slackutilsx.EscapeMessage(expected[0])
+	}
+	return expected
+}
+
+// checkKnowledgeResponse checks that asset is among the strong matches of the message and that the response is the
+// outcome expected from them: the answer of asset when it is the only strong match, or suggestions which include asset
+// when several assets score as well.
+func checkKnowledgeResponse(asset data.KnowledgeAsset, strong []scoredMatch, response []slack.MsgOption) error {
+	var names []string
+	for _, match := range strong {
+		names = append(names, match.Asset.Name)
+	}
+	if !slices.Contains(names, asset.Name) {
+		return fmt.Errorf("expected %s to be among the strong matches, got %v", asset.Name, names)
+	}
+	rendered := util.RenderMsgOptions(response...)
+	if len(strong) > 1 {
+		if !strings.HasPrefix(rendered, "You might be asking about:") || !strings.Contains(rendered, fmt.Sprintf("• *%s*", asset.Name)) {
+			return fmt.Errorf("expected %s to be suggested along with %v, got:\n%s", asset.Name, names, rendered)
+		}
+		return nil
+	}
+	if !strings.HasPrefix(rendered, strings.SplitN(DEFAULT_URL_PROMPT, "\n", 2)[0]) {
+		return fmt.Errorf("expected %s to answer with the default prompt, got:\n%s", asset.Name, rendered)
+	}
+	for _, text := range responseContent(asset) {
+		if !strings.Contains(rendered, text) {
+			return fmt.Errorf("expected the answer of %s to contain %q, got:\n%s", asset.Name, text, rendered)
+		}
+	}
+	return nil
+}
+
 func TestModelLoading(t *testing.T) {
 	ctx := context.TODO()
-	assets := knowledgeAssets
-	slackClient = &util.StubInterface{}
+	assets := getKnowledgeAssets()
+	client := util.NewFakeClient()
+	for _, name := range []string{"test", "random", "vmware"} {
+		client.Channels[name] = name
+	}
+	for _, asset := range assets {
+		if asset.ChannelContext != nil {
+			for _, channel := range asset.ChannelContext.Channels {
+				client.Channels[channel] = channel
+			}
+		}
+	}
 	for _, asset := range assets {
 		t.Run(asset.Name, func(t *testing.T) {
 			channelName := "test"
@@ -186,16 +241,24 @@ func TestModelLoading(t *testing.T) {
 					Text:    should,
 					Channel: channelName,
 				}
-				responses, err := defaultKnowledgeHandler(ctx, tokens, msgEvent)
+				matches, err := matchKnowledge(client, tokens, msgEvent)
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				strong := strongMatches(matches)
+				responses, err := defaultKnowledgeHandler(ctx, client, msgEvent, tokens)
 				if err != nil || len(responses) == 0 {
-					dump := DumpMatchTree(asset.On, nil, nil)
-					t.Fatalf("expected to match: %s\nOn: %s", should, strings.Join(dump, "\n"))
+					dump := explainTokenMatch(&asset.On, util.NormalizeTokens(tokens), strings.Join(tokens, " "), 0, nil)
+					t.Fatalf("expected to match: %s\nOn:\n%s", should, strings.Join(dump, "\n"))
 					return
 				}
+				if err := checkKnowledgeResponse(asset, strong, responses); err != nil {
+					t.Fatalf("unexpected response to %s: %v", should, err)
+				}
 				if !asset.WatchThreads {
-					response, err := defaultKnowledgeHandler(ctx, tokens, &slackevents.MessageEvent{
+					response, err := defaultKnowledgeHandler(ctx, client, &slackevents.MessageEvent{
 						ThreadTimeStamp: time.Now().String(),
-					})
+					}, tokens)
 					if err != nil {
 						t.Fatalf("expected no error, got: %v", err)
 						return
@@ -212,15 +275,69 @@ func TestModelLoading(t *testing.T) {
 					Text:    shouldnt,
 					Channel: "random",
 				}
-				_, err := defaultKnowledgeHandler(ctx, tokens, msgEvent)
+				_, err := defaultKnowledgeHandler(ctx, client, msgEvent, tokens)
 				if err != nil {
-					dump := DumpMatchTree(asset.On, nil, nil)
-					t.Fatalf("On: %s", strings.Join(dump, "\n"))
-					t.Fatalf("expected not to match: %s\nOn: %s", shouldnt, strings.Join(dump, "\n"))
-
-					return
+					dump := explainTokenMatch(&asset.On, util.NormalizeTokens(tokens), strings.Join(tokens, " "), 0, nil)
+					t.Fatalf("expected not to match: %s\nOn:\n%s", shouldnt, strings.Join(dump, "\n"))
 				}
 			}
 		})
 	}
 }
+
+// TestConcurrentHandler handles messages on several workers at once, the way the dispatcher does, to catch data races
+// in the knowledge catch-all when run with -race
+func TestConcurrentHandler(t *testing.T) {
+	withKnowledgeSet(t)
+	const messages = 32
+	client := util.NewFakeClient()
+	var channels []string
+	for idx := 0; idx < messages; idx++ {
+		name := fmt.Sprintf("forum-concurrent-%d", idx)
+		client.Channels[fmt.Sprintf("CCONCURRENT%d", idx)] = name
+		channels = append(channels, fmt.Sprintf("%q", name))
+	}
+	dir := t.TempDir()
+	writePrompt(t, dir, "proxy", fmt.Sprintf(`  tokens: ["proxy"]
+  terms:
+  - type: or
+    tokens: ["configure", "mirror"]
+must_be_in_channels: [%s]`, strings.Join(channels, ", ")))
+	if _, err := reloadKnowledge(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the messages are released together so that they're matched at the same time
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for idx := 0; idx < messages; idx++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			<-start
+			evt := slackevents.EventsAPIEvent{
+				Type: "message",
+				InnerEvent: slackevents.EventsAPIInnerEvent{
+					Type: "message",
+					Data: &slackevents.MessageEvent{
+						Type:        "message",
+						ChannelType: "channel",
+						Text:        "how do I configure a proxy",
+						Channel:     fmt.Sprintf("CCONCURRENT%d", idx),
+						User:        fmt.Sprintf("U%d", idx),
+						TimeStamp:   fmt.Sprintf("1700000000.%06d", idx),
+					},
+				},
+			}
+			if err := commands.Handler(context.TODO(), client, evt); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(idx)
+	}
+	close(start)
+	wg.Wait()
+
+	if answered := len(client.Messages()); answered != messages {
+		t.Errorf("expected every message to be answered, got %d answers", answered)
+	}
+}
@@ -0,0 +1,251 @@
+package knowledge
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/knowledge/platforms"
+)
+
+// SampleResult is the outcome of matching a should_match or shouldnt_match sample against the asset it belongs to
+type SampleResult struct {
+	Asset string
+	Path  string
+	// Sample the sample message
+	Sample string
+	// ShouldMatch is true for should_match samples
+	ShouldMatch bool
+	Matched     bool
+}
+
+// Passed is true when the sample matched if and only if it should
+func (r SampleResult) Passed() bool {
+	return r.ShouldMatch == r.Matched
+}
+
+func (r SampleResult) kind() string {
+	if r.ShouldMatch {
+		return "should_match"
+	}
+	return "shouldnt_match"
+}
+
+// Overlap is a should_match sample of one asset which also matches another asset
+type Overlap struct {
+	Asset     string
+	Path      string
+	Sample    string
+	Other     string
+	OtherPath string
+}
+
+// LintReport describes the problems found in a prompt directory
+type LintReport struct {
+	// Load the files which loaded and which failed to read, unmarshal or compile
+	Load LoadReport
+	// Missing the assets which have no should_match or shouldnt_match samples
+	Missing []FileError
+	Samples []SampleResult
+	// Overlaps the samples which match more than one asset
+	Overlaps []Overlap
+}
+
+// Failures returns the number of files which failed to load, assets without samples and samples which did not
+// match as expected. Overlaps are counted when overlapFails is true.
+func (r LintReport) Failures(overlapFails bool) int {
+	failures := len(r.Load.Failed) + len(r.Missing)
+	for _, sample := range r.Samples {
+		if !sample.Passed() {
+			failures++
+		}
+	}
+	if overlapFails {
+		failures += len(r.Overlaps)
+	}
+	return failures
+}
+
+// sampleTokens returns the tokens of a sample. Samples of assets with a channel context are treated as if they were
+// sent in one of the channels.
+func sampleTokens(asset data.KnowledgeAsset, sample string) []string {
+	tokens := strings.Split(sample, " ")
+	if asset.ChannelContext != nil && len(asset.ChannelContext.Channels) > 0 {
+		for _, term := range platforms.GetPathContextTerms(asset.ChannelContext.ContextPath) {
+			tokens = append(tokens, term.Tokens...)
+		}
+	}
+	return tokens
+}
+
+// Lint loads the assets in dir the same way the bot does, matches the samples of each asset against it and finds
+// the should_match samples which match other assets. An error is returned if dir can not be read.
+func Lint(dir string) (LintReport, error) {
+	set, load, err := loadKnowledgeSet(dir)
+	report := LintReport{Load: load}
+	if err != nil {
+		return report, err
+	}
+
+	// the assets are loaded in the same order as the files in Loaded
+	for idx, asset := range set.assets {
+		path := load.Loaded[idx]
+		if len(asset.ShouldMatch) == 0 {
+			report.Missing = append(report.Missing, FileError{Path: path, Err: fmt.Errorf("%s has no should_match samples", asset.Name)})
+		}
+		if len(asset.ShouldntMatch) == 0 {
+			report.Missing = append(report.Missing, FileError{Path: path, Err: fmt.Errorf("%s has no shouldnt_match samples", asset.Name)})
+		}
+		for _, sample := range asset.ShouldMatch {
+			report.Samples = append(report.Samples, SampleResult{
+				Asset:       asset.Name,
+				Path:        path,
+				Sample:      sample,
+				ShouldMatch: true,
+				Matched:     IsMatch(asset, sampleTokens(asset, sample)),
+			})
+			for otherIdx, other := range set.assets {
+				if otherIdx == idx || !IsMatch(other, sampleTokens(asset, sample)) {
+					continue
+				}
+				report.Overlaps = append(report.Overlaps, Overlap{
+					Asset:     asset.Name,
+					Path:      path,
+					Sample:    sample,
+					Other:     other.Name,
+					OtherPath: load.Loaded[otherIdx],
+				})
+			}
+		}
+		for _, sample := range asset.ShouldntMatch {
+			report.Samples = append(report.Samples, SampleResult{
+				Asset:   asset.Name,
+				Path:    path,
+				Sample:  sample,
+				Matched: IsMatch(asset, sampleTokens(asset, sample)),
+			})
+		}
+	}
+	return report, nil
+}
+
+// WriteText writes a human readable report of the problems found to w
+func (r LintReport) WriteText(w io.Writer, overlapFails bool) error {
+	var builder strings.Builder
+	for _, failed := range r.Load.Failed {
+		fmt.Fprintf(&builder, "FAIL %s: %v\n", r.Load.relative(failed.Path), failed.Err)
+	}
+	for _, missing := range r.Missing {
+		fmt.Fprintf(&builder, "FAIL %s: %v\n", r.Load.relative(missing.Path), missing.Err)
+	}
+	passed := 0
+	for _, sample := range r.Samples {
+		if sample.Passed() {
+			passed++
+			continue
+		}
+		expected := "expected to match"
+		if !sample.ShouldMatch {
+			expected = "expected not to match"
+		}
+		fmt.Fprintf(&builder, "FAIL %s: %s %q %s\n", r.Load.relative(sample.Path), sample.kind(), sample.Sample, expected)
+	}
+	level := "WARN"
+	if overlapFails {
+		level = "FAIL"
+	}
+	for _, overlap := range r.Overlaps {
+		fmt.Fprintf(&builder, "%s %s: should_match %q also matches %s (%s)\n", level, r.Load.relative(overlap.Path), overlap.Sample, overlap.Other, r.Load.relative(overlap.OtherPath))
+	}
+	fmt.Fprintf(&builder, "%d files loaded, %d failed to load, %d of %d samples passed, %d overlapping samples\n",
+		len(r.Load.Loaded), len(r.Load.Failed), passed, len(r.Samples), len(r.Overlaps))
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (s *junitTestSuite) add(testCase junitTestCase) {
+	s.Tests++
+	if testCase.Failure != nil {
+		s.Failures++
+	}
+	s.TestCases = append(s.TestCases, testCase)
+}
+
+// WriteJUnit writes the report to w as JUnit XML. Each file, sample and overlap is a test case.
+func (r LintReport) WriteJUnit(w io.Writer, overlapFails bool) error {
+	load := junitTestSuite{Name: "load"}
+	for _, path := range r.Load.Loaded {
+		load.add(junitTestCase{Name: r.Load.relative(path), ClassName: "load"})
+	}
+	for _, failed := range r.Load.Failed {
+		load.add(junitTestCase{Name: r.Load.relative(failed.Path), ClassName: "load", Failure: &junitFailure{Message: failed.Err.Error()}})
+	}
+	for _, missing := range r.Missing {
+		load.add(junitTestCase{Name: r.Load.relative(missing.Path) + " samples", ClassName: "load", Failure: &junitFailure{Message: missing.Err.Error()}})
+	}
+
+	samples := junitTestSuite{Name: "samples"}
+	for _, sample := range r.Samples {
+		testCase := junitTestCase{Name: fmt.Sprintf("%s %s: %s", sample.Asset, sample.kind(), sample.Sample), ClassName: r.Load.relative(sample.Path)}
+		if !sample.Passed() {
+			testCase.Failure = &junitFailure{Message: fmt.Sprintf("matched: %t", sample.Matched)}
+		}
+		samples.add(testCase)
+	}
+
+	overlaps := junitTestSuite{Name: "overlaps"}
+	for _, overlap := range r.Overlaps {
+		message := fmt.Sprintf("should_match %q of %s also matches %s (%s)", overlap.Sample, overlap.Asset, overlap.Other, r.Load.relative(overlap.OtherPath))
+		testCase := junitTestCase{Name: fmt.Sprintf("%s overlaps %s", overlap.Asset, overlap.Other), ClassName: r.Load.relative(overlap.Path)}
+		if overlapFails {
+			testCase.Failure = &junitFailure{Message: message}
+		} else {
+			testCase.SystemOut = message
+		}
+		overlaps.add(testCase)
+	}
+
+	suites := junitTestSuites{Suites: []junitTestSuite{load, samples, overlaps}}
+	for _, suite := range suites.Suites {
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return fmt.Errorf("unable to encode JUnit report: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
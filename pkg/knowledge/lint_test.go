@@ -0,0 +1,72 @@
+package knowledge
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "proxy", "  tokens: [\"proxy\"]\nshould_match: [\"proxy settings\"]\nshouldnt_match: [\"proxy\"]")
+	writePrompt(t, dir, "settings", "  tokens: [\"settings\"]\nshould_match: [\"where are the settings\"]\nshouldnt_match: [\"nothing\"]")
+	writePrompt(t, dir, "upgrade", `  tokens: ["upgrade"]`)
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("name: test\non:\n  expr: containsAny(tokens"), 0o644); err != nil {
+		t.Fatalf("unable to write prompt: %v", err)
+	}
+
+	report, err := Lint(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the broken file, the samples missing from upgrade and the shouldnt_match sample of proxy
+	if failures := report.Failures(false); failures != 4 {
+		t.Errorf("expected 4 failures, got %d: %+v", failures, report)
+	}
+	if failures := report.Failures(true); failures != 5 {
+		t.Errorf("expected the overlap to fail, got %d failures", failures)
+	}
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"FAIL broken.yaml: error compiling knowledge expression",
+		"FAIL upgrade.yaml: upgrade has no should_match samples",
+		"FAIL proxy.yaml: shouldnt_match \"proxy\" expected not to match",
+		"WARN proxy.yaml: should_match \"proxy settings\" also matches settings (settings.yaml)",
+		"3 files loaded, 1 failed to load, 3 of 4 samples passed, 1 overlapping samples",
+	} {
+		if !strings.Contains(text.String(), expected) {
+			t.Errorf("expected the report to contain %q, got:\n%s", expected, text.String())
+		}
+	}
+
+	var junit bytes.Buffer
+	if err := report.WriteJUnit(&junit, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(junit.Bytes(), &suites); err != nil {
+		t.Fatalf("expected valid XML, got %v:\n%s", err, junit.String())
+	}
+	if suites.Tests != 11 || suites.Failures != 5 || len(suites.Suites) != 3 {
+		t.Errorf("expected 11 tests with 5 failures, got %d tests with %d failures", suites.Tests, suites.Failures)
+	}
+}
+
+func TestLintPrompts(t *testing.T) {
+	report, err := Lint(filepath.Join("test", "knowledge_prompts"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failures := report.Failures(false); failures != 0 {
+		var text bytes.Buffer
+		_ = report.WriteText(&text, false)
+		t.Errorf("expected the test prompts to pass, got:\n%s", text.String())
+	}
+}
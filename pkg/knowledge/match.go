@@ -0,0 +1,169 @@
+package knowledge
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/expr-lang/expr"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// synonymsFile is the synonym dictionary shared by the assets in a prompt directory. It must be at the top of the
+// directory and is not loaded as an asset.
+const synonymsFile = "synonyms.yaml"
+
+// synonymDictionary is the content of the synonyms file
+type synonymDictionary struct {
+	// Synonyms groups of words which mean the same thing
+	Synonyms [][]string `yaml:"synonyms"`
+}
+
+// loadSynonyms loads the synonym dictionary at path. A missing dictionary is empty.
+func loadSynonyms(path string) (util.Synonyms, string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return util.Synonyms{}, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading: %v", err)
+	}
+	var dictionary synonymDictionary
+	if err := yaml.UnmarshalStrict(content, &dictionary); err != nil {
+		return nil, "", fmt.Errorf("error unmarshalling: %v", err)
+	}
+	return util.NewSynonyms(dictionary.Synonyms), string(content), nil
+}
+
+// resolveConditions sets the options of each condition in the tree rooted at match from its modes and compiles
+// its regex. Conditions without modes inherit the options of their parent.
+func resolveConditions(match *data.TokenMatch, inherited util.MatchOptions, synonyms util.Synonyms) error {
+	match.Options = inherited
+	if len(match.Match) > 0 {
+		options, err := util.ParseMatchOptions(match.Match, match.MaxDistance, synonyms)
+		if err != nil {
+			return fmt.Errorf("error resolving match modes: %v", err)
+		}
+		match.Options = options
+	}
+	if len(match.Regex) > 0 {
+		regex, err := regexp.Compile(match.Regex)
+		if err != nil {
+			return fmt.Errorf("error compiling regex: %v", err)
+		}
+		match.CompiledRegex = regex
+	}
+	for idx := range match.Terms {
+		if err := resolveConditions(&match.Terms[idx], match.Options, synonyms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasTextConditions returns true if the condition has a phrase, sequence or regex
+func hasTextConditions(match *data.TokenMatch) bool {
+	return len(match.Phrase) > 0 || len(match.Sequence) > 0 || match.CompiledRegex != nil
+}
+
+// isTextMatch checks if the text satisfies the phrase, sequence and regex of the condition
+func isTextMatch(match *data.TokenMatch, text string) bool {
+	if len(match.Phrase) > 0 && !util.ContainsPhrase(text, match.Phrase) {
+		return false
+	}
+	if len(match.Sequence) > 0 && !util.ContainsSequence(text, match.Sequence...) {
+		return false
+	}
+	if match.CompiledRegex != nil && !util.MatchesRegex(match.CompiledRegex, text) {
+		return false
+	}
+	return true
+}
+
+// exprEnv is the environment expressions are run in. tokens are the normalized tokens of the message and text is
+// the text of the message.
+func exprEnv(tokens map[string]string, text string) map[string]interface{} {
+	return map[string]interface{}{
+		"tokens": tokens,
+		"text":   text,
+	}
+}
+
+// exprRegexes caches the regexes compiled by matchesRegex
+var exprRegexes sync.Map
+
+func compileExprRegex(pattern string) (*regexp.Regexp, error) {
+	if regex, ok := exprRegexes.Load(pattern); ok {
+		return regex.(*regexp.Regexp), nil
+	}
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	exprRegexes.Store(pattern, regex)
+	return regex, nil
+}
+
+// exprFunctions returns the functions expressions are compiled with. containsAny and containsAll compare tokens
+// using options unless modes are passed as a third argument, such as containsAny(tokens, ["install"], "stem,fuzzy").
+// containsPhrase, containsSequence and matchesRegex match the text of the message, such as
+// containsSequence(text, ["failed to", "bootstrap"]).
+func exprFunctions(options util.MatchOptions, synonyms util.Synonyms) []expr.Option {
+	optionsFor := func(params []any) (util.MatchOptions, error) {
+		if len(params) < 3 {
+			return options, nil
+		}
+		modes, ok := params[2].(string)
+		if !ok {
+			return options, fmt.Errorf("the match modes must be a string such as \"stem,fuzzy\"")
+		}
+		return util.ParseMatchOptions(strings.Split(modes, ","), 0, synonyms)
+	}
+	tokensOf := func(params []any) []string {
+		var tokens []string
+		for _, param := range params[1].([]any) {
+			tokens = append(tokens, param.(string))
+		}
+		return tokens
+	}
+
+	return []expr.Option{
+		expr.Function("containsAny", func(params ...any) (any, error) {
+			options, err := optionsFor(params)
+			if err != nil {
+				return false, err
+			}
+			result := options.AnyPresent(params[0].(map[string]string), tokensOf(params)...)
+			log.Debugf("containsAny: %v; %v", result, params[1].([]any))
+			return result, nil
+		}),
+		expr.Function("containsAll", func(params ...any) (any, error) {
+			options, err := optionsFor(params)
+			if err != nil {
+				return false, err
+			}
+			result := options.AllPresent(params[0].(map[string]string), tokensOf(params)...)
+			log.Debugf("containsAll: %v; %v", result, params[1].([]any))
+			return result, nil
+		}),
+		expr.Function("containsPhrase", func(params ...any) (any, error) {
+			return util.ContainsPhrase(params[0].(string), params[1].(string)), nil
+		}, new(func(string, string) bool)),
+		expr.Function("containsSequence", func(params ...any) (any, error) {
+			return util.ContainsSequence(params[0].(string), tokensOf(params)...), nil
+		}, new(func(string, []any) bool)),
+		expr.Function("matchesRegex", func(params ...any) (any, error) {
+			regex, err := compileExprRegex(params[1].(string))
+			if err != nil {
+				return false, fmt.Errorf("error compiling regex: %v", err)
+			}
+			return util.MatchesRegex(regex, params[0].(string)), nil
+		}, new(func(string, string) bool)),
+	}
+}
@@ -0,0 +1,130 @@
+package knowledge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+func TestMatchModes(t *testing.T) {
+	withKnowledgeSet(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, synonymsFile), []byte("synonyms:\n- [vcenter, vsphere]\n"), 0o644); err != nil {
+		t.Fatalf("unable to write synonyms: %v", err)
+	}
+	writePrompt(t, dir, "exact", `  tokens: ["install"]`)
+	writePrompt(t, dir, "stemmed", `  match: [stem]
+  tokens: ["install"]
+  terms:
+  - tokens: ["vsphere"]
+    match: [synonyms, fuzzy]`)
+	writePrompt(t, dir, "expression", `  match: [stem]
+  expr: containsAny(tokens, ["upgrade"]) and containsAll(tokens, ["vsphere"], "synonyms")`)
+
+	report, err := reloadKnowledge(dir)
+	if err != nil || report.Active != 3 {
+		t.Fatalf("expected the assets to load without the synonyms, got %+v: %v", report, err)
+	}
+	matches := func(message string) []string {
+		t.Helper()
+		var names []string
+		for _, asset := range getKnowledgeAssets() {
+			if IsStringMatch(asset, message) {
+				names = append(names, asset.Name)
+			}
+		}
+		return names
+	}
+	tests := map[string]string{
+		"how do I install on vsphere":         "exact,stemmed",
+		"installing on vcenter":               "stemmed",
+		"the installer fails on vsphre":       "stemmed",
+		"the installer fails on vmware":       "",
+		"upgrading vcenter":                   "expression",
+		"upgrading vsphre":                    "",
+		"install and upgrade through vcenter": "exact,expression,stemmed",
+	}
+	for message, expected := range tests {
+		if names := strings.Join(matches(message), ","); names != expected {
+			t.Errorf("expected %q to match %q, got %q", message, expected, names)
+		}
+	}
+
+	// the dictionary is not an asset and its failures are reported
+	if err := os.WriteFile(filepath.Join(dir, synonymsFile), []byte("synonyms: [vcenter"), 0o644); err != nil {
+		t.Fatalf("unable to write synonyms: %v", err)
+	}
+	writePrompt(t, dir, "unknown", "  match: [phonetic]\n  tokens: [\"install\"]")
+	report, err = reloadKnowledge(dir)
+	if err == nil || report.Applied || len(report.Failed) != 2 {
+		t.Fatalf("expected the reload to fail, got %+v: %v", report, err)
+	}
+	if rendered := report.Markdown(); !strings.Contains(rendered, "- `synonyms.yaml`: error unmarshalling") || !strings.Contains(rendered, "- `unknown.yaml`: error resolving match modes: unknown match mode \"phonetic\"") {
+		t.Errorf("expected the failures to be reported, got %q", rendered)
+	}
+}
+
+func TestTextConditions(t *testing.T) {
+	withKnowledgeSet(t)
+	dir := t.TempDir()
+	writePrompt(t, dir, "certificate", `  phrase: "x509: certificate signed by unknown authority"`)
+	writePrompt(t, dir, "bootstrap", `  tokens: ["installer"]
+  terms:
+  - sequence: ["failed to", "bootstrap"]`)
+	writePrompt(t, dir, "version", `  type: or
+  terms:
+  - regex: '4\.1[4-7]'
+  - tokens: ["latest"]`)
+	writePrompt(t, dir, "expression", `  expr: containsAny(tokens, ["proxy"]) and (containsPhrase(text, "no route to host") or matchesRegex(text, "(?i)^proxy:")) and not containsSequence(text, ["works", "now"])`)
+	if _, err := reloadKnowledge(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]string{
+		"Error: X509: certificate signed by unknown authority":      "certificate",
+		"x509 certificate signed by unknown authority when pulling": "certificate",
+		"certificate signed by an unknown authority":                "",
+		"the installer failed to wait for bootstrap":                "bootstrap",
+		"the installer bootstrap failed to complete":                "",
+		"is 4.16 supported?":                                        "version",
+		"is 4.13 supported?":                                        "",
+		"no route to host through the proxy":                        "expression",
+		"Proxy: connection refused":                                 "expression",
+		"no route to host through the proxy, works now":             "",
+	}
+	for message, expected := range tests {
+		var names []string
+		for _, asset := range getKnowledgeAssets() {
+			if IsStringMatch(asset, message) {
+				names = append(names, asset.Name)
+			}
+		}
+		if matched := strings.Join(names, ","); matched != expected {
+			t.Errorf("expected %q to match %q, got %q", message, expected, matched)
+		}
+	}
+
+	for _, asset := range getKnowledgeAssets() {
+		if asset.Name != "bootstrap" {
+			continue
+		}
+		message := "the installer failed to bootstrap"
+		lines := strings.Join(explainTokenMatch(&asset.On, util.NormalizeTokens(strings.Split(message, " ")), message, 0, nil), "\n")
+		if !strings.Contains(lines, `    ✓ and sequence: ["failed to" "bootstrap"] ✓`) {
+			t.Errorf("expected the sequence to be explained, got:\n%s", lines)
+		}
+	}
+
+	writePrompt(t, dir, "broken-regex", `  regex: '4\.1[4-'`)
+	writePrompt(t, dir, "broken-expr", `  expr: containsPhrase(text, ["failed to"])`)
+	report, err := reloadKnowledge(dir)
+	if err == nil || len(report.Failed) != 2 {
+		t.Fatalf("expected the invalid conditions to fail to load, got %+v: %v", report, err)
+	}
+	if rendered := report.Markdown(); !strings.Contains(rendered, "- `broken-regex.yaml`: error compiling regex") || !strings.Contains(rendered, "- `broken-expr.yaml`: error compiling knowledge expression") {
+		t.Errorf("expected the failures to be reported, got %q", rendered)
+	}
+}
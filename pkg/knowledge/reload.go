@@ -0,0 +1,343 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"gopkg.in/yaml.v2"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/knowledge/platforms"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// watchDelay is how long the prompt directory must be quiet before it is reloaded. Editors and ConfigMap updates
+// usually change several files at once.
+var watchDelay = 2 * time.Second
+
+// knowledgeSet is the set of assets loaded from the prompt directory. A set is never modified once it is active so
+// that a reload does not affect messages which are being matched against the previous set.
+type knowledgeSet struct {
+	dir    string
+	assets []data.KnowledgeAsset
+	// sources the content of the files the assets were loaded from keyed by path
+	sources map[string]string
+	// synonyms the content of the synonym dictionary
+	synonyms string
+	// cooldowns the default and channel cooldowns, and cooldownsSource the content of the file they were loaded from
+	cooldowns       cooldownConfig
+	cooldownsSource string
+}
+
+var (
+	knowledgeMu     sync.Mutex
+	activeKnowledge = &knowledgeSet{}
+	// reloadMu serializes reloads so that the set being replaced is the set a reload was compared against
+	reloadMu sync.Mutex
+)
+
+func getKnowledgeSet() *knowledgeSet {
+	knowledgeMu.Lock()
+	defer knowledgeMu.Unlock()
+	return activeKnowledge
+}
+
+func setKnowledgeSet(set *knowledgeSet) {
+	knowledgeMu.Lock()
+	defer knowledgeMu.Unlock()
+	activeKnowledge = set
+}
+
+// getKnowledgeAssets returns the active knowledge assets
+func getKnowledgeAssets() []data.KnowledgeAsset {
+	return getKnowledgeSet().assets
+}
+
+// FileError is a prompt file which could not be loaded
+type FileError struct {
+	Path string
+	Err  error
+}
+
+// LoadReport describes the outcome of loading the prompt directory
+type LoadReport struct {
+	Dir string
+	// Loaded the files which were loaded
+	Loaded []string
+	// Failed the files which could not be read, unmarshalled or compiled
+	Failed []FileError
+	// Added, Removed and Changed count the assets which differ from the previously active set
+	Added   int
+	Removed int
+	Changed int
+	// SynonymsChanged is true when the synonym dictionary differs from the previously active set
+	SynonymsChanged bool
+	// CooldownsChanged is true when the cooldowns file differs from the previously active set
+	CooldownsChanged bool
+	// Applied is true when the loaded assets replaced the active set
+	Applied bool
+	// Active the number of assets which are active after the load
+	Active int
+}
+
+// Markdown describes the report for Slack
+func (r LoadReport) Markdown() string {
+	var builder strings.Builder
+	if r.Applied {
+		fmt.Fprintf(&builder, "loaded %d knowledge assets from `%s`: %d added, %d removed, %d changed", r.Active, r.Dir, r.Added, r.Removed, r.Changed)
+		if r.SynonymsChanged {
+			builder.WriteString(", synonyms changed")
+		}
+		if r.CooldownsChanged {
+			builder.WriteString(", cooldowns changed")
+		}
+	} else {
+		fmt.Fprintf(&builder, "unable to reload the knowledge assets from `%s`. the previous %d assets are still active", r.Dir, r.Active)
+	}
+	if len(r.Failed) > 0 {
+		builder.WriteString("\nfailed to load:")
+		for _, failed := range r.Failed {
+			fmt.Fprintf(&builder, "\n- `%s`: %v", r.relative(failed.Path), failed.Err)
+		}
+	}
+	if len(r.Loaded) > 0 {
+		var loaded []string
+		for _, path := range r.Loaded {
+			loaded = append(loaded, r.relative(path))
+		}
+		fmt.Fprintf(&builder, "\nloaded: %s", strings.Join(loaded, ", "))
+	}
+	return builder.String()
+}
+
+func (r LoadReport) relative(path string) string {
+	if relative, err := filepath.Rel(r.Dir, path); err == nil {
+		return relative
+	}
+	return path
+}
+
+// loadKnowledgeAsset loads the asset defined by the content of the file at filePath. synonyms is the dictionary the
+// synonyms match mode looks up.
+func loadKnowledgeAsset(filePath string, content []byte, synonyms util.Synonyms) (data.KnowledgeAsset, error) {
+	var asset data.KnowledgeAsset
+	if err := yaml.Unmarshal(content, &asset); err != nil {
+		return asset, fmt.Errorf("error unmarshalling: %v", err)
+	}
+	// if the name of a known platform appears in the path add platform specific terms
+	// to 'On' which must be met before the knowledge asset is considered a match
+	if contextTerms := platforms.GetPathContextTerms(filePath); contextTerms != nil {
+		asset.On.Terms = append(asset.On.Terms, contextTerms...)
+	}
+
+	if err := resolveConditions(&asset.On, util.MatchOptions{}, synonyms); err != nil {
+		return asset, err
+	}
+	if err := validateCooldown(asset.Cooldown); err != nil {
+		return asset, fmt.Errorf("invalid cooldown: %v", err)
+	}
+
+	if len(asset.On.Expr) > 0 {
+		platformExpressions := platforms.GetPathContextExpr(filePath)
+		if len(platformExpressions) > 0 {
+			asset.On.Expr = fmt.Sprintf("%s and %s", platformExpressions, asset.On.Expr)
+		}
+		var err error
+		asset.On.CompiledExpr, err = expr.Compile(asset.On.Expr, exprFunctions(asset.On.Options, synonyms)...)
+		if err != nil {
+			return asset, fmt.Errorf("error compiling knowledge expression: %v", err)
+		}
+	}
+	return asset, nil
+}
+
+// loadKnowledgeSet loads every asset in dir. Files which can not be loaded are reported rather than failing the load.
+// An error is returned if the directory can not be read.
+func loadKnowledgeSet(dir string) (*knowledgeSet, LoadReport, error) {
+	report := LoadReport{Dir: dir}
+	files, err := getKnowledgeEntryPaths(dir, []string{})
+	if err != nil {
+		return nil, report, fmt.Errorf("error reading knowledge prompts directory: %v", err)
+	}
+
+	set := &knowledgeSet{dir: dir, sources: map[string]string{}}
+	synonymsPath := filepath.Join(dir, synonymsFile)
+	synonyms, synonymsContent, err := loadSynonyms(synonymsPath)
+	if err != nil {
+		report.Failed = append(report.Failed, FileError{Path: synonymsPath, Err: err})
+	}
+	set.synonyms = synonymsContent
+	cooldownsPath := filepath.Join(dir, cooldownsFile)
+	set.cooldowns, set.cooldownsSource, err = loadCooldowns(cooldownsPath)
+	if err != nil {
+		report.Failed = append(report.Failed, FileError{Path: cooldownsPath, Err: err})
+	}
+	for _, filePath := range files {
+		if filePath == synonymsPath || filePath == cooldownsPath {
+			continue
+		}
+		log.Debugf("loading knowledge entry from %s", filePath)
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			report.Failed = append(report.Failed, FileError{Path: filePath, Err: fmt.Errorf("error reading: %v", err)})
+			continue
+		}
+		asset, err := loadKnowledgeAsset(filePath, content, synonyms)
+		if err != nil {
+			report.Failed = append(report.Failed, FileError{Path: filePath, Err: err})
+			continue
+		}
+		set.assets = append(set.assets, asset)
+		set.sources[filePath] = string(content)
+		report.Loaded = append(report.Loaded, filePath)
+	}
+	return set, report, nil
+}
+
+// reloadKnowledge loads the assets in dir and, if every file loaded, atomically replaces the active set. When files
+// fail to load, the previous set remains active unless there was no previous set, such as when the bot starts.
+func reloadKnowledge(dir string) (LoadReport, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	previous := getKnowledgeSet()
+	set, report, err := loadKnowledgeSet(dir)
+	report.Active = len(previous.assets)
+	if err != nil {
+		return report, err
+	}
+
+	for path, content := range set.sources {
+		previousContent, ok := previous.sources[path]
+		switch {
+		case !ok:
+			report.Added++
+		case previousContent != content:
+			report.Changed++
+		}
+	}
+	for path := range previous.sources {
+		if _, ok := set.sources[path]; !ok {
+			report.Removed++
+		}
+	}
+	report.SynonymsChanged = set.synonyms != previous.synonyms
+	report.CooldownsChanged = set.cooldownsSource != previous.cooldownsSource
+
+	if len(report.Failed) > 0 && len(previous.assets) > 0 {
+		return report, fmt.Errorf("%d knowledge files failed to load", len(report.Failed))
+	}
+	setKnowledgeSet(set)
+	report.Applied = true
+	report.Active = len(set.assets)
+	return report, nil
+}
+
+// addWatches watches dir and its subdirectories. fsnotify does not watch directories recursively.
+func addWatches(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}
+
+// Watch reloads the knowledge assets when the prompt directory changes until ctx is done. The outcome of each reload
+// is logged.
+func Watch(ctx context.Context) error {
+	_, err := startWatch(ctx)
+	return err
+}
+
+// startWatch watches the prompt directory. The returned channel is closed when the watch stops.
+func startWatch(ctx context.Context) (<-chan struct{}, error) {
+	dir := getKnowledgeSet().dir
+	if len(dir) == 0 {
+		return nil, fmt.Errorf("no knowledge assets were loaded")
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("unable to watch %s: %v", dir, err)
+	}
+	if err := addWatches(watcher, dir); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("unable to watch %s: %v", dir, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer watcher.Close()
+		var reload <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				log.Debugf("knowledge prompt directory changed: %v", event)
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						if err := addWatches(watcher, event.Name); err != nil {
+							log.Warnf("unable to watch %s: %v", event.Name, err)
+						}
+					}
+				}
+				reload = time.After(watchDelay)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warnf("error watching knowledge prompt directory: %v", err)
+			case <-reload:
+				reload = nil
+				report, err := reloadKnowledge(dir)
+				if err != nil {
+					log.Warnf("%v\n%s", err, report.Markdown())
+					continue
+				}
+				log.Infof("%s", report.Markdown())
+			}
+		}
+	}()
+	log.Infof("watching %s for changes to knowledge assets", dir)
+	return done, nil
+}
+
+var KnowledgeReloadAttributes = data.Attributes{
+	Commands:       []string{"knowledge", "reload"},
+	RequireMention: true,
+	AdminOnly:      true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		report, err := reloadKnowledge(getKnowledgeSet().dir)
+		if err != nil && len(report.Loaded) == 0 && len(report.Failed) == 0 {
+			return util.StringToBlock(fmt.Sprintf("unable to reload the knowledge assets. %v", err), false), nil
+		}
+		return util.StringToBlock(report.Markdown(), false), nil
+	},
+	Category:     data.CategoryKnowledge,
+	HelpMarkdown: "reload the knowledge assets from the prompt directory and report which files failed to load: `knowledge reload`",
+	ShouldMatch: []string{
+		"knowledge reload",
+	},
+	ShouldntMatch: []string{
+		"knowledge",
+		"reload knowledge",
+	},
+}
@@ -0,0 +1,128 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// withKnowledgeSet replaces the active knowledge assets with an empty set for the duration of a test
+func withKnowledgeSet(t *testing.T) {
+	saved := getKnowledgeSet()
+	setKnowledgeSet(&knowledgeSet{})
+	t.Cleanup(func() {
+		setKnowledgeSet(saved)
+	})
+}
+
+func writePrompt(t *testing.T, dir, name, on string) {
+	t.Helper()
+	content := fmt.Sprintf("name: %s\nmarkdown: \"%s prompt\"\non:\n%s\n", name, name, on)
+	if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(content), 0o644); err != nil {
+		t.Fatalf("unable to write prompt: %v", err)
+	}
+}
+
+func TestReloadKnowledge(t *testing.T) {
+	withKnowledgeSet(t)
+	dir := t.TempDir()
+	writePrompt(t, dir, "install", `  tokens: ["install"]`)
+	writePrompt(t, dir, "upgrade", `  expr: containsAny(tokens, ["upgrade"])`)
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("name: [broken"), 0o644); err != nil {
+		t.Fatalf("unable to write prompt: %v", err)
+	}
+
+	// nothing is active when the bot starts so the files which loaded are used
+	report, err := reloadKnowledge(dir)
+	if err != nil || !report.Applied || report.Active != 2 || report.Added != 2 || len(report.Failed) != 1 {
+		t.Fatalf("expected the valid files to be loaded, got %+v: %v", report, err)
+	}
+	if !strings.Contains(report.Markdown(), "- `broken.yaml`: error unmarshalling") {
+		t.Errorf("expected the unmarshalling failure to be reported, got %q", report.Markdown())
+	}
+	if err := os.Remove(filepath.Join(dir, "broken.yaml")); err != nil {
+		t.Fatalf("unable to remove prompt: %v", err)
+	}
+
+	// a failed reload keeps the previous set active
+	active := getKnowledgeAssets()
+	writePrompt(t, dir, "proxy", `  expr: containsAny(tokens, ["proxy"]`)
+	report, err = reloadKnowledge(dir)
+	if err == nil || report.Applied || report.Active != 2 {
+		t.Fatalf("expected the reload to fail, got %+v: %v", report, err)
+	}
+	if rendered := report.Markdown(); !strings.Contains(rendered, "the previous 2 assets are still active") || !strings.Contains(rendered, "- `proxy.yaml`: error compiling knowledge expression") {
+		t.Errorf("expected the compile failure to be reported, got %q", rendered)
+	}
+	if assets := getKnowledgeAssets(); len(assets) != 2 || &assets[0] != &active[0] {
+		t.Errorf("expected the previous assets to remain active, got %+v", assets)
+	}
+
+	writePrompt(t, dir, "proxy", `  expr: containsAny(tokens, ["proxy"])`)
+	writePrompt(t, dir, "install", `  tokens: ["install", "installer"]`)
+	if err := os.Remove(filepath.Join(dir, "upgrade.yaml")); err != nil {
+		t.Fatalf("unable to remove prompt: %v", err)
+	}
+	report, err = reloadKnowledge(dir)
+	if err != nil || !report.Applied || report.Active != 2 || report.Added != 1 || report.Changed != 1 || report.Removed != 1 {
+		t.Fatalf("expected the changes to be counted, got %+v: %v", report, err)
+	}
+	if rendered := report.Markdown(); !strings.Contains(rendered, "loaded 2 knowledge assets") || !strings.Contains(rendered, "1 added, 1 removed, 1 changed") || !strings.Contains(rendered, "loaded: install.yaml, proxy.yaml") {
+		t.Errorf("expected the reload to be reported, got %q", rendered)
+	}
+	// the assets which were active before the reload are unchanged for messages which were matching against them
+	if len(active) != 2 || active[1].Name != "upgrade" {
+		t.Errorf("expected the previous set to be unchanged, got %+v", active)
+	}
+
+	response, err := KnowledgeReloadAttributes.Callback(context.TODO(), util.NewFakeClient(), nil, nil)
+	if err != nil || !strings.Contains(util.RenderMsgOptions(response...), "0 added, 0 removed, 0 changed") {
+		t.Errorf("expected the command to report the reload, got %q: %v", util.RenderMsgOptions(response...), err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	withKnowledgeSet(t)
+	savedDelay := watchDelay
+	watchDelay = 10 * time.Millisecond
+	t.Cleanup(func() {
+		watchDelay = savedDelay
+	})
+
+	dir := t.TempDir()
+	writePrompt(t, dir, "install", `  tokens: ["install"]`)
+	if _, err := reloadKnowledge(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done, err := startWatch(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the watch must stop before the active set is restored
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	if err := os.Mkdir(filepath.Join(dir, "vsphere"), 0o755); err != nil {
+		t.Fatalf("unable to create directory: %v", err)
+	}
+	// give the watcher a chance to watch the new directory before a file is added to it
+	time.Sleep(100 * time.Millisecond)
+	writePrompt(t, filepath.Join(dir, "vsphere"), "datastore", `  tokens: ["datastore"]`)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(getKnowledgeAssets()) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the new asset to be loaded, got %+v", getKnowledgeAssets())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,145 @@
+package knowledge
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// maxSuggestions is the most assets listed when several assets match a message equally well
+	maxSuggestions = 5
+	// strongMatchPercent is how close, as a percentage, the score of an asset must be to the best score for the asset
+	// to be suggested alongside the best match
+	strongMatchPercent = 80
+)
+
+// exprLiteral matches the string literals in an expression, such as the tokens passed to containsAny
+var exprLiteral = regexp.MustCompile(`"([^"\\]*)"`)
+
+// Score describes how specifically a message matched a knowledge asset
+type Score struct {
+	// Tokens the number of tokens of the satisfied conditions which are in the message
+	Tokens int
+	// Terms the number of satisfied terms
+	Terms int
+	// Depth the nesting depth of the deepest satisfied term
+	Depth int
+	// Priority the priority of the asset
+	Priority int
+}
+
+// Total is the score used to rank the assets which match a message
+func (s Score) Total() int {
+	return s.Tokens + s.Terms + s.Depth + s.Priority
+}
+
+func (s Score) String() string {
+	return fmt.Sprintf("%d (tokens: %d, terms: %d, depth: %d, priority: %d)", s.Total(), s.Tokens, s.Terms, s.Depth, s.Priority)
+}
+
+// scoredMatch is a knowledge asset which matched a message
+type scoredMatch struct {
+	Asset data.KnowledgeAsset
+	Score Score
+	// Cooldown the cooldown of the asset in the channel the message was sent in
+	Cooldown cooldown
+	// Cooling why the asset may not answer the message, if it is cooling down
+	Cooling string
+}
+
+// scoreAsset scores an asset whose conditions are satisfied by tokens
+func scoreAsset(asset data.KnowledgeAsset, tokens map[string]string, text string) Score {
+	score := Score{Priority: asset.Priority}
+	scoreTokenMatch(&asset.On, tokens, text, 0, &score)
+	return score
+}
+
+// scoreTokenMatch adds the tokens of a satisfied condition which are in the message to score, along with the terms
+// of the condition which are satisfied. The tokens of an expression are the string literals in the expression. Each
+// word of a phrase or sequence counts as a token, as does a regex.
+func scoreTokenMatch(match *data.TokenMatch, tokens map[string]string, text string, depth int, score *Score) {
+	if depth > score.Depth {
+		score.Depth = depth
+	}
+	if match.CompiledExpr != nil {
+		for _, literal := range exprLiteral.FindAllStringSubmatch(match.Expr, -1) {
+			if match.Options.Present(tokens, literal[1]) {
+				score.Tokens++
+			}
+		}
+		return
+	}
+	for _, token := range match.Tokens {
+		if match.Options.Present(tokens, token) {
+			score.Tokens++
+		}
+	}
+	score.Tokens += len(util.NormalizeWords(match.Phrase))
+	for _, phrase := range match.Sequence {
+		score.Tokens += len(util.NormalizeWords(phrase))
+	}
+	if match.CompiledRegex != nil {
+		score.Tokens++
+	}
+	for idx := range match.Terms {
+		if isTokenMatch(&match.Terms[idx], tokens, text) {
+			score.Terms++
+			scoreTokenMatch(&match.Terms[idx], tokens, text, depth+1, score)
+		}
+	}
+}
+
+// rankMatches orders matches from the highest score to the lowest. Matches with the same score keep the order the
+// assets were loaded in.
+func rankMatches(matches []scoredMatch) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score.Total() > matches[j].Score.Total()
+	})
+}
+
+// strongMatches returns the ranked matches whose score is close to the best score, up to maxSuggestions
+func strongMatches(ranked []scoredMatch) []scoredMatch {
+	if len(ranked) == 0 {
+		return nil
+	}
+	best := ranked[0].Score.Total()
+	var strong []scoredMatch
+	for _, match := range ranked {
+		if len(strong) == maxSuggestions || !isStrongScore(match.Score.Total(), best) {
+			break
+		}
+		strong = append(strong, match)
+	}
+	return strong
+}
+
+// isStrongScore returns whether total is close enough to the best score to be suggested alongside it. The percentage
+// only holds for a positive best score, so when a negative priority brings the best score to zero or below only the
+// assets which tie with it are strong.
+func isStrongScore(total, best int) bool {
+	if best <= 0 {
+		return total == best
+	}
+	return total*100 >= best*strongMatchPercent
+}
+
+// respondingMatches returns the strong matches which respond to a message and whether they are suggested rather than
+// answering. Cooldowns are applied after ranking so that a weaker asset never responds in place of one which is
+// cooling down: nothing responds when the best match is cooling down and cooling suggestions are left out.
+func respondingMatches(ranked []scoredMatch) ([]scoredMatch, bool) {
+	strong := strongMatches(ranked)
+	if len(strong) == 0 || len(strong[0].Cooling) > 0 {
+		return nil, false
+	}
+	var responding []scoredMatch
+	for _, match := range strong {
+		if len(match.Cooling) == 0 {
+			responding = append(responding, match)
+		}
+	}
+	return responding, len(responding) > 1
+}
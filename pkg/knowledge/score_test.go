@@ -0,0 +1,124 @@
+package knowledge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+func TestScoreAsset(t *testing.T) {
+	withKnowledgeSet(t)
+	dir := t.TempDir()
+	writePrompt(t, dir, "tokens", `  type: or
+  tokens: ["install", "installer"]
+  terms:
+  - tokens: ["vsphere"]
+    terms:
+    - tokens: ["proxy"]`)
+	writePrompt(t, dir, "expr", `  expr: containsAny(tokens, ["install", "upgrade"]) and containsAll(tokens, ["proxy", "vsphere"])`)
+	if _, err := reloadKnowledge(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assets := getKnowledgeAssets()
+	message := "install fails behind a proxy on vSphere"
+	tokens := util.NormalizeTokens(strings.Split(message, " "))
+
+	expected := map[string]Score{
+		"tokens": {Tokens: 3, Terms: 2, Depth: 2},
+		"expr":   {Tokens: 3},
+	}
+	for _, asset := range assets {
+		if !isTokenMatch(&asset.On, tokens, message) {
+			t.Fatalf("expected %s to match", asset.Name)
+		}
+		if score := scoreAsset(asset, tokens, message); score != expected[asset.Name] {
+			t.Errorf("expected %s to score %v, got %v", asset.Name, expected[asset.Name], score)
+		}
+	}
+}
+
+func TestRankMatches(t *testing.T) {
+	withKnowledgeSet(t)
+	dir := t.TempDir()
+	// the names of the files must not contain the platform or install keywords which add terms to the assets
+	writePrompt(t, dir, "generic", `  tokens: ["install"]`)
+	writePrompt(t, dir, "specific-one", `  tokens: ["install", "vsphere"]`)
+	writePrompt(t, dir, "specific-two", `  tokens: ["install", "aws"]`)
+	if _, err := reloadKnowledge(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	respond := func(message string) string {
+		t.Helper()
+		response, err := defaultKnowledgeHandler(context.TODO(), util.NewFakeClient(), &slackevents.MessageEvent{Channel: "C1"}, strings.Split(message, " "))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return util.RenderMsgOptions(response...)
+	}
+
+	// the most specific asset answers rather than the first asset loaded
+	if response := respond("how do I install on vsphere"); !strings.Contains(response, "specific-one prompt") {
+		t.Errorf("expected the asset which matched more tokens to answer, got %q", response)
+	}
+	if response := respond("how do I install"); !strings.Contains(response, "generic prompt") {
+		t.Errorf("expected the only matching asset to answer, got %q", response)
+	}
+	// assets which match equally well are suggested
+	response := respond("install on vsphere or aws")
+	if !strings.HasPrefix(response, "You might be asking about:") || !strings.Contains(response, "• *specific-one*") || !strings.Contains(response, "• *specific-two*") {
+		t.Errorf("expected the assets which matched equally well to be suggested, got %q", response)
+	}
+	if strings.Contains(response, "generic") {
+		t.Errorf("expected the weaker match to not be suggested, got %q", response)
+	}
+
+	// priority breaks the tie
+	writePrompt(t, dir, "specific-two", "  tokens: [\"install\", \"aws\"]\npriority: 1")
+	if _, err := reloadKnowledge(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response := respond("install on vsphere or aws"); !strings.Contains(response, "specific-two prompt") {
+		t.Errorf("expected the asset with a higher priority to answer, got %q", response)
+	}
+}
+
+func TestStrongMatchesNegativePriority(t *testing.T) {
+	ranked := []scoredMatch{
+		{Asset: data.KnowledgeAsset{Name: "demoted"}, Score: Score{Tokens: 1, Priority: -5}},
+		{Asset: data.KnowledgeAsset{Name: "more-demoted"}, Score: Score{Tokens: 1, Priority: -6}},
+	}
+	strong := strongMatches(ranked)
+	if len(strong) != 1 || strong[0].Asset.Name != "demoted" {
+		t.Fatalf("expected the best match to be strong even though its score is negative, got %+v", strong)
+	}
+
+	withKnowledgeSet(t)
+	dir := t.TempDir()
+	writePrompt(t, dir, "demoted", "  tokens: [\"install\"]\npriority: -5")
+	if _, err := reloadKnowledge(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response, err := defaultKnowledgeHandler(context.TODO(), util.NewFakeClient(), &slackevents.MessageEvent{Channel: "C1"}, []string{"install"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rendered := util.RenderMsgOptions(response...); !strings.Contains(rendered, "demoted prompt") {
+		t.Errorf("expected the asset with a negative priority to answer, got %q", rendered)
+	}
+}
+
+func TestRespondingMatchesCooling(t *testing.T) {
+	ranked := []scoredMatch{
+		{Asset: data.KnowledgeAsset{Name: "best"}, Score: Score{Tokens: 2}},
+		{Asset: data.KnowledgeAsset{Name: "cooling"}, Score: Score{Tokens: 2}, Cooling: "answered recently"},
+	}
+	responding, suggest := respondingMatches(ranked)
+	if len(responding) != 1 || responding[0].Asset.Name != "best" || suggest {
+		t.Errorf("expected the only strong match which isn't cooling down to answer, got %+v suggest=%t", responding, suggest)
+	}
+}
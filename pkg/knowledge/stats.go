@@ -0,0 +1,313 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// FeedbackHelpfulActionID and FeedbackUnhelpfulActionID are the action_ids of the buttons added to knowledge
+	// answers. The value of the buttons is the name of the asset which answered.
+	FeedbackHelpfulActionID   = "knowledge-helpful"
+	FeedbackUnhelpfulActionID = "knowledge-unhelpful"
+	// defaultMuteMinVotes is the number of votes needed before an asset may be muted unless
+	// KNOWLEDGE_MUTE_MIN_VOTES is set
+	defaultMuteMinVotes = 5
+	// feedbackFlushInterval is how often the answers and votes are persisted
+	feedbackFlushInterval = time.Minute
+)
+
+var (
+	feedbackMu sync.Mutex
+	// feedback is kept in memory until initializeFeedback opens the store configured by KNOWLEDGE_FEEDBACK_PATH
+	feedback, _ = OpenFeedback("", clock.RealClock{})
+	mutePolicy  = MutePolicy{}
+)
+
+func getFeedback() *FeedbackStore {
+	feedbackMu.Lock()
+	defer feedbackMu.Unlock()
+	return feedback
+}
+
+func setFeedback(store *FeedbackStore) {
+	feedbackMu.Lock()
+	defer feedbackMu.Unlock()
+	feedback = store
+}
+
+func getMutePolicy() MutePolicy {
+	feedbackMu.Lock()
+	defer feedbackMu.Unlock()
+	return mutePolicy
+}
+
+func setMutePolicy(policy MutePolicy) {
+	feedbackMu.Lock()
+	defer feedbackMu.Unlock()
+	mutePolicy = policy
+}
+
+// initializeFeedback opens the feedback store configured by KNOWLEDGE_FEEDBACK_PATH and reads the mute policy from
+// KNOWLEDGE_MUTE_UNHELPFUL_PERCENT and KNOWLEDGE_MUTE_MIN_VOTES
+func initializeFeedback() error {
+	policy := MutePolicy{MinVotes: defaultMuteMinVotes}
+	if percent := os.Getenv("KNOWLEDGE_MUTE_UNHELPFUL_PERCENT"); len(percent) > 0 {
+		value, err := strconv.Atoi(percent)
+		if err != nil || value < 0 || value > 100 {
+			return fmt.Errorf("KNOWLEDGE_MUTE_UNHELPFUL_PERCENT must be a percentage between 0 and 100, got %q", percent)
+		}
+		policy.UnhelpfulPercent = value
+	}
+	if minVotes := os.Getenv("KNOWLEDGE_MUTE_MIN_VOTES"); len(minVotes) > 0 {
+		value, err := strconv.Atoi(minVotes)
+		if err != nil || value < 1 {
+			return fmt.Errorf("KNOWLEDGE_MUTE_MIN_VOTES must be a positive number, got %q", minVotes)
+		}
+		policy.MinVotes = value
+	}
+	setMutePolicy(policy)
+
+	path := os.Getenv("KNOWLEDGE_FEEDBACK_PATH")
+	if len(path) == 0 {
+		log.Warnf("Knowledge feedback will be lost when the bot restarts.  Please configure KNOWLEDGE_FEEDBACK_PATH if you wish to keep it.")
+		return nil
+	}
+	store, err := OpenFeedback(path, clock.RealClock{})
+	if err != nil {
+		return err
+	}
+	setFeedback(store)
+	return nil
+}
+
+// RunFeedback periodically persists the answers and votes until ctx is done
+func RunFeedback(ctx context.Context) {
+	getFeedback().Run(ctx, feedbackFlushInterval)
+}
+
+// FlushFeedback persists the answers and votes which haven't been persisted yet
+func FlushFeedback() error {
+	return getFeedback().Flush()
+}
+
+// feedbackBlock returns the buttons which rate an answer given by the asset
+func feedbackBlock(asset string) *slack.ActionBlock {
+	helpful := slack.NewButtonBlockElement(FeedbackHelpfulActionID, asset, slack.NewTextBlockObject(slack.PlainTextType, ":+1: Helpful", true, false))
+	unhelpful := slack.NewButtonBlockElement(FeedbackUnhelpfulActionID, asset, slack.NewTextBlockObject(slack.PlainTextType, ":-1: Not helpful", true, false))
+	return slack.NewActionBlock("knowledge-feedback", helpful, unhelpful)
+}
+
+// feedbackAction records the vote of the user who clicked a feedback button
+func feedbackAction(actionID string, helpful bool) data.Action {
+	return data.Action{
+		ActionID:           actionID,
+		AllowNonSplatUsers: true,
+		Handler: func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+			return recordFeedback(client, callback, action.Value, helpful)
+		},
+	}
+}
+
+func recordFeedback(client util.SlackClientInterface, callback slack.InteractionCallback, asset string, helpful bool) ([]slack.MsgOption, error) {
+	store := getFeedback()
+	recorded, err := store.RecordVote(Vote{
+		Asset:   asset,
+		Channel: callback.Channel.ID,
+		Message: callback.Message.Timestamp,
+		User:    callback.User.ID,
+		Helpful: helpful,
+	})
+	if errors.Is(err, ErrAnswerTooOld) {
+		return util.StringToBlock("this answer is too old to rate", false), nil
+	}
+	if err != nil {
+		return util.StringToBlock("unable to record your feedback", false), err
+	}
+	if !recorded {
+		return util.StringToBlock("you already rated this answer", false), nil
+	}
+	rating := "helpful"
+	if !helpful {
+		rating = "unhelpful"
+	}
+	metrics.KnowledgeFeedback.WithLabelValues(asset, rating).Inc()
+
+	mute, muted, err := store.CheckMute(asset, getMutePolicy())
+	if err != nil {
+		log.Warnf("unable to mute knowledge asset %s: %v", asset, err)
+	} else if muted {
+		log.Infof("muted knowledge asset %s: %s", asset, mute.Reason)
+		notifyOwners(client, mute)
+	}
+	return util.StringToBlock("thanks for the feedback!", false), nil
+}
+
+// notifyOwners tells the owners of a muted asset that it was muted
+func notifyOwners(client util.SlackClientInterface, mute Mute) {
+	var owners []string
+	for _, asset := range getKnowledgeAssets() {
+		if asset.Name == mute.Asset {
+			owners = asset.Owners
+			break
+		}
+	}
+	text := fmt.Sprintf(":mute: the knowledge asset *%s* was muted because %s. an admin can unmute it with `knowledge unmute %s`",
+		mute.Asset, mute.Reason, mute.Asset)
+	for _, owner := range owners {
+		channel, _, _, err := client.OpenConversation(&slack.OpenConversationParameters{Users: []string{owner}})
+		if err != nil {
+			log.Warnf("unable to notify %s that %s was muted: %v", owner, mute.Asset, err)
+			continue
+		}
+		if _, _, err := client.PostMessage(channel.ID, util.StringToBlock(text, false)...); err != nil {
+			log.Warnf("unable to notify %s that %s was muted: %v", owner, mute.Asset, err)
+		}
+	}
+}
+
+// rankStats orders stats by helpfulness, volume or unhelpful rate. Assets without votes are ranked last by
+// helpfulness and unhelpful rate.
+func rankStats(stats []AssetStats, by string) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if by == "volume" {
+			return a.Answers > b.Answers
+		}
+		if (a.Votes() > 0) != (b.Votes() > 0) {
+			return a.Votes() > 0
+		}
+		if by == "unhelpful" && a.UnhelpfulPercent() != b.UnhelpfulPercent() {
+			return a.UnhelpfulPercent() > b.UnhelpfulPercent()
+		}
+		if by == "helpful" && a.HelpfulPercent() != b.HelpfulPercent() {
+			return a.HelpfulPercent() > b.HelpfulPercent()
+		}
+		return a.Votes() > b.Votes()
+	})
+}
+
+// formatStats lays the stats out as a table
+func formatStats(stats []AssetStats) string {
+	var builder strings.Builder
+	writer := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ASSET\tANSWERS\tHELPFUL\tUNHELPFUL\tHELPFUL %\tUNHELPFUL %")
+	for _, asset := range stats {
+		name := asset.Asset
+		if _, muted := getFeedback().Muted(asset.Asset); muted {
+			name += " (muted)"
+		}
+		if asset.Votes() == 0 {
+			fmt.Fprintf(writer, "%s\t%d\t%d\t%d\t-\t-\n", name, asset.Answers, asset.Helpful, asset.Unhelpful)
+			continue
+		}
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%d\t%d%%\t%d%%\n", name, asset.Answers, asset.Helpful, asset.Unhelpful,
+			asset.HelpfulPercent(), asset.UnhelpfulPercent())
+	}
+	writer.Flush()
+	return builder.String()
+}
+
+var KnowledgeStatsAttributes = data.Attributes{
+	Commands:       []string{"knowledge", "stats"},
+	RequireMention: true,
+	AdminOnly:      true,
+	Params: []data.Param{
+		{
+			Name:        "by",
+			Type:        data.ParamEnum,
+			Values:      []string{"helpful", "volume", "unhelpful"},
+			Default:     "helpful",
+			Description: "rank the assets by the percentage of helpful votes, the number of answers or the percentage of unhelpful votes",
+		},
+		{
+			Name:        "since",
+			Type:        data.ParamDuration,
+			KeyValue:    true,
+			Default:     "30d",
+			Description: "only count answers and votes within the duration",
+		},
+		{
+			Name:        "channel",
+			Type:        data.ParamChannel,
+			KeyValue:    true,
+			Description: "only count answers and votes in the channel",
+		},
+		{
+			Name:        "limit",
+			Type:        data.ParamInt,
+			KeyValue:    true,
+			Default:     "20",
+			Min:         1,
+			Max:         100,
+			Description: "maximum number of assets to show",
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		stats := getFeedback().Stats(time.Now().Add(-args.Duration("since")), args.String("channel"))
+		if len(stats) == 0 {
+			return util.StringToBlock("no knowledge answers were given in that time", false), nil
+		}
+		rankStats(stats, args.String("by"))
+		if len(stats) > args.Int("limit") {
+			stats = stats[:args.Int("limit")]
+		}
+		return util.StringToBlock(fmt.Sprintf("```\n%s```", formatStats(stats)), false), nil
+	},
+	Category:     data.CategoryKnowledge,
+	HelpMarkdown: "rank the knowledge assets by how helpful their answers were rated: `knowledge stats [helpful|volume|unhelpful] [since=<duration>] [channel=<#channel>]`",
+	ShouldMatch: []string{
+		"knowledge stats",
+		"knowledge stats unhelpful since=7d",
+	},
+	ShouldntMatch: []string{
+		"knowledge",
+		"stats knowledge",
+	},
+}
+
+var KnowledgeUnmuteAttributes = data.Attributes{
+	Commands:       []string{"knowledge", "unmute"},
+	RequireMention: true,
+	AdminOnly:      true,
+	Params: []data.Param{
+		{
+			Name:        "asset",
+			Required:    true,
+			Remainder:   true,
+			Description: "the name of the muted asset",
+		},
+	},
+	ParsedCallback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args *data.ParsedArgs) ([]slack.MsgOption, error) {
+		asset := args.String("asset")
+		unmuted, err := getFeedback().Unmute(asset)
+		if err != nil {
+			return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to unmute knowledge asset: %v", err)
+		}
+		if !unmuted {
+			return util.StringToBlock(fmt.Sprintf("*%s* is not muted", asset), false), nil
+		}
+		return util.StringToBlock(fmt.Sprintf("*%s* is unmuted and will answer messages again", asset), false), nil
+	},
+	Category:      data.CategoryKnowledge,
+	HelpMarkdown:  "allow a knowledge asset which was muted because its answers were rated unhelpful to answer messages again: `knowledge unmute <asset name>`",
+	ShouldMatch:   []string{"knowledge unmute openshift install"},
+	ShouldntMatch: []string{"knowledge", "knowledge stats"},
+}
@@ -9,6 +9,8 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/openshift-splat-team/jira-bot/pkg/util"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
 )
 
 const (
@@ -61,7 +63,9 @@ func GenerateResponse(ctx context.Context, prompt string) (string, error) {
 	return completion, nil*/
 
 	//summay   := GetJiraIssueSummary(ctx, "SPLAT - updates in last wee
+	start := time.Now()
 	out, err := Completion(prompt)
+	metrics.ObserveLLM("llama.cpp", start, err)
 
 	if err != nil {
 		return "", fmt.Errorf("unable to get completion: %v", err)
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Namespace prefixes the names of the bot's metrics
+const Namespace = "splat_bot"
+
+// Outcomes of calls to external services
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// latencyBuckets cover fast commands through LLM prompts, which may take up to two minutes
+var latencyBuckets = prometheus.ExponentialBuckets(0.05, 2, 12)
+
+var (
+	// CommandInvocations counts the commands which were invoked by command, source and outcome
+	CommandInvocations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "command_invocations_total",
+		Help:      "Number of commands invoked by command, how the command was invoked and outcome.",
+	}, []string{"command", "source", "outcome"})
+
+	// CommandDuration observes how long commands took to run, including authorization and their callback
+	CommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "command_duration_seconds",
+		Help:      "Time taken to run commands by command.",
+		Buckets:   latencyBuckets,
+	}, []string{"command"})
+
+	// KnowledgeMatches counts the messages answered by each knowledge asset
+	KnowledgeMatches = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "knowledge_matches_total",
+		Help:      "Number of messages answered by each knowledge asset.",
+	}, []string{"asset"})
+
+	// KnowledgeFeedback counts the votes on knowledge answers by asset and whether the answer was helpful
+	KnowledgeFeedback = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "knowledge_feedback_total",
+		Help:      "Number of votes on knowledge answers by asset and rating.",
+	}, []string{"asset", "rating"})
+
+	// LLMRequests counts the requests made to language models by backend and outcome
+	LLMRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "llm_requests_total",
+		Help:      "Number of requests made to language models by backend and outcome.",
+	}, []string{"backend", "outcome"})
+
+	// LLMDuration observes how long requests to language models took
+	LLMDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "llm_request_duration_seconds",
+		Help:      "Time taken by requests to language models by backend.",
+		Buckets:   latencyBuckets,
+	}, []string{"backend"})
+
+	// SlackAPIErrors counts the calls to the Slack API which failed by method
+	SlackAPIErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "slack_api_errors_total",
+		Help:      "Number of calls to the Slack API which failed by method.",
+	}, []string{"method"})
+
+	// DuplicateEventsDropped counts the events which were dropped because they had already been handled
+	DuplicateEventsDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "duplicate_events_dropped_total",
+		Help:      "Number of events which were dropped because they had already been handled.",
+	})
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(
+		CommandInvocations,
+		CommandDuration,
+		KnowledgeMatches,
+		KnowledgeFeedback,
+		LLMRequests,
+		LLMDuration,
+		SlackAPIErrors,
+		DuplicateEventsDropped,
+	)
+}
+
+// ObserveCommand records an invocation of command which took duration
+func ObserveCommand(command, source, outcome string, duration time.Duration) {
+	CommandInvocations.WithLabelValues(command, source, outcome).Inc()
+	CommandDuration.WithLabelValues(command).Observe(duration.Seconds())
+}
+
+// ObserveLLM records a request to a language model which started at start and failed if err is not nil
+func ObserveLLM(backend string, start time.Time, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+	}
+	LLMRequests.WithLabelValues(backend, outcome).Inc()
+	LLMDuration.WithLabelValues(backend).Observe(time.Since(start).Seconds())
+}
+
+// ObserveSlackAPI records a call to a Slack API method which failed if err is not nil
+func ObserveSlackAPI(method string, err error) {
+	if err != nil {
+		SlackAPIErrors.WithLabelValues(method).Inc()
+	}
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// value returns the value of a counter or the number of observations of a histogram
+func value(t *testing.T, metric prometheus.Metric) float64 {
+	t.Helper()
+	var out dto.Metric
+	if err := metric.Write(&out); err != nil {
+		t.Fatalf("unable to read metric: %v", err)
+	}
+	if out.Histogram != nil {
+		return float64(out.Histogram.GetSampleCount())
+	}
+	return out.Counter.GetValue()
+}
+
+func TestObserve(t *testing.T) {
+	ObserveCommand("test-command", "message", OutcomeSuccess, time.Second)
+	ObserveCommand("test-command", "message", OutcomeSuccess, time.Second)
+	if count := value(t, CommandInvocations.WithLabelValues("test-command", "message", OutcomeSuccess)); count != 2 {
+		t.Errorf("expected 2 invocations, got %v", count)
+	}
+	if count := value(t, CommandDuration.WithLabelValues("test-command").(prometheus.Histogram)); count != 2 {
+		t.Errorf("expected 2 durations, got %v", count)
+	}
+
+	ObserveLLM("test-backend", time.Now(), nil)
+	ObserveLLM("test-backend", time.Now(), errors.New("timeout"))
+	if count := value(t, LLMRequests.WithLabelValues("test-backend", OutcomeError)); count != 1 {
+		t.Errorf("expected 1 failed request, got %v", count)
+	}
+	if count := value(t, LLMDuration.WithLabelValues("test-backend").(prometheus.Histogram)); count != 2 {
+		t.Errorf("expected 2 durations, got %v", count)
+	}
+
+	// only failed calls are counted
+	ObserveSlackAPI("test.method", nil)
+	ObserveSlackAPI("test.method", errors.New("channel_not_found"))
+	if count := value(t, SlackAPIErrors.WithLabelValues("test.method")); count != 1 {
+		t.Errorf("expected 1 error, got %v", count)
+	}
+}
@@ -0,0 +1,260 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// AnyCommand may be used in a rule to match every command.  Rules which name a command explicitly are more
+	// specific and always win over AnyCommand.
+	AnyCommand = "*"
+
+	groupMembershipTTL = 10 * time.Minute
+)
+
+// GroupResolver resolves the members of a Slack user group.  util.SlackClientInterface satisfies this interface.
+type GroupResolver interface {
+	GetUserGroupMembers(userGroup string) ([]string, error)
+}
+
+// Policy grants commands to Slack users, user groups and channels.
+type Policy struct {
+	// Rules which grant commands. When several rules name the same command, any of them may grant access.
+	Rules []Rule `yaml:"rules"`
+	// Admins may run commands which are restricted to administrators, such as `audit`.
+	Admins Admins `yaml:"admins"`
+
+	groupMu sync.Mutex
+	groups  map[string]groupMembers
+}
+
+// Rule grants Commands to the listed subjects.
+type Rule struct {
+	// Name of the rule. Reported to the user when the rule blocks a command.
+	Name string `yaml:"name"`
+	// Commands granted by the rule.  Each command is a space separated prefix such as `ci pools cordon` or `ci lease`.
+	Commands []string `yaml:"commands"`
+	// Users Slack user IDs granted the commands.
+	Users []string `yaml:"users"`
+	// Groups Slack user group IDs granted the commands.
+	Groups []string `yaml:"groups"`
+	// Channels Slack channel IDs in which anyone may run the commands.
+	Channels []string `yaml:"channels"`
+}
+
+// Admins lists the Slack users and user groups which administer the bot.
+type Admins struct {
+	// Users Slack user IDs of administrators.
+	Users []string `yaml:"users"`
+	// Groups Slack user group IDs whose members are administrators.
+	Groups []string `yaml:"groups"`
+}
+
+// Request describes a user attempting to run a command.
+type Request struct {
+	User    string
+	Channel string
+	// Command the tokens of the command being invoked, including its arguments.
+	Command []string
+}
+
+type groupMembers struct {
+	members map[string]bool
+	fetched time.Time
+}
+
+// Load reads and validates a policy from a YAML file.
+func Load(path string) (*Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read policy %s: %v", path, err)
+	}
+	return Parse(content)
+}
+
+// Parse parses and validates a YAML policy.
+func Parse(content []byte) (*Policy, error) {
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(content, policy); err != nil {
+		return nil, fmt.Errorf("unable to parse policy: %v", err)
+	}
+
+	for idx := range policy.Rules {
+		rule := &policy.Rules[idx]
+		if len(rule.Name) == 0 {
+			rule.Name = fmt.Sprintf("rule-%d", idx+1)
+		}
+		if len(rule.Commands) == 0 {
+			return nil, fmt.Errorf("rule %s does not grant any commands", rule.Name)
+		}
+		if len(rule.Users) == 0 && len(rule.Groups) == 0 && len(rule.Channels) == 0 {
+			return nil, fmt.Errorf("rule %s does not grant commands to any users, groups or channels", rule.Name)
+		}
+	}
+	return policy, nil
+}
+
+// commandSpecificity returns the number of tokens in command that prefix args. -1 is returned if command does not
+// apply to args.
+func commandSpecificity(command string, args []string) int {
+	if strings.TrimSpace(command) == AnyCommand {
+		return 0
+	}
+	tokens := strings.Fields(command)
+	if len(tokens) == 0 || len(tokens) > len(args) {
+		return -1
+	}
+	for idx, token := range tokens {
+		if token != args[idx] {
+			return -1
+		}
+	}
+	return len(tokens)
+}
+
+// rulesFor returns the most specific rules which apply to the command along with the command prefix they matched.
+func (p *Policy) rulesFor(args []string) ([]Rule, string) {
+	var matched []Rule
+	var prefix string
+	best := -1
+	for _, rule := range p.Rules {
+		ruleBest := -1
+		rulePrefix := ""
+		for _, command := range rule.Commands {
+			if specificity := commandSpecificity(command, args); specificity > ruleBest {
+				ruleBest = specificity
+				rulePrefix = strings.Join(strings.Fields(command), " ")
+			}
+		}
+		if ruleBest < 0 || ruleBest < best {
+			continue
+		}
+		if ruleBest > best {
+			best = ruleBest
+			prefix = rulePrefix
+			matched = nil
+		}
+		matched = append(matched, rule)
+	}
+	return matched, prefix
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) isGroupMember(resolver GroupResolver, group, user string) (bool, error) {
+	p.groupMu.Lock()
+	defer p.groupMu.Unlock()
+
+	if p.groups == nil {
+		p.groups = map[string]groupMembers{}
+	}
+	if cached, ok := p.groups[group]; ok && time.Since(cached.fetched) < groupMembershipTTL {
+		return cached.members[user], nil
+	}
+	if resolver == nil {
+		return false, errors.New("no slack client available to resolve user groups")
+	}
+	members, err := resolver.GetUserGroupMembers(group)
+	if err != nil {
+		return false, fmt.Errorf("unable to get members of user group %s: %v", group, err)
+	}
+	cached := groupMembers{
+		members: map[string]bool{},
+		fetched: time.Now(),
+	}
+	for _, member := range members {
+		cached.members[member] = true
+	}
+	p.groups[group] = cached
+	return cached.members[user], nil
+}
+
+func (p *Policy) grants(resolver GroupResolver, rule Rule, req Request) bool {
+	if contains(rule.Users, req.User) || contains(rule.Channels, req.Channel) {
+		return true
+	}
+	for _, group := range rule.Groups {
+		member, err := p.isGroupMember(resolver, group, req.User)
+		if err != nil {
+			log.Warnf("rule %s: %v", rule.Name, err)
+			continue
+		}
+		if member {
+			return true
+		}
+	}
+	return false
+}
+
+func describeRule(rule Rule) string {
+	var subjects []string
+	if len(rule.Users) > 0 {
+		subjects = append(subjects, fmt.Sprintf("users %s", strings.Join(rule.Users, ", ")))
+	}
+	if len(rule.Groups) > 0 {
+		subjects = append(subjects, fmt.Sprintf("groups %s", strings.Join(rule.Groups, ", ")))
+	}
+	if len(rule.Channels) > 0 {
+		subjects = append(subjects, fmt.Sprintf("channels %s", strings.Join(rule.Channels, ", ")))
+	}
+	return fmt.Sprintf("rule %q only allows %s", rule.Name, strings.Join(subjects, "; "))
+}
+
+// Authorize checks if the request is granted by the policy. matched is false when no rule covers the command, in
+// which case the caller should apply its default behavior. When a rule covers the command but does not grant it to
+// the user, the returned error explains which rules blocked the user.
+func (p *Policy) Authorize(resolver GroupResolver, req Request) (matched bool, err error) {
+	if p == nil {
+		return false, nil
+	}
+	rules, prefix := p.rulesFor(req.Command)
+	if len(rules) == 0 {
+		return false, nil
+	}
+
+	var reasons []string
+	for _, rule := range rules {
+		if p.grants(resolver, rule, req) {
+			log.Debugf("user %s granted %q by rule %s", req.User, prefix, rule.Name)
+			return true, nil
+		}
+		reasons = append(reasons, describeRule(rule))
+	}
+	return true, fmt.Errorf("`%s` is restricted: %s", prefix, strings.Join(reasons, ", "))
+}
+
+// IsAdmin returns true if the user is an administrator. A nil policy has no administrators.
+func (p *Policy) IsAdmin(resolver GroupResolver, user string) bool {
+	if p == nil {
+		return false
+	}
+	if contains(p.Admins.Users, user) {
+		return true
+	}
+	for _, group := range p.Admins.Groups {
+		member, err := p.isGroupMember(resolver, group, user)
+		if err != nil {
+			log.Warnf("admins: %v", err)
+			continue
+		}
+		if member {
+			return true
+		}
+	}
+	return false
+}
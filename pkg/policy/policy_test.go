@@ -0,0 +1,172 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testPolicy = `rules:
+- name: pool-admins
+  commands:
+  - ci pools cordon
+  - ci pools uncordon
+  groups:
+  - admins
+- name: ci-users
+  commands:
+  - ci
+  users:
+  - U1
+  channels:
+  - CI
+- name: everyone-else
+  commands:
+  - "*"
+  users:
+  - U3
+`
+
+type fakeResolver struct {
+	groups map[string][]string
+	calls  int
+}
+
+func (f *fakeResolver) GetUserGroupMembers(userGroup string) ([]string, error) {
+	f.calls++
+	members, ok := f.groups[userGroup]
+	if !ok {
+		return nil, errors.New("no such group")
+	}
+	return members, nil
+}
+
+func TestAuthorize(t *testing.T) {
+	policy, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("unable to parse policy: %v", err)
+	}
+	resolver := &fakeResolver{groups: map[string][]string{"admins": {"U2"}}}
+
+	cases := []struct {
+		name          string
+		req           Request
+		expectMatched bool
+		expectAllowed bool
+		expectReason  string
+	}{
+		{
+			name:          "group member may cordon",
+			req:           Request{User: "U2", Channel: "C1", Command: []string{"ci", "pools", "cordon", "pool-1"}},
+			expectMatched: true,
+			expectAllowed: true,
+		},
+		{
+			name:          "ci user may not cordon",
+			req:           Request{User: "U1", Channel: "C1", Command: []string{"ci", "pools", "cordon", "pool-1"}},
+			expectMatched: true,
+			expectReason:  `rule "pool-admins" only allows groups admins`,
+		},
+		{
+			name:          "ci user may list pools",
+			req:           Request{User: "U1", Channel: "C1", Command: []string{"ci", "pools", "list"}},
+			expectMatched: true,
+			expectAllowed: true,
+		},
+		{
+			name:          "anyone may use ci commands in the ci channel",
+			req:           Request{User: "U9", Channel: "CI", Command: []string{"ci", "lease", "list"}},
+			expectMatched: true,
+			expectAllowed: true,
+		},
+		{
+			name:          "wildcard applies to other commands",
+			req:           Request{User: "U1", Channel: "C1", Command: []string{"jira", "create", "summary"}},
+			expectMatched: true,
+			expectReason:  `rule "everyone-else" only allows users U3`,
+		},
+		{
+			name:          "wildcard grants other commands",
+			req:           Request{User: "U3", Channel: "C1", Command: []string{"help"}},
+			expectMatched: true,
+			expectAllowed: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			matched, err := policy.Authorize(resolver, tc.req)
+			if matched != tc.expectMatched {
+				t.Fatalf("expected matched to be %t", tc.expectMatched)
+			}
+			if tc.expectAllowed && err != nil {
+				t.Fatalf("expected to be allowed: %v", err)
+			}
+			if !tc.expectAllowed {
+				if err == nil {
+					t.Fatalf("expected to be denied")
+				}
+				if !strings.Contains(err.Error(), tc.expectReason) {
+					t.Fatalf("expected denial to contain %q, got %q", tc.expectReason, err.Error())
+				}
+			}
+		})
+	}
+
+	if resolver.calls != 1 {
+		t.Fatalf("expected group membership to be cached, resolved %d times", resolver.calls)
+	}
+}
+
+func TestUnmatchedCommand(t *testing.T) {
+	policy, err := Parse([]byte(`rules:
+- commands: ["ci pools cordon"]
+  users: ["U1"]
+`))
+	if err != nil {
+		t.Fatalf("unable to parse policy: %v", err)
+	}
+	if matched, err := policy.Authorize(nil, Request{User: "U2", Command: []string{"ci", "pools", "list"}}); matched || err != nil {
+		t.Fatalf("expected no rule to apply, got matched=%t err=%v", matched, err)
+	}
+
+	var nilPolicy *Policy
+	if matched, _ := nilPolicy.Authorize(nil, Request{User: "U2", Command: []string{"ci"}}); matched {
+		t.Fatalf("expected a nil policy to never match")
+	}
+}
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	invalid := []string{
+		"rules:\n- users: [U1]\n",
+		"rules:\n- commands: [ci]\n",
+		"rules:\n- commands: [ci]\n  user: [U1]\n",
+	}
+	for _, spec := range invalid {
+		if _, err := Parse([]byte(spec)); err == nil {
+			t.Errorf("expected policy to be rejected:\n%s", spec)
+		}
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	policy, err := Parse([]byte(`admins:
+  users: ["U1"]
+  groups: ["admins"]
+`))
+	if err != nil {
+		t.Fatalf("unable to parse policy: %v", err)
+	}
+	resolver := &fakeResolver{groups: map[string][]string{"admins": {"U2"}}}
+
+	for user, expected := range map[string]bool{"U1": true, "U2": true, "U3": false} {
+		if admin := policy.IsAdmin(resolver, user); admin != expected {
+			t.Errorf("expected IsAdmin(%s) to be %t", user, expected)
+		}
+	}
+
+	var nilPolicy *Policy
+	if nilPolicy.IsAdmin(resolver, "U1") {
+		t.Fatalf("expected a nil policy to have no admins")
+	}
+}
@@ -0,0 +1,190 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearch bounds the search for the next run of a cron expression. Expressions such as `0 0 31 2 *` never match.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// macros are shorthands for common cron expressions
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	dayNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: monthNames}
+	// day of week 7 is accepted as Sunday
+	dowField = field{name: "day of week", min: 0, max: 7, names: dayNames}
+)
+
+// Cron is a parsed cron expression with the standard five fields: minute, hour, day of month, month and day of week.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny are set when the field is `*`. When both day fields are restricted, either may match.
+	domAny, dowAny bool
+}
+
+// ParseCron parses a cron expression such as `0 9 * * 1-5`. Fields accept `*`, numbers, ranges, lists and steps,
+// and month and day of week accept names such as `jan` and `mon-fri`. Macros such as `@daily` and `@weekly` are also
+// accepted.
+func ParseCron(spec string) (*Cron, error) {
+	spec = strings.TrimSpace(spec)
+	if macro, ok := macros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields: minute hour day-of-month month day-of-week", spec)
+	}
+
+	cron := &Cron{
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}
+	var err error
+	if cron.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if cron.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if cron.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if cron.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if cron.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+	if cron.dow&(1<<7) != 0 {
+		cron.dow |= 1
+	}
+	return cron, nil
+}
+
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepPart, f.name)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*":
+			start, end = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			low, high, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseValue(low, f); err != nil {
+				return 0, err
+			}
+			if end, err = parseValue(high, f); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q in %s", rangePart, f.name)
+			}
+		default:
+			var err error
+			if start, err = parseValue(rangePart, f); err != nil {
+				return 0, err
+			}
+			end = start
+			if hasStep {
+				end = f.max
+			}
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	if number, ok := f.names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", f.name, value)
+	}
+	if number < f.min || number > f.max {
+		return 0, fmt.Errorf("%s must be between %d and %d, got %d", f.name, f.min, f.max, number)
+	}
+	return number, nil
+}
+
+func (c *Cron) matchesDay(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next returns the first time after t which matches the expression, in the location of t. The zero time is returned
+// if the expression never matches.
+func (c *Cron) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+	for next.Before(limit) {
+		if c.month&(1<<uint(next.Month())) == 0 {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !c.matchesDay(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if c.hour&(1<<uint(next.Hour())) == 0 {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+			continue
+		}
+		if c.minute&(1<<uint(next.Minute())) == 0 {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+	return time.Time{}
+}
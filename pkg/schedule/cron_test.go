@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2024, 5, 15, 9, 30, 20, 0, time.UTC)
+	cases := map[string]time.Time{
+		"* * * * *":        time.Date(2024, 5, 15, 9, 31, 0, 0, time.UTC),
+		"0 9 * * 1-5":      time.Date(2024, 5, 16, 9, 0, 0, 0, time.UTC),
+		"0 9 * * mon":      time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC),
+		"*/15 * * * *":     time.Date(2024, 5, 15, 9, 45, 0, 0, time.UTC),
+		"0 8,17 * * *":     time.Date(2024, 5, 15, 17, 0, 0, 0, time.UTC),
+		"0 0 1 jan *":      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		"0 12 29 feb *":    time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC),
+		"0 10 1 * 5":       time.Date(2024, 5, 17, 10, 0, 0, 0, time.UTC),
+		"0 0 * * 7":        time.Date(2024, 5, 19, 0, 0, 0, 0, time.UTC),
+		"@weekly":          time.Date(2024, 5, 19, 0, 0, 0, 0, time.UTC),
+		"@hourly":          time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC),
+		"30 9-17/4 * * *":  time.Date(2024, 5, 15, 13, 30, 0, 0, time.UTC),
+		"0 0 31 2 *":       {},
+		"15 10 15 5 wed":   time.Date(2024, 5, 15, 10, 15, 0, 0, time.UTC),
+		" 0 9 * * MON-FRI": time.Date(2024, 5, 16, 9, 0, 0, 0, time.UTC),
+	}
+	for spec, expected := range cases {
+		cron, err := ParseCron(spec)
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", spec, err)
+			continue
+		}
+		if next := cron.Next(from); !next.Equal(expected) {
+			t.Errorf("expected the next run of %q to be %s, got %s", spec, expected, next)
+		}
+	}
+}
+
+func TestCronNextInLocation(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data is not available: %v", err)
+	}
+	cron, err := ParseCron("0 9 * * *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	next := cron.Next(time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC).In(location))
+	if expected := time.Date(2024, 5, 15, 13, 0, 0, 0, time.UTC); !next.Equal(expected) {
+		t.Errorf("expected 9:00 in New York to be %s, got %s", expected, next.UTC())
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	invalid := map[string]string{
+		"* * * *":        "must have 5 fields",
+		"60 * * * *":     "minute must be between 0 and 59",
+		"* * 0 * *":      "day of month must be between 1 and 31",
+		"* * * 13 *":     "month must be between 1 and 12",
+		"* * * * funday": "invalid day of week",
+		"*/0 * * * *":    "invalid step",
+		"5-1 * * * *":    "invalid range",
+	}
+	for spec, expected := range invalid {
+		_, err := ParseCron(spec)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q for %q, got %v", expected, spec, err)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// reminderRetryBackoff is how long to wait before retrying a reminder which failed to send. The wait doubles with
+	// every failed attempt up to reminderMaxBackoff.
+	reminderRetryBackoff = time.Minute
+	reminderMaxBackoff   = time.Hour
+	// reminderMaxAttempts is how many times sending a reminder may fail before it is dropped
+	reminderMaxAttempts = 10
+)
+
+// Reminder nudges a user, or a thread, once at a point in time.
+type Reminder struct {
+	ID      string `json:"id"`
+	Creator string `json:"creator"`
+	// User the user who is reminded.
+	User string `json:"user"`
+	// Channel and Thread identify the thread the reminder is posted in. The reminder is sent to User in a DM when
+	// Thread is empty.
+	Channel string `json:"channel,omitempty"`
+	Thread  string `json:"thread,omitempty"`
+	// Link to the message the reminder was created from, if it was created in a thread.
+	Link    string    `json:"link,omitempty"`
+	Text    string    `json:"text"`
+	Created time.Time `json:"created"`
+	Due     time.Time `json:"due"`
+	// Attempts the number of times sending the reminder failed.
+	Attempts int `json:"attempts,omitempty"`
+	// Retry when the reminder is sent again after failing to send.
+	Retry time.Time `json:"retry,omitempty"`
+}
+
+// reminderFile is the content of the file the reminders are persisted to
+type reminderFile struct {
+	// LastID the ID of the most recently added reminder, kept so that IDs are not reused.
+	LastID    int        `json:"lastID"`
+	Reminders []Reminder `json:"reminders"`
+}
+
+// ReminderStore holds the reminders which are yet to be sent and persists them as JSON. Reminders are only kept in
+// memory when the store has no path.
+type ReminderStore struct {
+	clock Clock
+	store *util.JSONStore[reminderFile]
+}
+
+// OpenReminders returns a store which persists reminders to the file at path. Existing reminders are loaded from the
+// file.
+func OpenReminders(path string, clock Clock) (*ReminderStore, error) {
+	store, err := util.OpenJSONStore[reminderFile](path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load reminders: %v", err)
+	}
+	return &ReminderStore{clock: clock, store: store}, nil
+}
+
+// Now returns the current time according to the store's clock.
+func (s *ReminderStore) Now() time.Time {
+	return s.clock.Now()
+}
+
+// Add assigns the reminder's ID and stores it. The reminder must be due in the future.
+func (s *ReminderStore) Add(reminder Reminder) (Reminder, error) {
+	now := s.clock.Now()
+	if !reminder.Due.After(now) {
+		return reminder, fmt.Errorf("reminders must be in the future")
+	}
+	err := s.store.Update(func(file *reminderFile) error {
+		file.LastID++
+		reminder.ID = strconv.Itoa(file.LastID)
+		reminder.Created = now
+		file.Reminders = append(file.Reminders, reminder)
+		return nil
+	})
+	return reminder, err
+}
+
+// Get returns the reminder with the ID.
+func (s *ReminderStore) Get(id string) (Reminder, error) {
+	found, err := Reminder{}, ErrNotFound
+	s.store.View(func(file *reminderFile) {
+		for _, reminder := range file.Reminders {
+			if reminder.ID == id {
+				found, err = reminder, nil
+				return
+			}
+		}
+	})
+	return found, err
+}
+
+// Delete removes the reminder with the ID.
+func (s *ReminderStore) Delete(id string) error {
+	return s.store.Update(func(file *reminderFile) error {
+		for index, reminder := range file.Reminders {
+			if reminder.ID == id {
+				file.Reminders = append(file.Reminders[:index], file.Reminders[index+1:]...)
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+}
+
+// List returns the reminders created by or for the user, ordered by when they are due. Every reminder is returned when
+// user is empty.
+func (s *ReminderStore) List(user string) []Reminder {
+	var reminders []Reminder
+	s.store.View(func(file *reminderFile) {
+		for _, reminder := range file.Reminders {
+			if len(user) == 0 || reminder.Creator == user || reminder.User == user {
+				reminders = append(reminders, reminder)
+			}
+		}
+	})
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].Due.Before(reminders[j].Due)
+	})
+	return reminders
+}
+
+// Due returns the reminders which are due at or before now, other than those waiting to be retried. Unlike schedules,
+// reminders which were missed while the bot was down are still returned so that they can be sent late. Reminders
+// stay in the store until they are sent.
+func (s *ReminderStore) Due(now time.Time) []Reminder {
+	var due []Reminder
+	s.store.View(func(file *reminderFile) {
+		for _, reminder := range file.Reminders {
+			if !reminder.Due.After(now) && !reminder.Retry.After(now) {
+				due = append(due, reminder)
+			}
+		}
+	})
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].Due.Before(due[j].Due)
+	})
+	return due
+}
+
+// retryBackoff returns how long to wait before retrying a reminder which failed to send attempts times
+func retryBackoff(attempts int) time.Duration {
+	backoff := reminderRetryBackoff
+	for attempt := 1; attempt < attempts && backoff < reminderMaxBackoff; attempt++ {
+		backoff *= 2
+	}
+	return min(backoff, reminderMaxBackoff)
+}
+
+// failed records that sending the reminder failed. It is retried with a backoff until reminderMaxAttempts attempts
+// have failed, then dropped.
+func (s *ReminderStore) failed(id string, now time.Time) error {
+	return s.store.Apply(func(file *reminderFile) {
+		for index := range file.Reminders {
+			reminder := &file.Reminders[index]
+			if reminder.ID != id {
+				continue
+			}
+			reminder.Attempts++
+			if reminder.Attempts >= reminderMaxAttempts {
+				log.Warnf("dropping reminder %s after %d failed attempts", reminder.ID, reminder.Attempts)
+				file.Reminders = append(file.Reminders[:index], file.Reminders[index+1:]...)
+				return
+			}
+			reminder.Retry = now.Add(retryBackoff(reminder.Attempts))
+			return
+		}
+	})
+}
+
+// sent removes a reminder which was sent. It is removed even if that can't be persisted so that it is not sent again.
+func (s *ReminderStore) sent(id string) error {
+	return s.store.Apply(func(file *reminderFile) {
+		for index, reminder := range file.Reminders {
+			if reminder.ID == id {
+				file.Reminders = append(file.Reminders[:index], file.Reminders[index+1:]...)
+				return
+			}
+		}
+	})
+}
+
+// Run calls send for each reminder when it is due, checking every interval until ctx is done. A reminder is removed
+// once it is sent and retried later if send fails.
+func (s *ReminderStore) Run(ctx context.Context, interval time.Duration, send func(ctx context.Context, reminder Reminder) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sendDue(ctx, send)
+		}
+	}
+}
+
+// sendDue calls send for each reminder which is due
+func (s *ReminderStore) sendDue(ctx context.Context, send func(ctx context.Context, reminder Reminder) error) {
+	for _, reminder := range s.Due(s.clock.Now()) {
+		if err := send(ctx, reminder); err != nil {
+			log.Warnf("reminder %s failed: %v", reminder.ID, err)
+			if err := s.failed(reminder.ID, s.clock.Now()); err != nil {
+				log.Warnf("%v", err)
+			}
+			continue
+		}
+		if err := s.sent(reminder.ID); err != nil {
+			log.Warnf("%v", err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReminderStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reminders.json")
+	clock := &fakeClock{now: time.Date(2024, 5, 15, 8, 0, 0, 0, time.UTC)}
+	store, err := OpenReminders(path, clock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	nightly, err := store.Add(Reminder{Creator: "U1", User: "U1", Text: "check the nightly runs", Due: clock.now.Add(2 * time.Hour)})
+	if err != nil || nightly.ID != "1" {
+		t.Fatalf("expected reminder 1 to be added, got %+v: %v", nightly, err)
+	}
+	thread, err := store.Add(Reminder{Creator: "U2", User: "U2", Channel: "C1", Thread: "1.1", Text: "follow up", Due: clock.now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.Add(Reminder{Creator: "U1", User: "U1", Due: clock.now}); err == nil {
+		t.Errorf("expected a reminder which is not in the future to be rejected")
+	}
+
+	// reminders persist across restarts
+	store, err = OpenReminders(path, clock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reminders := store.List(""); len(reminders) != 2 || reminders[0].ID != thread.ID {
+		t.Fatalf("expected both reminders ordered by when they are due, got %+v", reminders)
+	}
+	if reminders := store.List("U1"); len(reminders) != 1 || reminders[0].ID != nightly.ID {
+		t.Errorf("expected only the reminders of U1, got %+v", reminders)
+	}
+
+	// the bot was down when the thread reminder was due so it is sent late
+	due := store.Due(clock.now.Add(90 * time.Minute))
+	if len(due) != 1 || due[0].ID != thread.ID || due[0].Thread != "1.1" {
+		t.Fatalf("expected the thread reminder to be due, got %+v", due)
+	}
+
+	// reminders stay in the store until they are sent, and are retried with a backoff when sending fails
+	clock.now = clock.now.Add(90 * time.Minute)
+	var sent []string
+	send := func(ctx context.Context, reminder Reminder) error {
+		if reminder.Attempts < 2 {
+			return errors.New("slack is down")
+		}
+		sent = append(sent, reminder.ID)
+		return nil
+	}
+	store.sendDue(context.TODO(), send)
+	if due := store.Due(clock.now); len(due) != 0 {
+		t.Fatalf("expected the failed reminder to wait before it is retried, got %+v", due)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	store.sendDue(context.TODO(), send)
+	clock.now = clock.now.Add(time.Minute)
+	if due := store.Due(clock.now); len(due) != 0 {
+		t.Fatalf("expected the wait to double after the second failure, got %+v", due)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	store.sendDue(context.TODO(), send)
+	if len(sent) != 1 || sent[0] != thread.ID {
+		t.Fatalf("expected the thread reminder to be sent once, got %v", sent)
+	}
+	if due := store.Due(clock.now); len(due) != 0 {
+		t.Errorf("expected the sent reminder to be removed, got %+v", due)
+	}
+
+	if err := store.Delete(nightly.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.Get(nightly.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the deleted reminder to not be found, got %v", err)
+	}
+	store, err = OpenReminders(path, clock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reminders := store.List(""); len(reminders) != 0 {
+		t.Errorf("expected no reminders to be loaded, got %+v", reminders)
+	}
+	if added, _ := store.Add(Reminder{Due: clock.now.Add(time.Minute)}); added.ID != "3" {
+		t.Errorf("expected IDs to not be reused, got %s", added.ID)
+	}
+}
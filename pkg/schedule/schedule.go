@@ -0,0 +1,215 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// MissedRunGrace is how late a schedule may run. Runs which were missed by more than this, for example while the bot
+// was down, are skipped rather than posted late.
+const MissedRunGrace = 10 * time.Minute
+
+// ErrNotFound is returned when a schedule does not exist.
+var ErrNotFound = errors.New("schedule not found")
+
+// Schedule runs a command on behalf of its creator and posts the output to a channel.
+type Schedule struct {
+	ID      string `json:"id"`
+	Creator string `json:"creator"`
+	Channel string `json:"channel"`
+	// Cron the cron expression describing when the command runs, such as `0 9 * * 1-5`.
+	Cron string `json:"cron"`
+	// Timezone the IANA name of the timezone the cron expression is evaluated in. Defaults to UTC.
+	Timezone string `json:"timezone,omitempty"`
+	// Command the tokens of the command, such as `ci pools list`.
+	Command []string  `json:"command"`
+	Created time.Time `json:"created"`
+	// Next the time of the next run.
+	Next    time.Time `json:"next"`
+	LastRun time.Time `json:"lastRun,omitempty"`
+}
+
+// NextAfter returns the first run of the schedule after t.
+func (s Schedule) NextAfter(t time.Time) (time.Time, error) {
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	location := time.UTC
+	if len(s.Timezone) > 0 {
+		location, err = time.LoadLocation(s.Timezone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown timezone %q", s.Timezone)
+		}
+	}
+	next := cron.Next(t.In(location))
+	if next.IsZero() {
+		return next, fmt.Errorf("cron expression %q never matches", s.Cron)
+	}
+	return next, nil
+}
+
+// Clock provides the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// storeFile is the content of the file the store is persisted to
+type storeFile struct {
+	// LastID the ID of the most recently added schedule, kept so that IDs are not reused.
+	LastID    int        `json:"lastID"`
+	Schedules []Schedule `json:"schedules"`
+}
+
+// Store holds the schedules and persists them as JSON. Schedules are only kept in memory when the store has no path.
+type Store struct {
+	clock Clock
+	store *util.JSONStore[storeFile]
+}
+
+// Open returns a store which persists schedules to the file at path. Existing schedules are loaded from the file.
+func Open(path string, clock Clock) (*Store, error) {
+	store, err := util.OpenJSONStore[storeFile](path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load schedules: %v", err)
+	}
+	return &Store{clock: clock, store: store}, nil
+}
+
+// Add validates the schedule, assigns its ID and computes its next run.
+func (s *Store) Add(schedule Schedule) (Schedule, error) {
+	now := s.clock.Now()
+	next, err := schedule.NextAfter(now)
+	if err != nil {
+		return schedule, err
+	}
+	err = s.store.Update(func(file *storeFile) error {
+		file.LastID++
+		schedule.ID = strconv.Itoa(file.LastID)
+		schedule.Created = now
+		schedule.Next = next
+		file.Schedules = append(file.Schedules, schedule)
+		return nil
+	})
+	return schedule, err
+}
+
+// Get returns the schedule with the ID.
+func (s *Store) Get(id string) (Schedule, error) {
+	found, err := Schedule{}, ErrNotFound
+	s.store.View(func(file *storeFile) {
+		for _, schedule := range file.Schedules {
+			if schedule.ID == id {
+				found, err = schedule, nil
+				return
+			}
+		}
+	})
+	return found, err
+}
+
+// Delete removes the schedule with the ID.
+func (s *Store) Delete(id string) error {
+	return s.store.Update(func(file *storeFile) error {
+		for index, schedule := range file.Schedules {
+			if schedule.ID == id {
+				file.Schedules = append(file.Schedules[:index], file.Schedules[index+1:]...)
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+}
+
+// List returns the schedules ordered by their next run.
+func (s *Store) List() []Schedule {
+	var schedules []Schedule
+	s.store.View(func(file *storeFile) {
+		schedules = append(schedules, file.Schedules...)
+	})
+	sort.SliceStable(schedules, func(i, j int) bool {
+		return schedules[i].Next.Before(schedules[j].Next)
+	})
+	return schedules
+}
+
+// Due returns the schedules whose next run is at or before now and advances them to their following run. Runs missed
+// by more than MissedRunGrace are skipped.
+func (s *Store) Due(now time.Time) []Schedule {
+	var due []Schedule
+	err := s.store.Apply(func(file *storeFile) {
+		for index := range file.Schedules {
+			schedule := &file.Schedules[index]
+			if schedule.Next.After(now) {
+				continue
+			}
+			if now.Sub(schedule.Next) > MissedRunGrace {
+				log.Warnf("skipping the run of schedule %s missed at %s", schedule.ID, schedule.Next)
+			} else {
+				schedule.LastRun = now
+				due = append(due, *schedule)
+			}
+			next, err := schedule.NextAfter(now)
+			if err != nil {
+				// the schedule was valid when it was added. keep it so that its creator can see and delete it.
+				log.Warnf("unable to compute the next run of schedule %s: %v", schedule.ID, err)
+				next = now.Add(24 * time.Hour)
+			}
+			schedule.Next = next
+		}
+	})
+	if err != nil {
+		// the schedules are still advanced so that they don't run again on the next check
+		log.Warnf("%v", err)
+	}
+	return due
+}
+
+// Run calls run for each schedule when it is due, checking every interval until ctx is done. Each run is made on its
+// own goroutine so that a slow command doesn't delay the other schedules. A run is skipped when the previous run of
+// the schedule is still in progress.
+func (s *Store) Run(ctx context.Context, interval time.Duration, run func(ctx context.Context, schedule Schedule)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	var mu sync.Mutex
+	running := map[string]bool{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, schedule := range s.Due(s.clock.Now()) {
+				mu.Lock()
+				if running[schedule.ID] {
+					mu.Unlock()
+					log.Warnf("skipping the run of schedule %s because its previous run is still in progress", schedule.ID)
+					continue
+				}
+				running[schedule.ID] = true
+				mu.Unlock()
+
+				wg.Add(1)
+				go func(schedule Schedule) {
+					defer wg.Done()
+					defer func() {
+						mu.Lock()
+						delete(running, schedule.ID)
+						mu.Unlock()
+					}()
+					run(ctx, schedule)
+				}(schedule)
+			}
+		}
+	}
+}
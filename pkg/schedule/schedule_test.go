@@ -0,0 +1,135 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedules.json")
+	clock := &fakeClock{now: time.Date(2024, 5, 15, 8, 0, 0, 0, time.UTC)}
+	store, err := Open(path, clock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	standup, err := store.Add(Schedule{Creator: "U1", Channel: "C1", Cron: "0 9 * * 1-5", Command: []string{"ci", "pools", "list"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if standup.ID != "1" || !standup.Next.Equal(time.Date(2024, 5, 15, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected schedule 1 to run at 9:00, got %s at %s", standup.ID, standup.Next)
+	}
+	weekly, err := store.Add(Schedule{Creator: "U2", Channel: "C2", Cron: "0 8 * * 1", Command: []string{"provider-summary", "vsphere"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.Add(Schedule{Cron: "0 9 * *"}); err == nil {
+		t.Errorf("expected an invalid cron expression to be rejected")
+	}
+	if _, err := store.Add(Schedule{Cron: "0 9 * * *", Timezone: "Mars/Olympus_Mons"}); err == nil {
+		t.Errorf("expected an unknown timezone to be rejected")
+	}
+
+	// schedules persist across restarts
+	store, err = Open(path, clock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	schedules := store.List()
+	if len(schedules) != 2 || schedules[0].ID != standup.ID || schedules[1].ID != weekly.ID {
+		t.Fatalf("expected both schedules ordered by their next run, got %+v", schedules)
+	}
+
+	if due := store.Due(time.Date(2024, 5, 15, 8, 59, 0, 0, time.UTC)); len(due) != 0 {
+		t.Errorf("expected no schedules to be due, got %+v", due)
+	}
+	due := store.Due(time.Date(2024, 5, 15, 9, 0, 30, 0, time.UTC))
+	if len(due) != 1 || due[0].ID != standup.ID {
+		t.Fatalf("expected the standup schedule to be due, got %+v", due)
+	}
+	standup, err = store.Get(standup.ID)
+	if err != nil || !standup.Next.Equal(time.Date(2024, 5, 16, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the standup schedule to next run tomorrow, got %s: %v", standup.Next, err)
+	}
+
+	// the bot was down at 9:00 so the run is skipped rather than posted late
+	if due := store.Due(time.Date(2024, 5, 16, 11, 0, 0, 0, time.UTC)); len(due) != 0 {
+		t.Errorf("expected the missed run to be skipped, got %+v", due)
+	}
+	if standup, _ = store.Get(standup.ID); !standup.Next.Equal(time.Date(2024, 5, 17, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the standup schedule to next run on Friday, got %s", standup.Next)
+	}
+
+	if err := store.Delete(weekly.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Delete(weekly.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected deleting a deleted schedule to fail, got %v", err)
+	}
+	store, err = Open(path, clock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schedules := store.List(); len(schedules) != 1 {
+		t.Errorf("expected the deleted schedule to not be loaded, got %+v", schedules)
+	}
+	if added, _ := store.Add(Schedule{Cron: "@daily"}); added.ID != "3" {
+		t.Errorf("expected IDs to not be reused, got %s", added.ID)
+	}
+}
+
+func TestRunIsConcurrent(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 15, 8, 0, 0, 0, time.UTC)}
+	store, err := Open("", clock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, command := range []string{"slow", "fast"} {
+		if _, err := store.Add(Schedule{Cron: "* * * * *", Command: []string{command}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	clock.now = clock.now.Add(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	release := make(chan struct{})
+	ran := make(chan string, 2)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		store.Run(ctx, 5*time.Millisecond, func(ctx context.Context, schedule Schedule) {
+			if schedule.Command[0] == "slow" {
+				<-release
+			}
+			ran <- schedule.Command[0]
+		})
+	}()
+
+	// the fast schedule runs while the slow schedule is still running
+	select {
+	case command := <-ran:
+		if command != "fast" {
+			t.Errorf("expected the fast schedule to finish first, got %s", command)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the fast schedule to run without waiting for the slow schedule")
+	}
+	close(release)
+	if command := <-ran; command != "slow" {
+		t.Errorf("expected the slow schedule to finish, got %s", command)
+	}
+	cancel()
+	<-stopped
+}
@@ -0,0 +1,184 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultHour is the time of day used when a day is given without a time, such as `tomorrow`
+const defaultHour = 9
+
+var (
+	clockRegex = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+
+	durationUnits = map[string]time.Duration{
+		"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+		"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+		"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	}
+
+	weekdays = map[string]time.Weekday{
+		"sun": time.Sunday, "sunday": time.Sunday,
+		"mon": time.Monday, "monday": time.Monday,
+		"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+		"wed": time.Wednesday, "wednesday": time.Wednesday,
+		"thu": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+		"fri": time.Friday, "friday": time.Friday,
+		"sat": time.Saturday, "saturday": time.Saturday,
+	}
+)
+
+// ErrNoTime is returned by ParseWhen when the arguments do not start with a time.
+var ErrNoTime = errors.New("expected a time such as `in 2h`, `at 5pm`, `tomorrow 9am`, `friday 14:30` or `2024-05-20 9am`")
+
+// ParseWhen parses the time at the start of args and returns it along with the arguments which follow it. Absolute
+// times, such as `tomorrow 9am`, are interpreted in the location of now. The time must be after now.
+func ParseWhen(args []string, now time.Time) (time.Time, []string, error) {
+	if len(args) == 0 {
+		return time.Time{}, nil, ErrNoTime
+	}
+	first := strings.ToLower(args[0])
+
+	if first == "in" {
+		duration, consumed, err := parseRelative(args[1:])
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+		return now.Add(duration), args[1+consumed:], nil
+	}
+
+	// the day, when one is given, is followed by an optional time
+	var day time.Time
+	dayGiven := true
+	consumed := 1
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekday, isWeekday := weekdays[first]
+	switch {
+	case first == "today":
+		day = today
+	case first == "tomorrow":
+		day = today.AddDate(0, 0, 1)
+	case isWeekday:
+		day = today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7)
+	default:
+		date, err := time.ParseInLocation("2006-01-02", first, now.Location())
+		if err == nil {
+			day = date
+		} else {
+			dayGiven = false
+			consumed = 0
+			day = today
+		}
+	}
+
+	hour, minute, clockConsumed, err := parseClock(args[consumed:])
+	if errors.Is(err, ErrNoTime) && dayGiven {
+		hour, minute = defaultHour, 0
+	} else if err != nil {
+		return time.Time{}, nil, err
+	}
+	consumed += clockConsumed
+
+	when := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location())
+	if !when.After(now) {
+		switch {
+		case !dayGiven:
+			// a time without a day is the next time the clock shows it
+			when = time.Date(day.Year(), day.Month(), day.Day()+1, hour, minute, 0, 0, now.Location())
+		case isWeekday:
+			when = time.Date(day.Year(), day.Month(), day.Day()+7, hour, minute, 0, 0, now.Location())
+		default:
+			return time.Time{}, nil, fmt.Errorf("%s has already passed", when.Format("Mon Jan 2 15:04 MST"))
+		}
+	}
+	return when, args[consumed:], nil
+}
+
+// parseRelative parses a duration such as `2h`, `1h30m`, `3d` or `2 hours`. The number of arguments consumed is
+// returned.
+func parseRelative(args []string) (time.Duration, int, error) {
+	if len(args) == 0 {
+		return 0, 0, ErrNoTime
+	}
+	value := strings.ToLower(args[0])
+
+	// a number followed by a unit, such as `2 hours`
+	if count, err := strconv.Atoi(value); err == nil && len(args) > 1 {
+		unit, ok := durationUnits[strings.ToLower(args[1])]
+		if !ok || count <= 0 {
+			return 0, 0, fmt.Errorf("invalid duration %q", strings.Join(args[:2], " "))
+		}
+		return time.Duration(count) * unit, 2, nil
+	}
+
+	// time.ParseDuration does not support days or weeks
+	for _, suffix := range []string{"d", "w"} {
+		if count, found := strings.CutSuffix(value, suffix); found {
+			if number, err := strconv.Atoi(count); err == nil && number > 0 {
+				return time.Duration(number) * durationUnits[suffix], 1, nil
+			}
+		}
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, 0, fmt.Errorf("invalid duration %q. expected a duration such as 30m, 2h or 3d", args[0])
+	}
+	return duration, 1, nil
+}
+
+// parseClock parses a time of day such as `9am`, `at 9:30pm`, `17:00`, `9 am` or `noon`. The number of arguments
+// consumed is returned.
+func parseClock(args []string) (int, int, int, error) {
+	consumed := 0
+	if len(args) > 0 && strings.EqualFold(args[0], "at") {
+		consumed++
+	}
+	if consumed >= len(args) {
+		return 0, 0, 0, ErrNoTime
+	}
+	value := strings.ToLower(args[consumed])
+	consumed++
+	switch value {
+	case "noon":
+		return 12, 0, consumed, nil
+	case "midnight":
+		return 0, 0, consumed, nil
+	}
+	// the meridiem may be a separate argument
+	if consumed < len(args) {
+		if next := strings.ToLower(args[consumed]); (next == "am" || next == "pm") && !strings.HasSuffix(value, "m") {
+			value += next
+			consumed++
+		}
+	}
+
+	match := clockRegex.FindStringSubmatch(value)
+	// a bare number is not a time unless it is preceded by `at`
+	if match == nil || (len(match[2]) == 0 && len(match[3]) == 0 && !strings.EqualFold(args[0], "at")) {
+		return 0, 0, 0, ErrNoTime
+	}
+	hour, _ := strconv.Atoi(match[1])
+	minute := 0
+	if len(match[2]) > 0 {
+		minute, _ = strconv.Atoi(match[2])
+	}
+	switch match[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, 0, fmt.Errorf("invalid time %q", value)
+		}
+		hour %= 12
+		if match[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, 0, fmt.Errorf("invalid time %q", value)
+	}
+	return hour, minute, consumed, nil
+}
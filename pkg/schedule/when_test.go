@@ -0,0 +1,68 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWhen(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data is not available: %v", err)
+	}
+	// a Wednesday
+	now := time.Date(2024, 5, 15, 14, 30, 0, 0, location)
+	cases := []struct {
+		text     string
+		expected time.Time
+		rest     string
+	}{
+		{"in 2h check the nightly runs", now.Add(2 * time.Hour), "check the nightly runs"},
+		{"in 1h30m check", now.Add(90 * time.Minute), "check"},
+		{"in 3d check", now.Add(72 * time.Hour), "check"},
+		{"in 2 hours check", now.Add(2 * time.Hour), "check"},
+		{"in 1 week check", now.Add(7 * 24 * time.Hour), "check"},
+		{"tomorrow 9am check", time.Date(2024, 5, 16, 9, 0, 0, 0, location), "check"},
+		{"tomorrow at 9:30 pm check", time.Date(2024, 5, 16, 21, 30, 0, 0, location), "check"},
+		{"tomorrow check", time.Date(2024, 5, 16, 9, 0, 0, 0, location), "check"},
+		{"today 17:00 check", time.Date(2024, 5, 15, 17, 0, 0, 0, location), "check"},
+		{"at 5pm check", time.Date(2024, 5, 15, 17, 0, 0, 0, location), "check"},
+		{"at 9 check", time.Date(2024, 5, 16, 9, 0, 0, 0, location), "check"},
+		{"8am check", time.Date(2024, 5, 16, 8, 0, 0, 0, location), "check"},
+		{"noon check", time.Date(2024, 5, 16, 12, 0, 0, 0, location), "check"},
+		{"friday 14:30 check", time.Date(2024, 5, 17, 14, 30, 0, 0, location), "check"},
+		{"wed 9am check", time.Date(2024, 5, 22, 9, 0, 0, 0, location), "check"},
+		{"Wednesday 4pm check", time.Date(2024, 5, 15, 16, 0, 0, 0, location), "check"},
+		{"2024-06-01 check", time.Date(2024, 6, 1, 9, 0, 0, 0, location), "check"},
+		{"2024-06-01 at 10:15am", time.Date(2024, 6, 1, 10, 15, 0, 0, location), ""},
+	}
+	for _, c := range cases {
+		when, rest, err := ParseWhen(strings.Fields(c.text), now)
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", c.text, err)
+			continue
+		}
+		if !when.Equal(c.expected) || strings.Join(rest, " ") != c.rest {
+			t.Errorf("expected %q to be %s followed by %q, got %s followed by %q", c.text, c.expected, c.rest, when, strings.Join(rest, " "))
+		}
+	}
+
+	invalid := map[string]string{
+		"":                   "expected a time",
+		"check the runs":     "expected a time",
+		"in soon check":      "invalid duration",
+		"in 0m check":        "invalid duration",
+		"in 2 fortnights":    "invalid duration",
+		"tomorrow 13pm":      "invalid time",
+		"at 25:00":           "invalid time",
+		"today 9am check":    "has already passed",
+		"2024-01-01 10am go": "has already passed",
+	}
+	for text, expected := range invalid {
+		_, _, err := ParseWhen(strings.Fields(text), now)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q for %q, got %v", expected, text, err)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/commands"
+	"github.com/openshift-splat-team/splat-bot/pkg/dispatch"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	EventsPath       = "/slack/events"
+	InteractionsPath = "/slack/interactions"
+	CommandsPath     = "/slack/commands"
+	HealthPath       = "/healthz"
+
+	// maxBodySize Slack payloads are well under this limit
+	maxBodySize = 1 << 20
+)
+
+// Server serves the Slack Events API, interactivity and slash command endpoints over HTTP. This is an alternative to
+// Socket Mode for deployments which can receive requests from Slack. Every request is verified with the app's
+// signing secret before it is handled.
+type Server struct {
+	signingSecret string
+	client        util.SlackClientInterface
+	dispatcher    *dispatch.Dispatcher
+
+	// handlers are replaced in tests
+	handleEvent       func(ctx context.Context, client util.SlackClientInterface, evt slackevents.EventsAPIEvent) error
+	handleInteraction func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback) error
+	handleSlash       func(ctx context.Context, client util.SlackClientInterface, cmd slack.SlashCommand) error
+}
+
+// New returns a server which dispatches the requests it receives to the commands package.
+func New(signingSecret string, client util.SlackClientInterface, dispatcher *dispatch.Dispatcher) (*Server, error) {
+	if len(signingSecret) == 0 {
+		return nil, errors.New("a signing secret is required to verify requests from Slack")
+	}
+	return &Server{
+		signingSecret:     signingSecret,
+		client:            client,
+		dispatcher:        dispatcher,
+		handleEvent:       commands.Handler,
+		handleInteraction: commands.InteractionHandler,
+		handleSlash:       commands.SlashHandler,
+	}, nil
+}
+
+// Handler returns the HTTP handler which serves the Slack endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(EventsPath, s.verified(s.serveEvent))
+	mux.HandleFunc(InteractionsPath, s.verified(s.serveInteraction))
+	mux.HandleFunc(CommandsPath, s.verified(s.serveSlashCommand))
+	mux.HandleFunc(HealthPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+// ListenAndServe serves the Slack endpoints on address until ctx is cancelled. It returns once the requests in flight
+// have been handled.
+func (s *Server) ListenAndServe(ctx context.Context, address string) error {
+	httpServer := &http.Server{
+		Addr:              address,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Warnf("unable to shut down server: %v", err)
+		}
+	}()
+
+	log.Infof("serving Slack events on %s", address)
+	err := httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		// ListenAndServe returns as soon as the shutdown begins, so wait for the requests in flight to be handled
+		<-shutdown
+		return nil
+	}
+	return err
+}
+
+// verified only passes requests to next which were signed with the signing secret within the last few minutes. The
+// body of the request is replaced so that it can be read again by next.
+func (s *Server) verified(next func(w http.ResponseWriter, r *http.Request, body []byte)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		verifier, err := slack.NewSecretsVerifier(r.Header, s.signingSecret)
+		if err != nil {
+			log.Warnf("rejecting request to %s: %v", r.URL.Path, err)
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, "unable to read request", http.StatusBadRequest)
+			return
+		}
+		if _, err := verifier.Write(body); err != nil {
+			http.Error(w, "unable to verify request", http.StatusInternalServerError)
+			return
+		}
+		if err := verifier.Ensure(); err != nil {
+			log.Warnf("rejecting request to %s: %v", r.URL.Path, err)
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next(w, r, body)
+	}
+}
+
+// dispatch queues the job which handles a request. When the job can't be queued, Slack is asked to retry the request
+// later and false is returned.
+func (s *Server) dispatch(key, name string, w http.ResponseWriter, job dispatch.Job) bool {
+	if err := s.dispatcher.Dispatch(key, name, job); err != nil {
+		log.Warnf("rejecting %s: %v", name, err)
+		http.Error(w, "unable to handle the request now", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+func (s *Server) serveEvent(w http.ResponseWriter, r *http.Request, body []byte) {
+	evt, err := slackevents.ParseEvent(body, slackevents.OptionNoVerifyToken())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to parse event: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	switch evt.Type {
+	case slackevents.URLVerification:
+		challenge := &slackevents.ChallengeResponse{}
+		if err := json.Unmarshal(body, challenge); err != nil {
+			http.Error(w, "unable to parse challenge", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(challenge.Challenge))
+	case slackevents.CallbackEvent:
+		// Slack expects a response within three seconds, so events are acknowledged before they are handled
+		log.Debugf("event received: %+v\n", evt)
+		if !s.dispatch(commands.EventOrderingKey(evt), "event "+evt.InnerEvent.Type, w, func(ctx context.Context) error {
+			return s.handleEvent(ctx, s.client, evt)
+		}) {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		log.Warnf("Unexpected event type received: %s\n", evt.Type)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (s *Server) serveInteraction(w http.ResponseWriter, r *http.Request, body []byte) {
+	callback := slack.InteractionCallback{}
+	if err := json.Unmarshal([]byte(r.FormValue("payload")), &callback); err != nil {
+		http.Error(w, fmt.Sprintf("unable to parse interaction: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if !s.dispatch(commands.InteractionOrderingKey(callback), "interaction "+string(callback.Type), w, func(ctx context.Context) error {
+		return s.handleInteraction(ctx, s.client, callback)
+	}) {
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) serveSlashCommand(w http.ResponseWriter, r *http.Request, body []byte) {
+	cmd, err := slack.SlashCommandParse(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to parse slash command: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if !s.dispatch(commands.SlashCommandOrderingKey(cmd), "slash command "+cmd.Command, w, func(ctx context.Context) error {
+		return s.handleSlash(ctx, s.client, cmd)
+	}) {
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
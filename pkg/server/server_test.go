@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/dispatch"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// signedRequest returns a request signed the way Slack signs requests with the signing secret
+func signedRequest(path, contentType, body string, timestamp time.Time) *http.Request {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	mac.Write([]byte(fmt.Sprintf("v0:%s:%s", ts, body)))
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+type recorder struct {
+	mu           sync.Mutex
+	events       []slackevents.EventsAPIEvent
+	interactions []slack.InteractionCallback
+	slash        []slack.SlashCommand
+}
+
+func newTestServer(t *testing.T) (*Server, *dispatch.Dispatcher, *recorder) {
+	dispatcher := dispatch.New(1, 10, 0)
+	dispatcher.Start(context.TODO())
+
+	srv, err := New(testSigningSecret, &util.StubInterface{}, dispatcher)
+	if err != nil {
+		t.Fatalf("unable to create server: %v", err)
+	}
+
+	rec := &recorder{}
+	srv.handleEvent = func(ctx context.Context, client util.SlackClientInterface, evt slackevents.EventsAPIEvent) error {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.events = append(rec.events, evt)
+		return nil
+	}
+	srv.handleInteraction = func(ctx context.Context, client util.SlackClientInterface, callback slack.InteractionCallback) error {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.interactions = append(rec.interactions, callback)
+		return nil
+	}
+	srv.handleSlash = func(ctx context.Context, client util.SlackClientInterface, cmd slack.SlashCommand) error {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.slash = append(rec.slash, cmd)
+		return nil
+	}
+	return srv, dispatcher, rec
+}
+
+const messageEvent = `{
+	"token": "ignored",
+	"team_id": "T1",
+	"api_app_id": "A1",
+	"type": "event_callback",
+	"event_id": "Ev1",
+	"event_time": 1700000000,
+	"event": {
+		"type": "message",
+		"channel": "C1",
+		"user": "U1",
+		"text": "hello",
+		"ts": "1700000000.000100"
+	}
+}`
+
+func TestNewRequiresSigningSecret(t *testing.T) {
+	if _, err := New("", &util.StubInterface{}, nil); err == nil {
+		t.Fatal("expected an error without a signing secret")
+	}
+}
+
+func TestURLVerification(t *testing.T) {
+	srv, dispatcher, _ := newTestServer(t)
+	defer dispatcher.Stop()
+
+	body := `{"token":"ignored","challenge":"3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P","type":"url_verification"}`
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, signedRequest(EventsPath, "application/json", body, time.Now()))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if w.Body.String() != "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P" {
+		t.Fatalf("expected the challenge in the response, got %q", w.Body.String())
+	}
+}
+
+func TestRejectsUnverifiedRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		request func() *http.Request
+	}{
+		{
+			name: "missing signature",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, EventsPath, strings.NewReader(messageEvent))
+			},
+		},
+		{
+			name: "tampered body",
+			request: func() *http.Request {
+				req := signedRequest(EventsPath, "application/json", messageEvent, time.Now())
+				req.Body = httptest.NewRequest(http.MethodPost, EventsPath, strings.NewReader(strings.Replace(messageEvent, "hello", "goodbye", 1))).Body
+				return req
+			},
+		},
+		{
+			name: "stale timestamp",
+			request: func() *http.Request {
+				return signedRequest(EventsPath, "application/json", messageEvent, time.Now().Add(-10*time.Minute))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv, dispatcher, rec := newTestServer(t)
+			w := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(w, test.request())
+			dispatcher.Stop()
+
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("expected status 401, got %d", w.Code)
+			}
+			if len(rec.events) != 0 {
+				t.Fatalf("expected no events to be handled, got %d", len(rec.events))
+			}
+		})
+	}
+}
+
+func TestRejectsGet(t *testing.T) {
+	srv, dispatcher, _ := newTestServer(t)
+	defer dispatcher.Stop()
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, EventsPath, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status 405, got %d", w.Code)
+	}
+}
+
+func TestEvent(t *testing.T) {
+	srv, dispatcher, rec := newTestServer(t)
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, signedRequest(EventsPath, "application/json", messageEvent, time.Now()))
+	dispatcher.Stop()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(rec.events) != 1 {
+		t.Fatalf("expected 1 event to be handled, got %d", len(rec.events))
+	}
+	msg, ok := rec.events[0].InnerEvent.Data.(*slackevents.MessageEvent)
+	if !ok {
+		t.Fatalf("expected a message event, got %T", rec.events[0].InnerEvent.Data)
+	}
+	if msg.Text != "hello" || msg.Channel != "C1" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+}
+
+func TestInteraction(t *testing.T) {
+	srv, dispatcher, rec := newTestServer(t)
+
+	payload := `{"type":"view_submission","user":{"id":"U1"},"view":{"id":"V1","callback_id":"create_jira","private_metadata":"C1"}}`
+	body := url.Values{"payload": {payload}}.Encode()
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, signedRequest(InteractionsPath, "application/x-www-form-urlencoded", body, time.Now()))
+	dispatcher.Stop()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(rec.interactions) != 1 {
+		t.Fatalf("expected 1 interaction to be handled, got %d", len(rec.interactions))
+	}
+	callback := rec.interactions[0]
+	if callback.Type != slack.InteractionTypeViewSubmission || callback.View.PrivateMetadata != "C1" {
+		t.Fatalf("unexpected interaction: %+v", callback)
+	}
+}
+
+func TestSlashCommand(t *testing.T) {
+	srv, dispatcher, rec := newTestServer(t)
+
+	body := url.Values{
+		"command":      {"/splat"},
+		"text":         {"lease list"},
+		"channel_id":   {"C1"},
+		"user_id":      {"U1"},
+		"trigger_id":   {"T1"},
+		"response_url": {"https://hooks.slack.com/commands/1"},
+	}.Encode()
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, signedRequest(CommandsPath, "application/x-www-form-urlencoded", body, time.Now()))
+	dispatcher.Stop()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(rec.slash) != 1 {
+		t.Fatalf("expected 1 slash command to be handled, got %d", len(rec.slash))
+	}
+	if rec.slash[0].Command != "/splat" || rec.slash[0].Text != "lease list" {
+		t.Fatalf("unexpected slash command: %+v", rec.slash[0])
+	}
+}
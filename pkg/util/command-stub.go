@@ -22,6 +22,10 @@ type SlackClientInterface interface {
 	GetConversationReplies(params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error)
 	GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error)
 	OpenViewContext(ctx context.Context, triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error)
+	PublishViewContext(ctx context.Context, userID string, view slack.HomeTabViewRequest, hash string) (*slack.ViewResponse, error)
+	GetUserGroupMembers(userGroup string) ([]string, error)
+	GetPermalink(params *slack.PermalinkParameters) (string, error)
+	GetUserInfo(user string) (*slack.User, error)
 }
 
 type StubInterface struct {
@@ -57,3 +61,19 @@ func (s *StubInterface) GetConversationInfo(input *slack.GetConversationInfoInpu
 func (s *StubInterface) OpenViewContext(ctx context.Context, triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
 	return nil, fmt.Errorf("OpenViewContext")
 }
+
+func (s *StubInterface) PublishViewContext(ctx context.Context, userID string, view slack.HomeTabViewRequest, hash string) (*slack.ViewResponse, error) {
+	return nil, fmt.Errorf("PublishViewContext")
+}
+
+func (s *StubInterface) GetUserGroupMembers(userGroup string) ([]string, error) {
+	return nil, fmt.Errorf("GetUserGroupMembers")
+}
+
+func (s *StubInterface) GetPermalink(params *slack.PermalinkParameters) (string, error) {
+	return "", fmt.Errorf("GetPermalink")
+}
+
+func (s *StubInterface) GetUserInfo(user string) (*slack.User, error) {
+	return nil, fmt.Errorf("GetUserInfo")
+}
@@ -0,0 +1,476 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+)
+
+// fakeAPIURL is the API URL messages are rendered against. A message sent to any other endpoint was sent to the
+// response_url of an interaction.
+const fakeAPIURL = "https://fake.slack.test/api/"
+
+// FakeMessage is a message which was posted through a FakeClient.
+type FakeMessage struct {
+	Channel string
+	// User the recipient of an ephemeral message.
+	User      string
+	Ephemeral bool
+	// ThreadTimestamp the thread the message was posted in, if any.
+	ThreadTimestamp string
+	// ResponseURL the response_url the message was sent to when it replaces or deletes an interaction's message.
+	ResponseURL string
+	// Timestamp assigned to the message by the FakeClient.
+	Timestamp string
+	Text      string
+	Blocks    []slack.Block
+}
+
+// Render returns the text and blocks of the message as readable text.
+func (m FakeMessage) Render() string {
+	var lines []string
+	if len(m.Text) > 0 {
+		lines = append(lines, m.Text)
+	}
+	if rendered := RenderBlocks(m.Blocks); len(rendered) > 0 {
+		lines = append(lines, rendered)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NewFakeMessage builds the message which would be posted to channel with the options.
+func NewFakeMessage(channel string, options ...slack.MsgOption) (FakeMessage, error) {
+	endpoint, values, err := slack.UnsafeApplyMsgOptions("", channel, fakeAPIURL, options...)
+	if err != nil {
+		return FakeMessage{}, fmt.Errorf("unable to apply message options: %v", err)
+	}
+
+	message := FakeMessage{
+		Channel:         channel,
+		ThreadTimestamp: values.Get("thread_ts"),
+		Text:            values.Get("text"),
+	}
+	if !strings.HasPrefix(endpoint, fakeAPIURL) {
+		message.ResponseURL = endpoint
+	}
+	if encoded := values.Get("blocks"); len(encoded) > 0 {
+		blocks := slack.Blocks{}
+		if err := json.Unmarshal([]byte(encoded), &blocks); err != nil {
+			return FakeMessage{}, fmt.Errorf("unable to parse blocks: %v", err)
+		}
+		message.Blocks = blocks.BlockSet
+	}
+	return message, nil
+}
+
+// RenderMsgOptions returns the message built from the options as readable text.
+func RenderMsgOptions(options ...slack.MsgOption) string {
+	message, err := NewFakeMessage("", options...)
+	if err != nil {
+		return err.Error()
+	}
+	return message.Render()
+}
+
+// RenderBlocks returns Block Kit blocks as readable text. Interactive components are rendered as [label].
+func RenderBlocks(blocks []slack.Block) string {
+	var lines []string
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *slack.HeaderBlock:
+			lines = append(lines, renderText(b.Text))
+		case *slack.SectionBlock:
+			var parts []string
+			if text := renderText(b.Text); len(text) > 0 {
+				parts = append(parts, text)
+			}
+			for _, field := range b.Fields {
+				parts = append(parts, renderText(field))
+			}
+			if b.Accessory != nil {
+				if element := renderAccessory(b.Accessory); len(element) > 0 {
+					parts = append(parts, element)
+				}
+			}
+			lines = append(lines, strings.Join(parts, "\n"))
+		case *slack.DividerBlock:
+			lines = append(lines, "---")
+		case *slack.ContextBlock:
+			var parts []string
+			for _, element := range b.ContextElements.Elements {
+				if text, ok := element.(*slack.TextBlockObject); ok {
+					parts = append(parts, renderText(text))
+				}
+			}
+			lines = append(lines, strings.Join(parts, " "))
+		case *slack.ActionBlock:
+			var parts []string
+			for _, element := range b.Elements.ElementSet {
+				parts = append(parts, renderElement(element))
+			}
+			lines = append(lines, strings.Join(parts, " "))
+		case *slack.InputBlock:
+			lines = append(lines, renderText(b.Label)+": "+renderElement(b.Element))
+		case *slack.RichTextBlock:
+			lines = append(lines, renderRichText(b.Elements, 0))
+		default:
+			lines = append(lines, fmt.Sprintf("<%s>", block.BlockType()))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderText(text *slack.TextBlockObject) string {
+	if text == nil {
+		return ""
+	}
+	return text.Text
+}
+
+func renderAccessory(accessory *slack.Accessory) string {
+	switch {
+	case accessory.ButtonElement != nil:
+		return renderElement(accessory.ButtonElement)
+	case accessory.OverflowElement != nil:
+		return renderElement(accessory.OverflowElement)
+	case accessory.SelectElement != nil:
+		return renderElement(accessory.SelectElement)
+	}
+	return ""
+}
+
+func renderElement(element slack.BlockElement) string {
+	switch e := element.(type) {
+	case *slack.ButtonBlockElement:
+		return fmt.Sprintf("[%s]", renderText(e.Text))
+	case *slack.SelectBlockElement:
+		return fmt.Sprintf("[%s]", renderText(e.Placeholder))
+	case *slack.PlainTextInputBlockElement:
+		return fmt.Sprintf("[%s]", e.InitialValue)
+	case nil:
+		return ""
+	}
+	return fmt.Sprintf("[%s]", element.ElementType())
+}
+
+func renderRichText(elements []slack.RichTextElement, indent int) string {
+	var builder strings.Builder
+	for _, element := range elements {
+		switch e := element.(type) {
+		case *slack.RichTextSection:
+			builder.WriteString(strings.Repeat("  ", indent))
+			for _, sectionElement := range e.Elements {
+				builder.WriteString(renderRichTextSectionElement(sectionElement))
+			}
+		case *slack.RichTextList:
+			for _, item := range e.Elements {
+				builder.WriteString(strings.Repeat("  ", indent+e.Indent) + "• ")
+				builder.WriteString(strings.TrimLeft(renderRichText([]slack.RichTextElement{item}, 0), " "))
+				builder.WriteString("\n")
+			}
+		case *slack.RichTextPreformatted:
+			builder.WriteString("```")
+			for _, sectionElement := range e.Elements {
+				builder.WriteString(renderRichTextSectionElement(sectionElement))
+			}
+			builder.WriteString("```")
+		case *slack.RichTextQuote:
+			builder.WriteString("> ")
+			for _, sectionElement := range e.Elements {
+				builder.WriteString(renderRichTextSectionElement(sectionElement))
+			}
+		}
+	}
+	return builder.String()
+}
+
+func renderRichTextSectionElement(element slack.RichTextSectionElement) string {
+	switch e := element.(type) {
+	case *slack.RichTextSectionTextElement:
+		return e.Text
+	case *slack.RichTextSectionLinkElement:
+		if len(e.Text) > 0 {
+			return e.Text
+		}
+		return e.URL
+	case *slack.RichTextSectionEmojiElement:
+		return fmt.Sprintf(":%s:", e.Name)
+	case *slack.RichTextSectionUserElement:
+		return fmt.Sprintf("<@%s>", e.UserID)
+	case *slack.RichTextSectionChannelElement:
+		return fmt.Sprintf("<#%s>", e.ChannelID)
+	}
+	return ""
+}
+
+// FakeView is a modal which was opened through a FakeClient.
+type FakeView struct {
+	TriggerID string
+	View      slack.ModalViewRequest
+}
+
+// FakeHomeView is an App Home tab which was published through a FakeClient.
+type FakeHomeView struct {
+	UserID string
+	View   slack.HomeTabViewRequest
+	Hash   string
+}
+
+// FakeClient is an in-memory SlackClientInterface for tests. Messages, conversations and views are recorded so
+// that tests can assert what would have been sent to Slack. Threads, channels, user groups and users are served from
+// the values scripted by the test.
+type FakeClient struct {
+	mu sync.Mutex
+
+	messages      []FakeMessage
+	conversations []slack.OpenConversationParameters
+	views         []FakeView
+	homeViews     []FakeHomeView
+	timestamp     int
+
+	// Threads the messages returned by GetConversationReplies keyed by channel and thread timestamp. Use AddThread
+	// to add a thread.
+	Threads map[string][]slack.Message
+	// Channels the names of channels keyed by channel ID.
+	Channels map[string]string
+	// UserGroups the members of user groups keyed by user group ID.
+	UserGroups map[string][]string
+	// Users the profiles returned by GetUserInfo keyed by user ID.
+	Users map[string]slack.User
+	// Errors returned by methods, keyed by the name of the method. Calls which fail are not recorded.
+	Errors map[string]error
+}
+
+// NewFakeClient returns a FakeClient which knows about no threads, channels, user groups or users.
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		Threads:    map[string][]slack.Message{},
+		Channels:   map[string]string{},
+		UserGroups: map[string][]string{},
+		Users:      map[string]slack.User{},
+		Errors:     map[string]error{},
+	}
+}
+
+func threadKey(channel, timestamp string) string {
+	return channel + "/" + timestamp
+}
+
+// AddThread scripts the messages returned by GetConversationReplies for a thread.
+func (f *FakeClient) AddThread(channel, timestamp string, messages ...slack.Message) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Threads[threadKey(channel, timestamp)] = messages
+}
+
+// Messages returns the messages which have been posted, in the order they were posted.
+func (f *FakeClient) Messages() []FakeMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	messages := make([]FakeMessage, len(f.messages))
+	copy(messages, f.messages)
+	return messages
+}
+
+// LastMessage returns the most recently posted message. false is returned if no messages have been posted.
+func (f *FakeClient) LastMessage() (FakeMessage, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.messages) == 0 {
+		return FakeMessage{}, false
+	}
+	return f.messages[len(f.messages)-1], true
+}
+
+// Conversations returns the parameters of each call to OpenConversation.
+func (f *FakeClient) Conversations() []slack.OpenConversationParameters {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	conversations := make([]slack.OpenConversationParameters, len(f.conversations))
+	copy(conversations, f.conversations)
+	return conversations
+}
+
+// Views returns the modals which have been opened.
+func (f *FakeClient) Views() []FakeView {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	views := make([]FakeView, len(f.views))
+	copy(views, f.views)
+	return views
+}
+
+// HomeViews returns the App Home tabs which have been published, in the order they were published.
+func (f *FakeClient) HomeViews() []FakeHomeView {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	views := make([]FakeHomeView, len(f.homeViews))
+	copy(views, f.homeViews)
+	return views
+}
+
+// Reset forgets the recorded messages, conversations and views.
+func (f *FakeClient) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = nil
+	f.conversations = nil
+	f.views = nil
+	f.homeViews = nil
+}
+
+func (f *FakeClient) err(method string) error {
+	return f.Errors[method]
+}
+
+func (f *FakeClient) record(message FakeMessage) string {
+	f.timestamp++
+	message.Timestamp = fmt.Sprintf("1700000000.%06d", f.timestamp)
+	f.messages = append(f.messages, message)
+	return message.Timestamp
+}
+
+func (f *FakeClient) PostEphemeral(channelID string, userID string, options ...slack.MsgOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.err("PostEphemeral"); err != nil {
+		return "", err
+	}
+	message, err := NewFakeMessage(channelID, options...)
+	if err != nil {
+		return "", err
+	}
+	message.User = userID
+	message.Ephemeral = true
+	return f.record(message), nil
+}
+
+func (f *FakeClient) PostMessage(channelID string, options ...slack.MsgOption) (string, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.err("PostMessage"); err != nil {
+		return "", "", err
+	}
+	message, err := NewFakeMessage(channelID, options...)
+	if err != nil {
+		return "", "", err
+	}
+	return channelID, f.record(message), nil
+}
+
+// OpenConversation returns a DM channel whose ID is D followed by the IDs of the users.
+func (f *FakeClient) OpenConversation(params *slack.OpenConversationParameters) (*slack.Channel, bool, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.err("OpenConversation"); err != nil {
+		return nil, false, false, err
+	}
+	f.conversations = append(f.conversations, *params)
+
+	channelID := "D" + strings.Join(params.Users, "")
+	channel := &slack.Channel{}
+	channel.ID = channelID
+	channel.IsIM = true
+	channel.Latest = &slack.Message{Msg: slack.Msg{Channel: channelID}}
+	return channel, false, false, nil
+}
+
+func (f *FakeClient) GetConversationReplies(params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.err("GetConversationReplies"); err != nil {
+		return nil, false, "", err
+	}
+	thread, ok := f.Threads[threadKey(params.ChannelID, params.Timestamp)]
+	if !ok {
+		return nil, false, "", fmt.Errorf("thread_not_found")
+	}
+	return thread, false, "", nil
+}
+
+func (f *FakeClient) GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.err("GetConversationInfo"); err != nil {
+		return nil, err
+	}
+	name, ok := f.Channels[input.ChannelID]
+	if !ok {
+		return nil, fmt.Errorf("channel_not_found")
+	}
+	channel := &slack.Channel{}
+	channel.ID = input.ChannelID
+	channel.Name = name
+	return channel, nil
+}
+
+func (f *FakeClient) OpenViewContext(ctx context.Context, triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.err("OpenViewContext"); err != nil {
+		return nil, err
+	}
+	f.views = append(f.views, FakeView{TriggerID: triggerID, View: view})
+
+	response := &slack.ViewResponse{}
+	response.ID = fmt.Sprintf("V%d", len(f.views))
+	response.CallbackID = view.CallbackID
+	response.PrivateMetadata = view.PrivateMetadata
+	return response, nil
+}
+
+func (f *FakeClient) PublishViewContext(ctx context.Context, userID string, view slack.HomeTabViewRequest, hash string) (*slack.ViewResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.err("PublishViewContext"); err != nil {
+		return nil, err
+	}
+	f.homeViews = append(f.homeViews, FakeHomeView{UserID: userID, View: view, Hash: hash})
+
+	response := &slack.ViewResponse{}
+	response.ID = fmt.Sprintf("VH%d", len(f.homeViews))
+	response.Type = view.Type
+	response.CallbackID = view.CallbackID
+	return response, nil
+}
+
+func (f *FakeClient) GetUserGroupMembers(userGroup string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.err("GetUserGroupMembers"); err != nil {
+		return nil, err
+	}
+	members, ok := f.UserGroups[userGroup]
+	if !ok {
+		return nil, fmt.Errorf("no_such_subteam")
+	}
+	return members, nil
+}
+
+// GetPermalink returns a permalink in the format used by Slack.
+func (f *FakeClient) GetPermalink(params *slack.PermalinkParameters) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.err("GetPermalink"); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://fake.slack.test/archives/%s/p%s", params.Channel, strings.Replace(params.Ts, ".", "", 1)), nil
+}
+
+func (f *FakeClient) GetUserInfo(user string) (*slack.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.err("GetUserInfo"); err != nil {
+		return nil, err
+	}
+	info, ok := f.Users[user]
+	if !ok {
+		return nil, fmt.Errorf("user_not_found")
+	}
+	info.ID = user
+	return &info, nil
+}
@@ -0,0 +1,131 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestFakeClientRecordsMessages(t *testing.T) {
+	client := NewFakeClient()
+
+	if _, _, err := client.PostMessage("C1", append(StringToBlock("hello", false), slack.MsgOptionTS("1.1"))...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.PostEphemeral("C1", "U1", StringToBlock("just for you", false)...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := client.PostMessage("C1", slack.MsgOptionText("updated", false), slack.MsgOptionReplaceOriginal("https://hooks.slack.test/1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := client.Messages()
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(messages))
+	}
+	if messages[0].Text != "hello" || messages[0].ThreadTimestamp != "1.1" || messages[0].Ephemeral {
+		t.Errorf("unexpected message: %+v", messages[0])
+	}
+	if messages[1].Text != "just for you" || !messages[1].Ephemeral || messages[1].User != "U1" {
+		t.Errorf("unexpected ephemeral message: %+v", messages[1])
+	}
+	if messages[2].ResponseURL != "https://hooks.slack.test/1" {
+		t.Errorf("expected the message to be sent to the response url, got %+v", messages[2])
+	}
+	if messages[0].Timestamp == messages[1].Timestamp {
+		t.Errorf("expected each message to have a unique timestamp")
+	}
+
+	client.Errors["PostMessage"] = errors.New("channel_not_found")
+	if _, _, err := client.PostMessage("C2", StringToBlock("lost", false)...); err == nil {
+		t.Fatalf("expected the scripted error")
+	}
+	if len(client.Messages()) != 3 {
+		t.Fatalf("expected failed posts not to be recorded")
+	}
+
+	client.Reset()
+	if _, posted := client.LastMessage(); posted {
+		t.Fatalf("expected no messages after reset")
+	}
+}
+
+func TestFakeClientScriptedResponses(t *testing.T) {
+	client := NewFakeClient()
+	client.AddThread("C1", "1.1", slack.Message{Msg: slack.Msg{Text: "question"}}, slack.Message{Msg: slack.Msg{Text: "answer"}})
+	client.Channels["C1"] = "forum-splat"
+	client.UserGroups["S1"] = []string{"U1"}
+	client.Users["U1"] = slack.User{TZ: "Europe/London"}
+
+	thread, _, _, err := client.GetConversationReplies(&slack.GetConversationRepliesParameters{ChannelID: "C1", Timestamp: "1.1"})
+	if err != nil || len(thread) != 2 || thread[1].Text != "answer" {
+		t.Fatalf("expected the scripted thread, got %v: %v", thread, err)
+	}
+	if _, _, _, err := client.GetConversationReplies(&slack.GetConversationRepliesParameters{ChannelID: "C1", Timestamp: "2.2"}); err == nil {
+		t.Fatalf("expected an unknown thread to fail")
+	}
+
+	channel, err := client.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: "C1"})
+	if err != nil || channel.Name != "forum-splat" {
+		t.Fatalf("expected the scripted channel, got %v: %v", channel, err)
+	}
+
+	members, err := client.GetUserGroupMembers("S1")
+	if err != nil || len(members) != 1 {
+		t.Fatalf("expected the scripted user group, got %v: %v", members, err)
+	}
+
+	user, err := client.GetUserInfo("U1")
+	if err != nil || user.ID != "U1" || user.TZ != "Europe/London" {
+		t.Fatalf("expected the scripted user, got %v: %v", user, err)
+	}
+	if _, err := client.GetUserInfo("U2"); err == nil {
+		t.Fatalf("expected an unknown user to fail")
+	}
+
+	dm, _, _, err := client.OpenConversation(&slack.OpenConversationParameters{Users: []string{"U1"}})
+	if err != nil || dm.Latest.Channel != "DU1" {
+		t.Fatalf("expected a DM channel, got %v: %v", dm, err)
+	}
+
+	view, err := client.OpenViewContext(context.TODO(), "trigger", slack.ModalViewRequest{CallbackID: "create_jira"})
+	if err != nil || view.CallbackID != "create_jira" || len(client.Views()) != 1 {
+		t.Fatalf("expected the view to be recorded, got %v: %v", view, err)
+	}
+
+	home, err := client.PublishViewContext(context.TODO(), "U1", slack.HomeTabViewRequest{Type: slack.VTHomeTab}, "")
+	if err != nil || home.Type != slack.VTHomeTab || len(client.HomeViews()) != 1 || client.HomeViews()[0].UserID != "U1" {
+		t.Fatalf("expected the home tab to be recorded, got %v: %v", home, err)
+	}
+}
+
+func TestRenderBlocks(t *testing.T) {
+	options := []slack.MsgOption{
+		slack.MsgOptionBlocks(
+			slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "CI Pool Status", false, false)),
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "*pool-1* is schedulable", false, false), nil, nil),
+			slack.NewDividerBlock(),
+			slack.NewRichTextBlock("status",
+				slack.NewRichTextSection(
+					slack.NewRichTextSectionEmojiElement("large_green_circle", 2, nil),
+					slack.NewRichTextSectionTextElement(" pool-2", nil),
+				),
+			),
+			slack.NewActionBlock("actions", NewCloseButton()),
+		),
+	}
+
+	expected := strings.Join([]string{
+		"CI Pool Status",
+		"*pool-1* is schedulable",
+		"---",
+		":large_green_circle: pool-2",
+		"[Close]",
+	}, "\n")
+	if rendered := RenderMsgOptions(options...); rendered != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, rendered)
+	}
+}
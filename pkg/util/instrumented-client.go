@@ -0,0 +1,79 @@
+package util
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
+)
+
+// InstrumentedClient counts the calls to the Slack API which fail.
+type InstrumentedClient struct {
+	client SlackClientInterface
+}
+
+// NewInstrumentedClient returns a client which calls client and records its failures in the Slack API metrics.
+func NewInstrumentedClient(client SlackClientInterface) *InstrumentedClient {
+	return &InstrumentedClient{client: client}
+}
+
+func (c *InstrumentedClient) PostEphemeral(channelID string, userID string, options ...slack.MsgOption) (string, error) {
+	timestamp, err := c.client.PostEphemeral(channelID, userID, options...)
+	metrics.ObserveSlackAPI("chat.postEphemeral", err)
+	return timestamp, err
+}
+
+func (c *InstrumentedClient) PostMessage(channelID string, options ...slack.MsgOption) (string, string, error) {
+	channel, timestamp, err := c.client.PostMessage(channelID, options...)
+	metrics.ObserveSlackAPI("chat.postMessage", err)
+	return channel, timestamp, err
+}
+
+func (c *InstrumentedClient) OpenConversation(params *slack.OpenConversationParameters) (*slack.Channel, bool, bool, error) {
+	channel, noOp, alreadyOpen, err := c.client.OpenConversation(params)
+	metrics.ObserveSlackAPI("conversations.open", err)
+	return channel, noOp, alreadyOpen, err
+}
+
+func (c *InstrumentedClient) GetConversationReplies(params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error) {
+	msgs, hasMore, nextCursor, err = c.client.GetConversationReplies(params)
+	metrics.ObserveSlackAPI("conversations.replies", err)
+	return msgs, hasMore, nextCursor, err
+}
+
+func (c *InstrumentedClient) GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	channel, err := c.client.GetConversationInfo(input)
+	metrics.ObserveSlackAPI("conversations.info", err)
+	return channel, err
+}
+
+func (c *InstrumentedClient) OpenViewContext(ctx context.Context, triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	response, err := c.client.OpenViewContext(ctx, triggerID, view)
+	metrics.ObserveSlackAPI("views.open", err)
+	return response, err
+}
+
+func (c *InstrumentedClient) PublishViewContext(ctx context.Context, userID string, view slack.HomeTabViewRequest, hash string) (*slack.ViewResponse, error) {
+	response, err := c.client.PublishViewContext(ctx, userID, view, hash)
+	metrics.ObserveSlackAPI("views.publish", err)
+	return response, err
+}
+
+func (c *InstrumentedClient) GetUserGroupMembers(userGroup string) ([]string, error) {
+	members, err := c.client.GetUserGroupMembers(userGroup)
+	metrics.ObserveSlackAPI("usergroups.users.list", err)
+	return members, err
+}
+
+func (c *InstrumentedClient) GetPermalink(params *slack.PermalinkParameters) (string, error) {
+	permalink, err := c.client.GetPermalink(params)
+	metrics.ObserveSlackAPI("chat.getPermalink", err)
+	return permalink, err
+}
+
+func (c *InstrumentedClient) GetUserInfo(user string) (*slack.User, error) {
+	info, err := c.client.GetUserInfo(user)
+	metrics.ObserveSlackAPI("users.info", err)
+	return info, err
+}
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"errors"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
+)
+
+func slackAPIErrors(t *testing.T, method string) float64 {
+	t.Helper()
+	var out dto.Metric
+	if err := metrics.SlackAPIErrors.WithLabelValues(method).Write(&out); err != nil {
+		t.Fatalf("unable to read metric: %v", err)
+	}
+	return out.Counter.GetValue()
+}
+
+func TestInstrumentedClient(t *testing.T) {
+	fake := NewFakeClient()
+	client := NewInstrumentedClient(fake)
+	before := slackAPIErrors(t, "chat.postMessage")
+
+	if _, _, err := client.PostMessage("C1", StringToBlock("hello", false)...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := fake.LastMessage(); !ok {
+		t.Errorf("expected the message to be passed to the wrapped client")
+	}
+	fake.Errors["PostMessage"] = errors.New("channel_not_found")
+	if _, _, err := client.PostMessage("C1", StringToBlock("hello", false)...); err == nil {
+		t.Errorf("expected the error of the wrapped client to be returned")
+	}
+	if count := slackAPIErrors(t, "chat.postMessage") - before; count != 1 {
+		t.Errorf("expected only the failed call to be counted, got %v", count)
+	}
+}
@@ -0,0 +1,159 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	logrus "github.com/sirupsen/logrus"
+)
+
+// ReadJSON parses the file at path into value. A file which does not exist is left for WriteJSON to create.
+func ReadJSON(path string, value any) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, value); err != nil {
+		return fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	return nil
+}
+
+// WriteJSON writes value to a temporary file which replaces the file at path so that a failed write does not lose
+// the previous content.
+func WriteJSON(path string, value any) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal %s: %v", path, err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(content); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// JSONStore holds a value which is persisted to a file as JSON. The value is only kept in memory when the store has no
+// path. It is safe for concurrent use.
+type JSONStore[T any] struct {
+	mu    sync.Mutex
+	path  string
+	value T
+	// dirty is true when changes made by Defer have not been persisted
+	dirty bool
+}
+
+// OpenJSONStore returns a store which persists its value to the file at path. The value is loaded from the file if
+// it exists.
+func OpenJSONStore[T any](path string) (*JSONStore[T], error) {
+	store := &JSONStore[T]{path: path}
+	if len(path) == 0 {
+		return store, nil
+	}
+	if err := ReadJSON(path, &store.value); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// View calls view with the value. view must not modify the value or keep references to it.
+func (s *JSONStore[T]) View(view func(value *T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	view(&s.value)
+}
+
+// Update calls update with the value and persists the changes. The value is left unchanged if update returns an
+// error or the changes can't be persisted.
+func (s *JSONStore[T]) Update(update func(value *T) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := json.Marshal(s.value)
+	if err != nil {
+		return fmt.Errorf("unable to marshal %s: %v", s.path, err)
+	}
+	if err := update(&s.value); err != nil {
+		s.restore(snapshot)
+		return err
+	}
+	if len(s.path) == 0 {
+		return nil
+	}
+	// the file is only rewritten when update changed the value
+	if updated, err := json.Marshal(s.value); err == nil && bytes.Equal(updated, snapshot) {
+		return nil
+	}
+	if err := WriteJSON(s.path, s.value); err != nil {
+		s.restore(snapshot)
+		return fmt.Errorf("unable to save %s: %v", s.path, err)
+	}
+	s.dirty = false
+	return nil
+}
+
+// Apply calls apply with the value and persists the changes. Unlike Update, the changes are kept when they can't be
+// persisted, and are persisted by the next write, so that the value reflects work which has already been done.
+func (s *JSONStore[T]) Apply(apply func(value *T)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	apply(&s.value)
+	s.dirty = true
+	return s.flushLocked()
+}
+
+// Defer calls change with the value without persisting the changes. Use it for frequent changes which may be lost if
+// the process exits before they are persisted by Flush or the next write.
+func (s *JSONStore[T]) Defer(change func(value *T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change(&s.value)
+	s.dirty = true
+}
+
+// Flush persists the changes which have not been persisted
+func (s *JSONStore[T]) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flushLocked()
+}
+
+// flushLocked persists the value if it has changes which have not been persisted. The caller must hold mu.
+func (s *JSONStore[T]) flushLocked() error {
+	if !s.dirty || len(s.path) == 0 {
+		return nil
+	}
+	if err := WriteJSON(s.path, s.value); err != nil {
+		return fmt.Errorf("unable to save %s: %v", s.path, err)
+	}
+	s.dirty = false
+	return nil
+}
+
+// restore replaces the value with the snapshot taken before it was updated. The caller must hold mu.
+func (s *JSONStore[T]) restore(snapshot []byte) {
+	var restored T
+	if err := json.Unmarshal(snapshot, &restored); err != nil {
+		// the snapshot was marshalled from a T so this is not expected
+		logrus.Errorf("unable to restore %s: %v", s.path, err)
+		return
+	}
+	s.value = restored
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJSONStore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "store.json")
+	store, err := OpenJSONStore[[]string](path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Update(func(value *[]string) error {
+		*value = append(*value, "first")
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a failed update leaves the value unchanged
+	if err := store.Update(func(value *[]string) error {
+		*value = append(*value, "second")
+		return errors.New("rejected")
+	}); err == nil {
+		t.Fatalf("expected the update to fail")
+	}
+	reopened, err := OpenJSONStore[[]string](path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range []*JSONStore[[]string]{store, reopened} {
+		s.View(func(value *[]string) {
+			if !reflect.DeepEqual(*value, []string{"first"}) {
+				t.Errorf("expected only the first update to be kept, got %v", *value)
+			}
+		})
+	}
+
+	// changes which can't be persisted are only kept when they are applied. the file can't be replaced by a directory.
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("unable to remove the file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "child"), 0o755); err != nil {
+		t.Fatalf("unable to replace the file with a directory: %v", err)
+	}
+	if err := store.Update(func(value *[]string) error {
+		*value = append(*value, "second")
+		return nil
+	}); err == nil {
+		t.Fatalf("expected the update to fail to be persisted")
+	}
+	if err := store.Apply(func(value *[]string) {
+		*value = append(*value, "third")
+	}); err == nil {
+		t.Fatalf("expected the change to fail to be persisted")
+	}
+	store.View(func(value *[]string) {
+		if !reflect.DeepEqual(*value, []string{"first", "third"}) {
+			t.Errorf("expected the applied change to be kept, got %v", *value)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"regexp"
 	"strings"
 	"unicode"
 )
@@ -77,3 +78,67 @@ func NormalizeTokensToSlice(args []string) []string {
 	}
 	return normalized
 }
+
+// NormalizeWords splits text into lower case words without the punctuation at their edges, keeping their order
+func NormalizeWords(text string) []string {
+	var words []string
+	for _, word := range strings.Fields(text) {
+		word = strings.ToLower(strings.TrimFunc(word, unicode.IsPunct))
+		if len(word) > 0 {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// indexWords returns the index of the first occurrence of phrase in words at or after start, or -1
+func indexWords(words, phrase []string, start int) int {
+	if len(phrase) == 0 {
+		return -1
+	}
+	for i := start; i+len(phrase) <= len(words); i++ {
+		found := true
+		for j := range phrase {
+			if words[i+j] != phrase[j] {
+				found = false
+				break
+			}
+		}
+		if found {
+			return i
+		}
+	}
+	return -1
+}
+
+// ContainsPhrase checks if the normalized words of text contain the normalized words of phrase, so the phrase only
+// matches whole words and "x509: certificate signed by unknown authority" matches the text
+// "X509 certificate signed by unknown authority." as well.
+func ContainsPhrase(text, phrase string) bool {
+	return indexWords(NormalizeWords(text), NormalizeWords(phrase), 0) >= 0
+}
+
+// ContainsSequence checks if the phrases appear in text in order, not necessarily next to each other. Like
+// ContainsPhrase, each phrase must match whole normalized words of the text.
+func ContainsSequence(text string, phrases ...string) bool {
+	if len(phrases) == 0 {
+		return false
+	}
+	words := NormalizeWords(text)
+	start := 0
+	for _, phrase := range phrases {
+		phraseWords := NormalizeWords(phrase)
+		index := indexWords(words, phraseWords, start)
+		if index < 0 {
+			return false
+		}
+		start = index + len(phraseWords)
+	}
+	return true
+}
+
+// MatchesRegex checks if regex matches either the original text or the normalized words of the text joined by
+// spaces
+func MatchesRegex(regex *regexp.Regexp, text string) bool {
+	return regex.MatchString(text) || regex.MatchString(strings.Join(NormalizeWords(text), " "))
+}
@@ -0,0 +1,79 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestContainsPhrase(t *testing.T) {
+	phrase := "x509: certificate signed by unknown authority"
+	for text, expected := range map[string]bool{
+		"error: x509: certificate signed by unknown authority":       true,
+		"X509: Certificate signed by unknown authority":              true,
+		"why do I get x509 certificate signed by unknown authority?": true,
+		"x509: certificate signed by an unknown authority":           false,
+		"unknown authority signed the certificate":                   false,
+	} {
+		if ContainsPhrase(text, phrase) != expected {
+			t.Errorf("expected %q to contain the phrase: %t", text, expected)
+		}
+	}
+	if ContainsPhrase("anything", " ") {
+		t.Errorf("expected an empty phrase to not match")
+	}
+	// phrases match whole words only
+	for text, phrase := range map[string]string{
+		"how do I install on vsphere": "all",
+		"the node is not ready":       "no",
+		"reinstalled the cluster":     "installed the",
+	} {
+		if ContainsPhrase(text, phrase) {
+			t.Errorf("expected %q to not contain the phrase %q", text, phrase)
+		}
+	}
+}
+
+func TestContainsSequence(t *testing.T) {
+	sequence := []string{"failed to", "bootstrap"}
+	for text, expected := range map[string]bool{
+		"the installer failed to wait for bootstrap to complete": true,
+		"Failed to... bootstrap!":                                true,
+		"bootstrap failed to complete":                           false,
+		"failed to bootstrap":                                    true,
+		"it failed, to bootstrap":                                true,
+		"failed bootstrap":                                       false,
+		"failed tokenizing the config, bootstrap again":          false,
+		"failed to start, bootstrapping again":                   false,
+	} {
+		if ContainsSequence(text, sequence...) != expected {
+			t.Errorf("expected %q to contain the sequence: %t", text, expected)
+		}
+	}
+	// the phrases of a sequence match whole words only
+	if ContainsSequence("failed tokenizing the config, will reboot", "failed to", "boot") {
+		t.Errorf("expected the sequence to not match parts of words")
+	}
+	if ContainsSequence("anything") {
+		t.Errorf("expected an empty sequence to not match")
+	}
+}
+
+func TestMatchesRegex(t *testing.T) {
+	regex := regexp.MustCompile(`4\.1[4-7]`)
+	for text, expected := range map[string]bool{
+		"upgrading from 4.15 to 4.16": true,
+		"upgrading to 4.13":           false,
+	} {
+		if MatchesRegex(regex, text) != expected {
+			t.Errorf("expected %q to match: %t", text, expected)
+		}
+	}
+	// the normalized text is lower case and has no punctuation at the edges of words
+	if !MatchesRegex(regexp.MustCompile(`^install failed$`), "INSTALL FAILED!") {
+		t.Errorf("expected the normalized text to be matched")
+	}
+	if words := strings.Join(NormalizeWords(" Hello,  World - again "), " "); words != "hello world again" {
+		t.Errorf("unexpected normalized words %q", words)
+	}
+}
@@ -16,6 +16,8 @@ import (
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/ollama"
 	"github.com/tmc/langchaingo/schema"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
 )
 
 const (
@@ -72,9 +74,11 @@ func GenerateResponse(ctx context.Context, prompt string, conversationContext ..
 
 	log.Printf("calling model with temp: %f\n", TEMPERATURE)
 
+	start := time.Now()
 	response, err := llm.GenerateContent(timedCtx, conversationContext, func(co *llms.CallOptions) {
 		co.Temperature = TEMPERATURE
 	})
+	metrics.ObserveLLM("ollama", start, err)
 	if err != nil {
 		return "", fmt.Errorf("unable to generate response from LLM: %v", err)
 	}
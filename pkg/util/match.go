@@ -0,0 +1,209 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Match modes which may be combined to loosen how a token is compared with the tokens of a message. Tokens are
+// compared exactly when no modes are set.
+const (
+	// MatchStem compares the stems of tokens so that "installing" and "installer" match "install"
+	MatchStem = "stem"
+	// MatchSynonyms compares the synonyms of a token from the synonym dictionary as well as the token
+	MatchSynonyms = "synonyms"
+	// MatchFuzzy allows tokens to differ by a small number of edits to tolerate typos such as "vsphre"
+	MatchFuzzy = "fuzzy"
+)
+
+const (
+	// DefaultMaxDistance is the greatest edit distance allowed by MatchFuzzy unless another distance is set
+	DefaultMaxDistance = 1
+	// maxAllowedDistance bounds the edit distance which may be set so fuzzy matching doesn't match unrelated words
+	maxAllowedDistance = 2
+	// minFuzzyLength is the shortest token MatchFuzzy applies to. Short tokens such as "aws" are a single edit
+	// from common words.
+	minFuzzyLength = 5
+)
+
+// Synonyms maps a word to the words which mean the same thing, including the word itself
+type Synonyms map[string][]string
+
+// NewSynonyms returns a dictionary in which each word of a group is a synonym of the other words in the group
+func NewSynonyms(groups [][]string) Synonyms {
+	synonyms := Synonyms{}
+	for _, group := range groups {
+		var words []string
+		for _, word := range group {
+			words = append(words, strings.ToLower(strings.TrimSpace(word)))
+		}
+		for _, word := range words {
+			synonyms[word] = append(synonyms[word], words...)
+		}
+	}
+	return synonyms
+}
+
+// MatchOptions control how a token is compared with the tokens of a message
+type MatchOptions struct {
+	Stem bool
+	// MaxDistance the greatest edit distance allowed between tokens. Fuzzy matching is disabled when 0.
+	MaxDistance int
+	// Synonyms the dictionary the synonyms of a token are looked up in. Synonyms are not matched when nil.
+	Synonyms Synonyms
+}
+
+// ParseMatchOptions returns the options for the match modes. maxDistance is used for MatchFuzzy when it is greater
+// than 0 and synonyms are used for MatchSynonyms.
+func ParseMatchOptions(modes []string, maxDistance int, synonyms Synonyms) (MatchOptions, error) {
+	var options MatchOptions
+	for _, mode := range modes {
+		switch strings.ToLower(strings.TrimSpace(mode)) {
+		case "exact", "":
+		case MatchStem:
+			options.Stem = true
+		case MatchSynonyms:
+			options.Synonyms = synonyms
+			if options.Synonyms == nil {
+				options.Synonyms = Synonyms{}
+			}
+		case MatchFuzzy:
+			options.MaxDistance = DefaultMaxDistance
+			if maxDistance > 0 {
+				options.MaxDistance = maxDistance
+			}
+		default:
+			return options, fmt.Errorf("unknown match mode %q. expected exact, %s, %s or %s", mode, MatchStem, MatchSynonyms, MatchFuzzy)
+		}
+	}
+	if options.MaxDistance > maxAllowedDistance {
+		return options, fmt.Errorf("max_distance may be at most %d", maxAllowedDistance)
+	}
+	return options, nil
+}
+
+// Modes returns the match modes of the options
+func (o MatchOptions) Modes() []string {
+	var modes []string
+	if o.Stem {
+		modes = append(modes, MatchStem)
+	}
+	if o.Synonyms != nil {
+		modes = append(modes, MatchSynonyms)
+	}
+	if o.MaxDistance > 0 {
+		modes = append(modes, fmt.Sprintf("%s(%d)", MatchFuzzy, o.MaxDistance))
+	}
+	return modes
+}
+
+// Present checks if token, or a token which matches it according to the options, is present in the tokens of a
+// message
+func (o MatchOptions) Present(tokens map[string]string, token string) bool {
+	token = strings.ToLower(token)
+	candidates := []string{token}
+	if o.Synonyms != nil {
+		candidates = append(candidates, o.Synonyms[token]...)
+	}
+	for _, candidate := range candidates {
+		if _, exists := tokens[candidate]; exists {
+			return true
+		}
+	}
+	if !o.Stem && o.MaxDistance == 0 {
+		return false
+	}
+	for _, candidate := range candidates {
+		for messageToken := range tokens {
+			if o.similar(candidate, messageToken) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (o MatchOptions) similar(token, messageToken string) bool {
+	if o.Stem && Stem(token) == Stem(messageToken) {
+		return true
+	}
+	if o.MaxDistance > 0 && len(token) >= minFuzzyLength {
+		return EditDistance(token, messageToken) <= o.MaxDistance
+	}
+	return false
+}
+
+// AllPresent checks if all of the args are present in the tokens of a message
+func (o MatchOptions) AllPresent(tokens map[string]string, args ...string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	for _, arg := range args {
+		if !o.Present(tokens, arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// AnyPresent checks if any of the args are present in the tokens of a message
+func (o MatchOptions) AnyPresent(tokens map[string]string, args ...string) bool {
+	for _, arg := range args {
+		if o.Present(tokens, arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// stemSuffixes are removed from the end of words by Stem, longest first
+var stemSuffixes = []struct {
+	suffix      string
+	replacement string
+}{
+	{"ational", "ate"},
+	{"ations", ""},
+	{"ation", ""},
+	{"ments", ""},
+	{"ment", ""},
+	{"ings", ""},
+	{"ing", ""},
+	{"ies", "y"},
+	{"ied", "y"},
+	{"ers", ""},
+	{"er", ""},
+	{"ed", ""},
+	{"es", ""},
+	{"ly", ""},
+	{"s", ""},
+}
+
+// minStemLength is the shortest stem a suffix is removed to leave
+const minStemLength = 3
+
+// Stem reduces an English word to a stem by removing common suffixes so that the forms of a word share the same
+// stem. The stem is not necessarily a word, for example both "configure" and "configuring" become "configur".
+func Stem(word string) string {
+	word = strings.ToLower(word)
+	removed := false
+	for _, rule := range stemSuffixes {
+		if !strings.HasSuffix(word, rule.suffix) || len(word)-len(rule.suffix)+len(rule.replacement) < minStemLength {
+			continue
+		}
+		// words such as "process" and "status" are not plurals
+		if rule.suffix == "s" && strings.ContainsRune("siu", rune(word[len(word)-2])) {
+			break
+		}
+		word = strings.TrimSuffix(word, rule.suffix) + rule.replacement
+		removed = true
+		break
+	}
+	// a consonant doubled before a suffix, such as in "running", is reduced to one
+	if length := len(word); removed && length > minStemLength && word[length-1] == word[length-2] && !strings.ContainsRune("aeioulsz", rune(word[length-1])) {
+		word = word[:length-1]
+	}
+	if length := len(word); length > minStemLength && word[length-1] == 'e' {
+		word = word[:length-1]
+	}
+	return word
+}
@@ -0,0 +1,79 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	groups := [][]string{
+		{"install", "installs", "installing", "installer", "installers", "installed", "installation"},
+		{"configure", "configured", "configuring", "configuration"},
+		{"upgrade", "upgrades", "upgraded", "upgrading"},
+		{"proxy", "proxies"},
+		{"run", "running", "runs"},
+		{"process", "processes"},
+		{"type", "types"},
+	}
+	for _, group := range groups {
+		stem := Stem(group[0])
+		for _, word := range group[1:] {
+			if Stem(word) != stem {
+				t.Errorf("expected %q to have the stem of %q (%q), got %q", word, group[0], stem, Stem(word))
+			}
+		}
+	}
+	for _, word := range []string{"aws", "status", "use", "vsphere"} {
+		if stem := Stem(word); len(stem) < 3 || !strings.HasPrefix(word, stem) {
+			t.Errorf("expected %q to be left mostly intact, got %q", word, stem)
+		}
+	}
+}
+
+func TestMatchOptions(t *testing.T) {
+	tokens := NormalizeTokens(strings.Split("Installing on vsphre was slow", " "))
+	synonyms := NewSynonyms([][]string{{"vcenter", "vsphere"}, {"slow", "sluggish"}})
+
+	tests := []struct {
+		modes    []string
+		token    string
+		expected bool
+	}{
+		{nil, "installing", true},
+		{nil, "INSTALLING", true},
+		{nil, "install", false},
+		{[]string{"stem"}, "install", true},
+		{[]string{"stem"}, "installer", true},
+		{nil, "vsphere", false},
+		{[]string{"fuzzy"}, "vsphere", true},
+		{[]string{"fuzzy"}, "vcenter", false},
+		// short tokens are not matched fuzzily
+		{[]string{"fuzzy"}, "aws", false},
+		{[]string{"synonyms"}, "sluggish", true},
+		{[]string{"synonyms"}, "vcenter", false},
+		{[]string{"synonyms", "fuzzy"}, "vcenter", true},
+	}
+	for _, test := range tests {
+		options, err := ParseMatchOptions(test.modes, 0, synonyms)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if present := options.Present(tokens, test.token); present != test.expected {
+			t.Errorf("expected %q with modes %v to be present: %t, got %t", test.token, test.modes, test.expected, present)
+		}
+	}
+
+	if _, err := ParseMatchOptions([]string{"phonetic"}, 0, nil); err == nil || !strings.Contains(err.Error(), `unknown match mode "phonetic"`) {
+		t.Errorf("expected an unknown mode to be rejected, got %v", err)
+	}
+	if _, err := ParseMatchOptions([]string{"fuzzy"}, 3, nil); err == nil {
+		t.Errorf("expected a large edit distance to be rejected")
+	}
+	options, err := ParseMatchOptions([]string{"stem", "synonyms", "fuzzy"}, 2, nil)
+	if err != nil || strings.Join(options.Modes(), ",") != "stem,synonyms,fuzzy(2)" {
+		t.Errorf("expected all of the modes to be set, got %v: %v", options.Modes(), err)
+	}
+	if !(MatchOptions{}).AllPresent(tokens, "on", "was") || (MatchOptions{}).AllPresent(tokens) || (MatchOptions{}).AnyPresent(tokens, "off", "is") {
+		t.Errorf("expected exact matching by default")
+	}
+}
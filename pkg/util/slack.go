@@ -1,6 +1,7 @@
 package util
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -41,17 +42,7 @@ func init() {
 	// obfuscators = append(obfuscators, newObfuscator)
 }
 
-func GetClient() (*socketmode.Client, error) {
-	appToken := os.Getenv("SLACK_APP_TOKEN")
-	if appToken == "" {
-		return nil, errors.New("SLACK_APP_TOKEN must be set")
-
-	}
-
-	if !strings.HasPrefix(appToken, "xapp-") {
-		return nil, errors.New("SLACK_APP_TOKEN must have the prefix \"xapp-\"")
-	}
-
+func newAPI(options ...slack.Option) (*slack.Client, error) {
 	botToken := os.Getenv("SLACK_BOT_TOKEN")
 	if botToken == "" {
 		return nil, errors.New("SLACK_BOT_TOKEN must be set")
@@ -66,12 +57,29 @@ func GetClient() (*socketmode.Client, error) {
 		return nil, fmt.Errorf("unable to bind env vars: %v", err)
 	}
 
-	api := slack.New(
-		botToken,
+	options = append([]slack.Option{
 		slack.OptionDebug(true),
 		slack.OptionLog(log.New(os.Stdout, "api: ", log.Lshortfile|log.LstdFlags)),
-		slack.OptionAppLevelToken(appToken),
-	)
+	}, options...)
+	return slack.New(botToken, options...), nil
+}
+
+// GetClient returns a client which receives events over Socket Mode.
+func GetClient() (*socketmode.Client, error) {
+	appToken := os.Getenv("SLACK_APP_TOKEN")
+	if appToken == "" {
+		return nil, errors.New("SLACK_APP_TOKEN must be set")
+
+	}
+
+	if !strings.HasPrefix(appToken, "xapp-") {
+		return nil, errors.New("SLACK_APP_TOKEN must have the prefix \"xapp-\"")
+	}
+
+	api, err := newAPI(slack.OptionAppLevelToken(appToken))
+	if err != nil {
+		return nil, err
+	}
 
 	client := socketmode.New(
 		api,
@@ -81,12 +89,18 @@ func GetClient() (*socketmode.Client, error) {
 	return client, nil
 }
 
+// GetWebClient returns a client for the Slack Web API. Unlike GetClient, an app level token is not required, so the
+// client may be used when events are received over HTTP.
+func GetWebClient() (*slack.Client, error) {
+	return newAPI()
+}
+
 func GetSlackClient() (SlackClientInterface, error) {
 	if os.Getenv("UNIT") != "" {
 		testClient := &StubInterface{}
 		return testClient, nil
 	} else {
-		return GetClient()
+		return GetWebClient()
 	}
 
 }
@@ -138,6 +152,11 @@ func IsSPLATBotID(botID string) bool {
 	return botID == userID
 }
 
+// BotMention returns the text which mentions the bot in a message
+func BotMention() string {
+	return fmt.Sprintf("<@%s>", os.Getenv("SPLAT_BOT_USER_ID"))
+}
+
 func ContainsBotMention(messageText string) bool {
 	userID, ok := os.LookupEnv("SPLAT_BOT_USER_ID")
 	if !ok {
@@ -174,6 +193,14 @@ func StringsToBlockUnfurl(messages []string, useMarkdown, unfurlLinks bool) []sl
 }
 
 func StringsToBlockWithURLs(messages []string, urls []string) []slack.MsgOption {
+	return []slack.MsgOption{
+		slack.MsgOptionBlocks(StringsToBlocksWithURLs(messages, urls)...),
+	}
+}
+
+// StringsToBlocksWithURLs returns a section for each message followed by the urls. Use it to add blocks, such as
+// buttons, to the message.
+func StringsToBlocksWithURLs(messages []string, urls []string) []slack.Block {
 	messageBlocks := []slack.Block{}
 
 	for _, message := range messages {
@@ -199,9 +226,7 @@ func StringsToBlockWithURLs(messages []string, urls []string) []slack.MsgOption
 			),
 		)
 	}
-	return []slack.MsgOption{
-		slack.MsgOptionBlocks(messageBlocks...),
-	}
+	return messageBlocks
 }
 
 func StringToBlockUnfurl(message string, useMarkdown, unfurlLinks bool) []slack.MsgOption {
@@ -214,6 +239,31 @@ func StringToBlockUnfurl(message string, useMarkdown, unfurlLinks bool) []slack.
 	return options
 }
 
+// PrependContext adds a line of context, such as a link to the message being answered, to the top of a response
+func PrependContext(context string, options []slack.MsgOption) ([]slack.MsgOption, error) {
+	_, values, err := slack.UnsafeApplyMsgOptions("", "", "", options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to apply message options: %v", err)
+	}
+	text := context
+	if len(values.Get("text")) > 0 {
+		text = fmt.Sprintf("%s\n%s", context, values.Get("text"))
+	}
+	prepended := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if encoded := values.Get("blocks"); len(encoded) > 0 {
+		blocks := slack.Blocks{}
+		if err := json.Unmarshal([]byte(encoded), &blocks); err != nil {
+			return nil, fmt.Errorf("unable to parse blocks: %v", err)
+		}
+		contextBlock := slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, context, false, false))
+		prepended = append(prepended, slack.MsgOptionBlocks(append([]slack.Block{contextBlock}, blocks.BlockSet...)...))
+	}
+	if values.Get("unfurl_links") == "true" {
+		prepended = append(prepended, slack.MsgOptionEnableLinkUnfurl())
+	}
+	return prepended, nil
+}
+
 func StringToBlock(message string, useMarkdown bool) []slack.MsgOption {
 	return StringToBlockUnfurl(message, useMarkdown, true)
 }
@@ -221,3 +271,11 @@ func StringToBlock(message string, useMarkdown bool) []slack.MsgOption {
 func WrapErrorToBlock(err error, message string) []slack.MsgOption {
 	return StringToBlock(fmt.Sprintf("%s: %v", message, err), false)
 }
+
+// CloseActionID is the action_id of buttons which delete the message they are part of.
+const CloseActionID = "close"
+
+// NewCloseButton returns a button which deletes the message it is part of when clicked.
+func NewCloseButton() *slack.ButtonBlockElement {
+	return slack.NewButtonBlockElement(CloseActionID, "", slack.NewTextBlockObject(slack.PlainTextType, "Close", true, false))
+}
@@ -0,0 +1,38 @@
+package util
+
+// EditDistance returns the Levenshtein distance between a and b
+func EditDistance(a, b string) int {
+	source := []rune(a)
+	target := []rune(b)
+
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
+
+// Truncate shortens s to at most length characters, marking the truncation with an ellipsis
+func Truncate(s string, length int) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+	if length <= 1 {
+		return string(runes[:length])
+	}
+	return string(runes[:length-1]) + "…"
+}